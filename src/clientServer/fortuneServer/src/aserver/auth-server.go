@@ -2,7 +2,10 @@
 Authentication Server
 
 Usage:
-$ go run auth-server.go [aserver UDP ip:port] [fserver RPC ip:port] [secret]
+$ go run auth-server.go [aserver UDP ip:port] [fserver RPC ip:port] [secret] [min protocol version]
+
+The min protocol version defaults to clientServerUtils.DefaultMinVersion.
+Pass 1 to keep accepting MD5 from clients not yet upgraded.
 
 Example:
 export GOPATH=<PROJECT_DIRECTORY>
@@ -15,6 +18,7 @@ package main
 import (
 	"clientServerUtils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net"
@@ -32,10 +36,10 @@ func generateNewNonce(udpAddr *net.UDPAddr, nonceMap clientServerUtils.Concurren
 	return nonce
 }
 
-func sendNewNonce(conn net.UDPConn, sendto *net.UDPAddr, nonceMap clientServerUtils.ConcurrentMap) {
+func sendNewNonce(conn net.UDPConn, sendto *net.UDPAddr, nonceMap clientServerUtils.ConcurrentMap, minVersion int) {
 	nonce := generateNewNonce(sendto, nonceMap)
 
-	nonceMsg := clientServerUtils.NonceMessage{nonce}
+	nonceMsg := clientServerUtils.NonceMessage{Nonce: nonce, Versions: clientServerUtils.SupportedVersions(minVersion)}
 	req, err := json.Marshal(nonceMsg)
 	if err != nil {
 		fmt.Println("Error marshalling NonceMessage: ", err)
@@ -94,21 +98,16 @@ func replyWithFortuneInfoMessage(conn net.UDPConn, clientUDPAddr *net.UDPAddr, f
 	sendFortuneInfoMessage(conn, clientUDPAddr, fortuneInfoMsg)
 }
 
-func parseSecretArg(secretStr string) int64 {
-	secret, err := strconv.ParseInt(secretStr, 10, 64)
+func parseMinVersionArg(minVersionStr string) int {
+	minVersion, err := strconv.Atoi(minVersionStr)
 	if err != nil {
-		fmt.Println("Error parsing int from secret arg: ", secretStr, ", Error: ", err)
+		fmt.Println("Error parsing int from min protocol version arg: ", minVersionStr, ", Error: ", err)
 		os.Exit(-1)
 	}
-	return secret
-}
-
-func isValidHashMessage(received clientServerUtils.HashMessage, storedNonce int64, secret int64) bool {
-	expected := clientServerUtils.ComputeHashMessage(storedNonce, secret)
-	return expected == received
+	return minVersion
 }
 
-func handleUDPConn(udpListener net.UDPConn, clientUDPAddr *net.UDPAddr, fserverRCPIpPort string, nonceMap clientServerUtils.ConcurrentMap, secret int64, msgFromClient []byte) {
+func handleUDPConn(udpListener net.UDPConn, clientUDPAddr *net.UDPAddr, fserverRCPIpPort string, nonceMap clientServerUtils.ConcurrentMap, key []byte, minVersion int, msgFromClient []byte) {
 	nonceMap.RLock()
 	clientNonce := nonceMap.Map[clientUDPAddr.String()]
 	nonceMap.RUnlock()
@@ -116,27 +115,39 @@ func handleUDPConn(udpListener net.UDPConn, clientUDPAddr *net.UDPAddr, fserverR
 	var receivedHashMsg clientServerUtils.HashMessage
 	err := json.Unmarshal(msgFromClient, &receivedHashMsg)
 	if err != nil {
-		sendNewNonce(udpListener, clientUDPAddr, nonceMap)
-	} else if clientNonce == 0 {
-		errMsg := clientServerUtils.ErrMessage{"unknown remote client address"}
+		sendNewNonce(udpListener, clientUDPAddr, nonceMap, minVersion)
+		return
+	}
+	if clientNonce == 0 {
+		errMsg := clientServerUtils.ErrMessage{Error: "unknown remote client address"}
 		sendErrMessage(udpListener, clientUDPAddr, errMsg)
-	} else if isValidHashMessage(receivedHashMsg, clientNonce, secret) {
+		return
+	}
+	err = clientServerUtils.VerifyHashMessage(receivedHashMsg, clientNonce, key, minVersion)
+	if err == nil {
 		replyWithFortuneInfoMessage(udpListener, clientUDPAddr, fserverRCPIpPort)
+	} else if errors.Is(err, clientServerUtils.ErrUnsupportedVersion) {
+		//Tell clients which versions to upgrade to
+		sendErrMessage(udpListener, clientUDPAddr, clientServerUtils.ErrMessage{Error: err.Error()})
 	} else {
-		errMsg := clientServerUtils.ErrMessage{"unexpected hash value"}
+		errMsg := clientServerUtils.ErrMessage{Error: "unexpected hash value"}
 		sendErrMessage(udpListener, clientUDPAddr, errMsg)
 	}
 }
 
 func main() {
 	args := os.Args
-	if len(args) != 4 {
-		fmt.Println("Usage: [aserver UDP ip:port] [fserver RPC ip:port] [secret]")
+	if len(args) != 4 && len(args) != 5 {
+		fmt.Println("Usage: [aserver UDP ip:port] [fserver RPC ip:port] [secret] [min protocol version]")
 		os.Exit(-1)
 	}
 	aserverUDPIpPort := args[1]
 	fserverRCPIpPort := args[2]
-	secret := parseSecretArg(args[3])
+	key := []byte(args[3])
+	minVersion := clientServerUtils.DefaultMinVersion
+	if len(args) == 5 {
+		minVersion = parseMinVersionArg(args[4])
+	}
 
 	udpListener := clientServerUtils.InitUDPConn(aserverUDPIpPort)
 
@@ -151,7 +162,7 @@ func main() {
 		if err != nil {
 			fmt.Println("Error on ReadFromUDP: ", err)
 		} else {
-			go handleUDPConn(udpListener, clientUDPAddr, fserverRCPIpPort, nonceMap, secret, buf[0:msgLen])
+			go handleUDPConn(udpListener, clientUDPAddr, fserverRCPIpPort, nonceMap, key, minVersion, buf[0:msgLen])
 		}
 	}
 	udpListener.Close()
@@ -1,9 +1,12 @@
 package clientServerUtils
 
 import (
+	"crypto/hmac"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -11,6 +14,28 @@ import (
 	"sync"
 )
 
+//Authentication protocol versions.  Servers list the versions they accept in
+//each NonceMessage, and clients name the version they chose in HashMessage.
+//Messages without versions come from peers predating versioning, which only
+//know ProtocolMD5.
+const (
+	//MD5 of the varint of nonce + secret, kept only for clients not yet
+	//upgraded: the addition can overflow and loses information, and MD5 is broken
+	ProtocolMD5 = 1
+	//HMAC-SHA256 of the nonce as 8 big-endian bytes, keyed by the shared secret
+	ProtocolHMACSHA256 = 2
+
+	LatestProtocol = ProtocolHMACSHA256
+	//Oldest version accepted unless ProtocolMD5 is explicitly allowed for
+	//clients not yet upgraded
+	DefaultMinVersion = ProtocolHMACSHA256
+)
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported protocol version")
+	ErrUnexpectedHash     = errors.New("unexpected hash value")
+)
+
 type ConcurrentMap struct {
 	sync.RWMutex
 	Map map[string]int64
@@ -22,10 +47,13 @@ type ErrMessage struct {
 
 type NonceMessage struct {
 	Nonce int64
+	//Protocol versions the server accepts, in ascending order
+	Versions []int `json:",omitempty"`
 }
 
 type HashMessage struct {
-	Hash string
+	Hash    string
+	Version int `json:",omitempty"`
 }
 
 // Message with details for contacting the fortune-server.
@@ -53,6 +81,10 @@ func ParseIntFromStr(str string) int64 {
 	return integer
 }
 
+//Computes the ProtocolMD5 HashMessage, without a Version so that servers
+//predating versioning accept it.
+//
+//Deprecated: use ComputeHMACMessage.
 func ComputeHashMessage(nonce int64, secret int64) HashMessage {
 	dataInt64 := nonce + secret
 
@@ -63,7 +95,78 @@ func ComputeHashMessage(nonce int64, secret int64) HashMessage {
 	hash := md5.New()
 	hash.Write(trimmedBuf)
 	hashStrHex := hex.EncodeToString(hash.Sum(nil))
-	return HashMessage{hashStrHex}
+	return HashMessage{Hash: hashStrHex}
+}
+
+//Computes the ProtocolHMACSHA256 HashMessage for nonce, keyed by key
+func ComputeHMACMessage(nonce int64, key []byte) HashMessage {
+	mac := hmac.New(sha256.New, key)
+	binary.Write(mac, binary.BigEndian, nonce)
+	return HashMessage{Hash: hex.EncodeToString(mac.Sum(nil)), Version: ProtocolHMACSHA256}
+}
+
+//Computes the HashMessage for nonce under version.  ProtocolMD5 requires key
+//to be a decimal integer, as secrets were before versioning.
+func ComputeVersionedHashMessage(version int, nonce int64, key []byte) (HashMessage, error) {
+	switch version {
+	case ProtocolMD5:
+		secret, err := strconv.ParseInt(string(key), 10, 64)
+		if err != nil {
+			return HashMessage{}, fmt.Errorf("%w %d needs an integer secret", ErrUnsupportedVersion, version)
+		}
+		return ComputeHashMessage(nonce, secret), nil
+	case ProtocolHMACSHA256:
+		return ComputeHMACMessage(nonce, key), nil
+	}
+	return HashMessage{}, fmt.Errorf("%w %d", ErrUnsupportedVersion, version)
+}
+
+//Returns the versions accepted by a server whose oldest accepted version is minVersion
+func SupportedVersions(minVersion int) []int {
+	versions := []int{}
+	for version := max(minVersion, ProtocolMD5); version <= LatestProtocol; version++ {
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+//Returns the latest version offered by a server which is at least minVersion
+func ChooseVersion(offered []int, minVersion int) (int, error) {
+	if len(offered) == 0 {
+		offered = []int{ProtocolMD5}
+	}
+	chosen := 0
+	for _, version := range offered {
+		if version >= minVersion && version <= LatestProtocol && version > chosen {
+			chosen = version
+		}
+	}
+	if chosen == 0 {
+		return 0, fmt.Errorf("%w: server offers %v, client accepts %d to %d", ErrUnsupportedVersion, offered, minVersion, LatestProtocol)
+	}
+	return chosen, nil
+}
+
+//Checks received against the hash expected for nonce and key, comparing in
+//constant time.  Returns an error wrapping ErrUnsupportedVersion if received
+//uses a version older than minVersion or unknown to this server, or
+//ErrUnexpectedHash if the hash is wrong.
+func VerifyHashMessage(received HashMessage, nonce int64, key []byte, minVersion int) error {
+	version := received.Version
+	if version == 0 {
+		version = ProtocolMD5
+	}
+	if version < minVersion || version > LatestProtocol {
+		return fmt.Errorf("%w %d, server accepts versions %v", ErrUnsupportedVersion, version, SupportedVersions(minVersion))
+	}
+	expected, err := ComputeVersionedHashMessage(version, nonce, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected.Hash), []byte(received.Hash)) {
+		return ErrUnexpectedHash
+	}
+	return nil
 }
 
 func ResolveUDPAddr(ipPort string) net.UDPAddr {
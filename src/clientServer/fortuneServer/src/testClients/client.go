@@ -2,7 +2,10 @@
 Sample Client
 
 Usage:
-$ go run client.go [local UDP ip:port] [aserver UDP ip:port] [secret]
+$ go run client.go [local UDP ip:port] [aserver UDP ip:port] [secret] [min protocol version]
+
+The min protocol version defaults to clientServerUtils.DefaultMinVersion.
+Pass 1 to fall back to MD5 for servers not yet upgraded.
 
 Example:
 export GOPATH=<PROJECT_DIRECTORY>
@@ -14,9 +17,6 @@ package main
 
 import (
 	"clientServerUtils"
-	"crypto/md5"
-	"encoding/binary"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -70,19 +70,6 @@ func retrieveNonce(clientConn net.UDPConn, aserverUDPAddr *net.UDPAddr) clientSe
 	return nonce
 }
 
-func computeHashMessage(nonce int64, secret int64) clientServerUtils.HashMessage {
-	dataInt64 := nonce + secret
-
-	dataBuf := make([]byte, 1024)
-	length := binary.PutVarint(dataBuf, dataInt64)
-	trimmedBuf := dataBuf[:length]
-
-	hash := md5.New()
-	hash.Write(trimmedBuf)
-	hashStrHex := hex.EncodeToString(hash.Sum(nil))
-	return clientServerUtils.HashMessage{hashStrHex}
-}
-
 //Retrieves FortuneInfoMessage data required to contact fserver
 func retrieveFortuneInfoMsg(clientConn net.UDPConn, aserverUDPAddr *net.UDPAddr, hashMsg clientServerUtils.HashMessage) clientServerUtils.FortuneInfoMessage {
 	//Send HashMessage to aserver to get FortuneInfoMessage
@@ -143,27 +130,41 @@ func retrieveFortune(clientConn net.UDPConn, fortuneInfoMsg clientServerUtils.Fo
 
 func main() {
 	args := os.Args //os.Args[0] is program name
-	if len(args) != 4 {
-		fmt.Println("Usage: client [local UDP ip:port] [aserver UDP ip:port] [secret]")
+	if len(args) != 4 && len(args) != 5 {
+		fmt.Println("Usage: client [local UDP ip:port] [aserver UDP ip:port] [secret] [min protocol version]")
 		os.Exit(-1)
 	}
 	clientIpPort := args[1]
 	aserverIpPort := args[2]
-	secret, err := strconv.ParseInt(args[3], 10, 64)
-	if err != nil {
-		fmt.Println("Error parsing int from secret arg: ", args[3], ", Error:", err)
-		os.Exit(-1)
+	key := []byte(args[3])
+	minVersion := clientServerUtils.DefaultMinVersion
+	if len(args) == 5 {
+		var err error
+		minVersion, err = strconv.Atoi(args[4])
+		if err != nil {
+			fmt.Println("Error parsing int from min protocol version arg: ", args[4], ", Error:", err)
+			os.Exit(-1)
+		}
 	}
 
 	clientConn := initUDPConn(clientIpPort)
 	aserverUDPAddr := resolveUDPAddr(aserverIpPort)
 
-	//Retrieve nonce from aserver and compute MD5(nonce + secret)
+	//Retrieve nonce from aserver and hash it under the chosen protocol version
 	nonce := retrieveNonce(clientConn, &aserverUDPAddr)
 
 	fmt.Println("Received NonceMessage:", nonce) //XXX
 
-	hashMsg := computeHashMessage(nonce.Nonce, secret)
+	version, err := clientServerUtils.ChooseVersion(nonce.Versions, minVersion)
+	if err != nil {
+		fmt.Println("Error negotiating protocol version: ", err)
+		os.Exit(-1)
+	}
+	hashMsg, err := clientServerUtils.ComputeVersionedHashMessage(version, nonce.Nonce, key)
+	if err != nil {
+		fmt.Println("Error computing hash: ", err)
+		os.Exit(-1)
+	}
 
 	//Retrieve FortuneInfoMessage from aserver
 	fortuneInfoMsg := retrieveFortuneInfoMsg(clientConn, &aserverUDPAddr, hashMsg)
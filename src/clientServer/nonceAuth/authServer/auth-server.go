@@ -3,118 +3,106 @@ Authentication Server
 
 Usage:
 1. Import the aserver package
-2. Call authServer.RunAuthServer(udpIpPort string, secret int64)
+2. Create a server with authServer.New(authServer.Config{Addr: udpIpPort, Key: key})
+3. Call server.Start(ctx) to serve in the background, and server.Shutdown(ctx) to stop
+
+Clients prove knowledge of the shared key by hashing a nonce under one of the
+protocol versions from common.SupportedVersions(Config.MinVersion).  The
+default, common.DefaultMinVersion, rejects clients still using
+common.ProtocolMD5.
+
+Each nonce is valid for a single answer within Config.NonceTTL, and at most
+Config.MaxNonces are outstanding at once.  Requests carrying a RequestID which
+the server has already answered, within Config.NonceTTL, are answered with the
+original reply, so clients can retransmit requests or replies lost in transit.
 */
 
 package authServer
 
 import (
 	"clientServer/nonceAuth/common"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math/rand"
 	"net"
 	"os"
-	"sync"
 )
 
-type concurrentMap struct {
-	sync.RWMutex
-	m map[string]int64
-}
-
-//Generate new nonce for udpAddr and add to nonceMap
-func generateNewNonce(udpAddr *net.UDPAddr, nonceMap concurrentMap) int64 {
-	nonce := rand.Int63()
-	nonceMap.Lock()
-	nonceMap.m[udpAddr.String()] = nonce
-	nonceMap.Unlock()
-	return nonce
-}
-
-func sendNewNonce(conn net.UDPConn, clientUDPAddr *net.UDPAddr, nonceMap concurrentMap) {
-	nonce := generateNewNonce(clientUDPAddr, nonceMap)
-
-	nonceMsg := common.NonceMessage{nonce}
-	req, err := json.Marshal(nonceMsg)
-	if err != nil {
-		fmt.Println("Error marshalling NonceMessage: ", err)
-		os.Exit(-1)
+func (server *Server) handleMessage(clientUDPAddr *net.UDPAddr, msgFromClient []byte) {
+	var request common.HashMessage
+	//Handle mangled message as request for nonce
+	json.Unmarshal(msgFromClient, &request)
+	if request.RequestID == 0 {
+		server.reply(clientUDPAddr, server.respond(clientUDPAddr, request))
+		return
 	}
-	_, err = conn.WriteToUDP(req, clientUDPAddr)
-	if err != nil {
-		fmt.Printf("Error writing NonceMessage to client %v: %v\n", clientUDPAddr, err)
+	//Answer retransmitted requests with the original reply
+	key := replyKey(clientUDPAddr.String(), request.RequestID)
+	if reply, seen := server.replies.begin(key); seen {
+		if reply != nil {
+			server.send(clientUDPAddr, reply)
+		}
+		return
 	}
+	reply := server.reply(clientUDPAddr, server.respond(clientUDPAddr, request))
+	server.replies.finish(key, reply)
 }
 
-func sendErrMessage(conn net.UDPConn, clientUDPAddr *net.UDPAddr, errMsg common.ErrMessage) {
-	req, err := json.Marshal(errMsg)
-	if err != nil {
-		fmt.Println("Error marshalling ErrMessage: ", err)
-		os.Exit(-1)
+//Returns the message answering request, which is a nonce request if it has no Hash
+func (server *Server) respond(clientUDPAddr *net.UDPAddr, request common.HashMessage) interface{} {
+	if request.Hash == "" {
+		nonce := server.nonces.issue(clientUDPAddr.String())
+		return common.NonceMessage{Nonce: nonce, Versions: common.SupportedVersions(server.config.MinVersion), RequestID: request.RequestID}
 	}
-	_, err = conn.WriteToUDP(req, clientUDPAddr)
-	if err != nil {
-		fmt.Println("Error writing ErrMessage to client: ", err)
+	//Each nonce is answered once, right or wrong, so it cannot be replayed or guessed at
+	clientNonce, err := server.nonces.take(clientUDPAddr.String())
+	if errors.Is(err, errExpiredNonce) {
+		return common.ErrMessage{Error: common.ErrMsgExpiredNonce, RequestID: request.RequestID}
+	} else if err != nil {
+		return common.ErrMessage{Error: common.ErrMsgUnknownClient, RequestID: request.RequestID}
 	}
+	err = common.VerifyHashMessage(request, clientNonce, server.config.Key, server.config.MinVersion)
+	if err == nil {
+		return common.GoalMessage{Goal: "You reached the goal!", RequestID: request.RequestID}
+	} else if errors.Is(err, common.ErrUnsupportedVersion) {
+		//Tell clients which versions to upgrade to
+		return common.ErrMessage{Error: err.Error(), RequestID: request.RequestID}
+	}
+	return common.ErrMessage{Error: common.ErrMsgUnexpectedHash, RequestID: request.RequestID}
 }
 
-func sendGoalMessage(conn net.UDPConn, clientUDPAddr *net.UDPAddr) {
-	goalMsg := common.GoalMessage{"You reached the goal!"}
-	req, err := json.Marshal(goalMsg)
-	if err != nil {
-		fmt.Println("Error marshalling GoalMessage: ", err)
-		os.Exit(-1)
-	}
-	_, err = conn.WriteToUDP(req, clientUDPAddr)
+//Sends msg to the client as JSON, logging any error.  Returns the JSON sent.
+func (server *Server) reply(clientUDPAddr *net.UDPAddr, msg interface{}) []byte {
+	req, err := json.Marshal(msg)
 	if err != nil {
-		fmt.Println("Error writing GoalMessage to client: ", err)
+		server.config.ErrorLog.Printf("authServer: marshalling %T: %v", msg, err)
+		return nil
 	}
+	server.send(clientUDPAddr, req)
+	return req
 }
 
-func isValidHashMessage(received common.HashMessage, storedNonce int64, secret int64) bool {
-	expected := common.ComputeHashMessage(storedNonce, secret)
-	return expected == received
-}
-
-func handleUDPConn(conn net.UDPConn, clientUDPAddr *net.UDPAddr, nonceMap concurrentMap, secret int64, msgFromClient []byte) {
-	nonceMap.RLock()
-	clientNonce := nonceMap.m[clientUDPAddr.String()]
-	nonceMap.RUnlock()
-
-	var receivedHashMsg common.HashMessage
-	err := json.Unmarshal(msgFromClient, &receivedHashMsg)
+func (server *Server) send(clientUDPAddr *net.UDPAddr, req []byte) {
+	_, err := server.conn.WriteToUDP(req, clientUDPAddr)
 	if err != nil {
-		//Handle mangled message as request for nonce
-		sendNewNonce(conn, clientUDPAddr, nonceMap)
-	} else if clientNonce == 0 {
-		errMsg := common.ErrMessage{"Unknown client address"}
-		sendErrMessage(conn, clientUDPAddr, errMsg)
-	} else if isValidHashMessage(receivedHashMsg, clientNonce, secret) {
-		sendGoalMessage(conn, clientUDPAddr)
-	} else {
-		errMsg := common.ErrMessage{"Unexpected hash value"}
-		sendErrMessage(conn, clientUDPAddr, errMsg)
+		server.config.ErrorLog.Printf("authServer: writing to client %v: %v", clientUDPAddr, err)
 	}
 }
 
-func RunAuthServer(udpIpPort string, secret int64) {
-	udpConn := common.InitUDPConn(udpIpPort)
-
-	//Initialize hash table of (udpIpPort, nonce) key-value pairs
-	var nonceMap concurrentMap
-	nonceMap.m = make(map[string]int64)
-
-	//Start listen/receive connection loop
-	for {
-		var buf [1024]byte
-		msgLen, clientUDPAddr, err := udpConn.ReadFromUDP(buf[:])
-		if err != nil {
-			fmt.Println("Error on ReadFromUDP: ", err)
-		} else {
-			//Start go routine to handle client, continue listening for new clients
-			go handleUDPConn(udpConn, clientUDPAddr, nonceMap, secret, buf[0:msgLen])
-		}
+//Runs the authentication server on udpIpPort, accepting clients which use
+//protocol version minVersion or later with the shared key.  Never returns, and
+//exits the process if the server cannot start.
+//
+//Deprecated: use New and Start, which return errors and can be shut down.
+func RunAuthServer(udpIpPort string, key []byte, minVersion int) {
+	server, err := New(Config{Addr: udpIpPort, Key: key, MinVersion: minVersion})
+	if err == nil {
+		_, err = server.Start(context.Background())
+	}
+	if err != nil {
+		fmt.Println("Error starting aserver: ", err)
+		os.Exit(-1)
 	}
-	udpConn.Close()
+	select {}
 }
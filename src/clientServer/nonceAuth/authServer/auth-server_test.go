@@ -1,12 +1,91 @@
 package authServer
 
 import (
+	"clientServer/nonceAuth/common"
+	"context"
+	"encoding/json"
+	"net"
 	"os"
+	"slices"
+	"strings"
 	"testUtil" //Custom test utils for running tests with expected exit errors
 	"testing"
 	"time"
 )
 
+var testKey = []byte("123456")
+
+//Starts a server on a free port with config, filling in Addr and Key, and
+//returns a client connection to it.  The server is shut down when the test ends.
+func startTestServer(t *testing.T, config Config) *net.UDPConn {
+	config.Addr = "localhost:0"
+	config.Key = testKey
+	server, err := New(config)
+	if err != nil {
+		t.Fatalf("New(%+v) == %v", config, err)
+	}
+	addr, err := server.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() == %v", err)
+	}
+	t.Cleanup(func() {
+		server.Shutdown(context.Background())
+	})
+
+	conn, err := net.Dial("udp", addr.String())
+	if err != nil {
+		t.Fatalf("Dial(%s) == %v", addr, err)
+	}
+	return conn.(*net.UDPConn)
+}
+
+//Sends msg to the aserver and unmarshals its reply into reply
+func exchange(t *testing.T, conn *net.UDPConn, msg []byte, reply interface{}) {
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatalf("Write == %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	var buf [1024]byte
+	msgLen, err := conn.Read(buf[:])
+	if err != nil {
+		t.Fatalf("Read == %v", err)
+	}
+	if err := json.Unmarshal(buf[:msgLen], reply); err != nil {
+		t.Fatalf("Unmarshal(%s) == %v", buf[:msgLen], err)
+	}
+}
+
+func requestNonce(t *testing.T, conn *net.UDPConn, expectedVersions []int) int64 {
+	var nonceMsg common.NonceMessage
+	exchange(t, conn, []byte("Hello aserver!  I'd like a nonce!"), &nonceMsg)
+	if !slices.Equal(nonceMsg.Versions, expectedVersions) {
+		t.Errorf("NonceMessage.Versions == %v, expected %v", nonceMsg.Versions, expectedVersions)
+	}
+	return nonceMsg.Nonce
+}
+
+func sendHash(t *testing.T, conn *net.UDPConn, hashMsg common.HashMessage) (common.GoalMessage, common.ErrMessage) {
+	req, _ := json.Marshal(hashMsg)
+	var reply struct {
+		common.GoalMessage
+		common.ErrMessage
+	}
+	exchange(t, conn, req, &reply)
+	return reply.GoalMessage, reply.ErrMessage
+}
+
+func verifyGoal(t *testing.T, goalMsg common.GoalMessage, errMsg common.ErrMessage) {
+	if goalMsg.Goal == "" || errMsg.Error != "" {
+		t.Errorf("reply == %v %v, expected a GoalMessage", goalMsg, errMsg)
+	}
+}
+
+func verifyErr(t *testing.T, goalMsg common.GoalMessage, errMsg common.ErrMessage, expectedErrMsg string) {
+	if goalMsg.Goal != "" || !strings.Contains(errMsg.Error, expectedErrMsg) {
+		t.Errorf("reply == %v %v, expected an ErrMessage containing %q", goalMsg, errMsg, expectedErrMsg)
+	}
+}
+
 func runTestWithExpectedExitErr(t *testing.T, testName string) {
 	var timeoutPeriod time.Duration = 3 //timeout before kill process (seconds)
 	testUtil.RunTestWithExpectedError(t, testName, "exit status", timeoutPeriod)
@@ -14,7 +93,7 @@ func runTestWithExpectedExitErr(t *testing.T, testName string) {
 
 func TestRunAuthServerWithInvalidIp(t *testing.T) {
 	if os.Getenv("BE_CRASHER") == "1" {
-		RunAuthServer("192.1.1.1.1:1234", 123456)
+		RunAuthServer("192.1.1.1.1:1234", []byte("123456"), common.DefaultMinVersion)
 	} else {
 		runTestWithExpectedExitErr(t, "TestRunAuthServerWithInvalidIp")
 	}
@@ -22,8 +101,118 @@ func TestRunAuthServerWithInvalidIp(t *testing.T) {
 
 func TestRunAuthServerWithInvalidPort(t *testing.T) {
 	if os.Getenv("BE_CRASHER") == "1" {
-		RunAuthServer("localhost:65536", 123456)
+		RunAuthServer("localhost:65536", []byte("123456"), common.DefaultMinVersion)
 	} else {
 		runTestWithExpectedExitErr(t, "TestRunAuthServerWithInvalidPort")
 	}
 }
+
+func TestAuthServerNegotiatesHMAC(t *testing.T) {
+	conn := startTestServer(t, Config{MinVersion: common.DefaultMinVersion})
+	defer conn.Close()
+
+	nonce := requestNonce(t, conn, []int{common.ProtocolHMACSHA256})
+	goalMsg, errMsg := sendHash(t, conn, common.ComputeHMACMessage(nonce, testKey))
+	verifyGoal(t, goalMsg, errMsg)
+
+	nonce = requestNonce(t, conn, []int{common.ProtocolHMACSHA256})
+	goalMsg, errMsg = sendHash(t, conn, common.ComputeHMACMessage(nonce, []byte("wrong key")))
+	verifyErr(t, goalMsg, errMsg, "Unexpected hash value")
+
+	//Clients predating versioning send no Version, and are rejected
+	nonce = requestNonce(t, conn, []int{common.ProtocolHMACSHA256})
+	goalMsg, errMsg = sendHash(t, conn, common.ComputeHashMessage(nonce, 123456))
+	verifyErr(t, goalMsg, errMsg, "unsupported protocol version 1, server accepts versions [2]")
+}
+
+func TestAuthServerAcceptsLegacyClientsWhenAllowed(t *testing.T) {
+	conn := startTestServer(t, Config{MinVersion: common.ProtocolMD5})
+	defer conn.Close()
+
+	nonce := requestNonce(t, conn, []int{common.ProtocolMD5, common.ProtocolHMACSHA256})
+	goalMsg, errMsg := sendHash(t, conn, common.ComputeHashMessage(nonce, 123456))
+	verifyGoal(t, goalMsg, errMsg)
+
+	nonce = requestNonce(t, conn, []int{common.ProtocolMD5, common.ProtocolHMACSHA256})
+	goalMsg, errMsg = sendHash(t, conn, common.ComputeHMACMessage(nonce, testKey))
+	verifyGoal(t, goalMsg, errMsg)
+
+	nonce = requestNonce(t, conn, []int{common.ProtocolMD5, common.ProtocolHMACSHA256})
+	goalMsg, errMsg = sendHash(t, conn, common.HashMessage{Hash: "00", Version: common.LatestProtocol + 1})
+	verifyErr(t, goalMsg, errMsg, "unsupported protocol version 3")
+}
+
+func TestAuthServerRejectsReplayedHash(t *testing.T) {
+	conn := startTestServer(t, Config{NonceTTL: time.Minute, Now: newFakeClock().now})
+	defer conn.Close()
+
+	nonce := requestNonce(t, conn, []int{common.ProtocolHMACSHA256})
+	hashMsg := common.ComputeHMACMessage(nonce, testKey)
+	goalMsg, errMsg := sendHash(t, conn, hashMsg)
+	verifyGoal(t, goalMsg, errMsg)
+	goalMsg, errMsg = sendHash(t, conn, hashMsg)
+	verifyErr(t, goalMsg, errMsg, "Unknown client address")
+}
+
+func TestAuthServerDeletesNonceOnFailedVerification(t *testing.T) {
+	conn := startTestServer(t, Config{NonceTTL: time.Minute, Now: newFakeClock().now})
+	defer conn.Close()
+
+	nonce := requestNonce(t, conn, []int{common.ProtocolHMACSHA256})
+	goalMsg, errMsg := sendHash(t, conn, common.ComputeHMACMessage(nonce, []byte("wrong key")))
+	verifyErr(t, goalMsg, errMsg, "Unexpected hash value")
+	//A second guess at the same nonce is refused even with the right key
+	goalMsg, errMsg = sendHash(t, conn, common.ComputeHMACMessage(nonce, testKey))
+	verifyErr(t, goalMsg, errMsg, "Unknown client address")
+}
+
+func TestAuthServerRejectsExpiredNonce(t *testing.T) {
+	clock := newFakeClock()
+	conn := startTestServer(t, Config{NonceTTL: time.Minute, Now: clock.now})
+	defer conn.Close()
+
+	nonce := requestNonce(t, conn, []int{common.ProtocolHMACSHA256})
+	clock.advance(time.Minute)
+	goalMsg, errMsg := sendHash(t, conn, common.ComputeHMACMessage(nonce, testKey))
+	verifyErr(t, goalMsg, errMsg, "Expired nonce")
+
+	nonce = requestNonce(t, conn, []int{common.ProtocolHMACSHA256})
+	clock.advance(time.Minute - time.Second)
+	goalMsg, errMsg = sendHash(t, conn, common.ComputeHMACMessage(nonce, testKey))
+	verifyGoal(t, goalMsg, errMsg)
+}
+
+func TestAuthServerAnswersRetransmissionsWithOriginalReply(t *testing.T) {
+	conn := startTestServer(t, Config{NonceTTL: time.Minute, Now: newFakeClock().now})
+	defer conn.Close()
+
+	nonceReq, _ := json.Marshal(common.NonceRequest{RequestID: 1})
+	var first, retransmitted common.NonceMessage
+	exchange(t, conn, nonceReq, &first)
+	exchange(t, conn, nonceReq, &retransmitted)
+	if first.RequestID != 1 || retransmitted.Nonce != first.Nonce || retransmitted.RequestID != first.RequestID {
+		t.Errorf("NonceMessages == %v then %v, expected the same nonce with RequestID 1", first, retransmitted)
+	}
+
+	hashMsg := common.ComputeHMACMessage(first.Nonce, testKey)
+	hashMsg.RequestID = 2
+	for i := 0; i < 2; i++ {
+		goalMsg, errMsg := sendHash(t, conn, hashMsg)
+		verifyGoal(t, goalMsg, errMsg)
+	}
+	//The nonce was still only used once
+	hashMsg.RequestID = 3
+	goalMsg, errMsg := sendHash(t, conn, hashMsg)
+	verifyErr(t, goalMsg, errMsg, "Unknown client address")
+}
+
+func TestAuthServerHandlesRequestsWithoutIDsEachTime(t *testing.T) {
+	conn := startTestServer(t, Config{NonceTTL: time.Minute, Now: newFakeClock().now})
+	defer conn.Close()
+
+	first := requestNonce(t, conn, []int{common.ProtocolHMACSHA256})
+	second := requestNonce(t, conn, []int{common.ProtocolHMACSHA256})
+	if first == second {
+		t.Errorf("requestNonce() returned %d twice, expected a new nonce for each request", first)
+	}
+}
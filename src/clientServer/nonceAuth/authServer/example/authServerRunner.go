@@ -2,7 +2,11 @@
 Example runner for authentication server
 
 Usage:
-$ go run authServerRunner.go [server UDP ip:port] [secret]
+$ go run authServerRunner.go [server UDP ip:port] [secret] [min protocol version]
+
+The min protocol version defaults to common.DefaultMinVersion.  Pass 1 to
+keep accepting MD5 from clients not yet upgraded.  Stops on interrupt, once
+in-flight clients have been answered.
 */
 
 package main
@@ -10,17 +14,43 @@ package main
 import (
 	"clientServer/nonceAuth/authServer"
 	"clientServer/nonceAuth/common"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"time"
 )
 
 func main() {
 	args := os.Args
-	if len(args) != 3 {
-		fmt.Println("Usage: authServerRunner [server UDP ip:port] [secret]")
+	if len(args) != 3 && len(args) != 4 {
+		fmt.Println("Usage: authServerRunner [server UDP ip:port] [secret] [min protocol version]")
 		os.Exit(-1)
 	}
 	udpIpPort := args[1]
-	secret := common.ParseIntFromStr(args[2])
-	authServer.RunAuthServer(udpIpPort, secret)
+	key := []byte(args[2])
+	minVersion := common.DefaultMinVersion
+	if len(args) == 4 {
+		minVersion = int(common.ParseIntFromStr(args[3]))
+	}
+	server, err := authServer.New(authServer.Config{Addr: udpIpPort, Key: key, MinVersion: minVersion})
+	if err != nil {
+		fmt.Println("Error creating aserver: ", err)
+		os.Exit(-1)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	addr, err := server.Start(ctx)
+	if err != nil {
+		fmt.Println("Error starting aserver: ", err)
+		os.Exit(-1)
+	}
+	fmt.Println("aserver listening on", addr)
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		fmt.Println("Error stopping aserver: ", err)
+	}
 }
@@ -0,0 +1,142 @@
+package authServer
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+//Values by key which expire ttl after being added.  Expired values are never
+//returned, and are removed when next looked up or swept.  Once the table holds
+//capacity values, adding another evicts the oldest.  Safe for concurrent use.
+type expiringTable[V any] struct {
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	//Entries in the order added, so the oldest, and first to expire, is at the front
+	order    *list.List
+	ttl      time.Duration
+	capacity int
+	//Returns the current time, replaced by a fake clock in tests
+	now func() time.Time
+}
+
+type expiringEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+func newExpiringTable[V any](ttl time.Duration, capacity int, now func() time.Time) *expiringTable[V] {
+	return &expiringTable[V]{
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		ttl:      ttl,
+		capacity: capacity,
+		now:      now,
+	}
+}
+
+//Adds value under key, replacing any value added before
+func (table *expiringTable[V]) put(key string, value V) {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	if element, ok := table.entries[key]; ok {
+		table.remove(element)
+	}
+	table.pushBack(key, value)
+}
+
+//Adds value under key unless key holds an unexpired value, which is returned
+//with loaded == true instead
+func (table *expiringTable[V]) putIfAbsent(key string, value V) (existing V, loaded bool) {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	if element, ok := table.entries[key]; ok {
+		entry := element.Value.(*expiringEntry[V])
+		if table.now().Before(entry.expires) {
+			return entry.value, true
+		}
+		table.remove(element)
+	}
+	table.pushBack(key, value)
+	return existing, false
+}
+
+//Replaces the value under key without extending its expiry, unless key has
+//since been evicted
+func (table *expiringTable[V]) update(key string, value V) {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	if element, ok := table.entries[key]; ok {
+		element.Value.(*expiringEntry[V]).value = value
+	}
+}
+
+//Removes and returns the value under key.  ok is false if there is none, and
+//expired is true if it has expired.
+func (table *expiringTable[V]) pop(key string) (value V, expired bool, ok bool) {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	element, ok := table.entries[key]
+	if !ok {
+		return value, false, false
+	}
+	table.remove(element)
+	entry := element.Value.(*expiringEntry[V])
+	if !table.now().Before(entry.expires) {
+		return value, true, true
+	}
+	return entry.value, false, true
+}
+
+//Removes expired values, returns how many were removed
+func (table *expiringTable[V]) sweep() int {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	now := table.now()
+	removed := 0
+	for front := table.order.Front(); front != nil && !now.Before(front.Value.(*expiringEntry[V]).expires); front = table.order.Front() {
+		table.remove(front)
+		removed++
+	}
+	return removed
+}
+
+//Returns the number of values held, including expired ones not yet swept
+func (table *expiringTable[V]) len() int {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	return table.order.Len()
+}
+
+//Evicts the oldest values to make room, then adds value.  Must be called with
+//table.mutex held and key absent.
+func (table *expiringTable[V]) pushBack(key string, value V) {
+	for table.order.Len() >= table.capacity {
+		table.remove(table.order.Front())
+	}
+	entry := &expiringEntry[V]{key: key, value: value, expires: table.now().Add(table.ttl)}
+	table.entries[key] = table.order.PushBack(entry)
+}
+
+//Must be called with table.mutex held
+func (table *expiringTable[V]) remove(element *list.Element) {
+	delete(table.entries, element.Value.(*expiringEntry[V]).key)
+	table.order.Remove(element)
+}
+
+//Calls each of sweeps every interval until stop is closed, or forever if stop is nil
+func sweepEvery(interval time.Duration, stop <-chan struct{}, sweeps ...func() int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, sweep := range sweeps {
+				sweep()
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package authServer
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+const (
+	//How long a client has to answer its nonce
+	DefaultNonceTTL = 30 * time.Second
+	//Most outstanding nonces kept before the oldest are evicted
+	DefaultMaxNonces = 10000
+)
+
+var (
+	errUnknownNonce = errors.New("no nonce issued to client")
+	errExpiredNonce = errors.New("nonce expired")
+)
+
+//Outstanding nonces by client address.  Each nonce can be taken once, within
+//ttl of being issued.  Once the table holds capacity nonces, issuing another
+//evicts the oldest.  Safe for concurrent use.
+type nonceTable struct {
+	*expiringTable[int64]
+}
+
+func newNonceTable(ttl time.Duration, capacity int, now func() time.Time) *nonceTable {
+	return &nonceTable{newExpiringTable[int64](ttl, capacity, now)}
+}
+
+//Generates a new nonce for addr, replacing any nonce issued to it before
+func (table *nonceTable) issue(addr string) int64 {
+	nonce := newNonce()
+	table.put(addr, nonce)
+	return nonce
+}
+
+//Returns a non-negative nonce from a cryptographically secure source, so
+//clients cannot predict the nonces issued to others
+func newNonce() int64 {
+	var buf [8]byte
+	//crypto/rand.Read never returns an error, it crashes the program instead
+	rand.Read(buf[:])
+	return int64(binary.BigEndian.Uint64(buf[:]) &^ (1 << 63))
+}
+
+//Removes and returns the nonce issued to addr.  Returns errUnknownNonce if
+//there is none, or errExpiredNonce if it has expired.
+func (table *nonceTable) take(addr string) (int64, error) {
+	nonce, expired, ok := table.pop(addr)
+	if !ok {
+		return 0, errUnknownNonce
+	}
+	if expired {
+		return 0, errExpiredNonce
+	}
+	return nonce, nil
+}
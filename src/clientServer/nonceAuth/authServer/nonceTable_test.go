@@ -0,0 +1,167 @@
+package authServer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+//Clock which only moves when advanced, safe for concurrent use
+type fakeClock struct {
+	mutex sync.Mutex
+	time  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{time: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (clock *fakeClock) now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.time
+}
+
+func (clock *fakeClock) advance(duration time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.time = clock.time.Add(duration)
+}
+
+func verifyTake(t *testing.T, table *nonceTable, addr string, expectedNonce int64, expectedErr error) {
+	nonce, err := table.take(addr)
+	if nonce != expectedNonce || err != expectedErr {
+		t.Errorf("take(%s) == %d, %v, expected %d, %v", addr, nonce, err, expectedNonce, expectedErr)
+	}
+}
+
+func verifyLen(t *testing.T, table *nonceTable, expected int) {
+	if table.len() != expected {
+		t.Errorf("len() == %d, expected %d", table.len(), expected)
+	}
+}
+
+func TestNonceTable_SingleUse(t *testing.T) {
+	table := newNonceTable(time.Minute, 10, newFakeClock().now)
+	nonce := table.issue("a")
+	verifyTake(t, table, "a", nonce, nil)
+	verifyTake(t, table, "a", 0, errUnknownNonce)
+	verifyTake(t, table, "b", 0, errUnknownNonce)
+	verifyLen(t, table, 0)
+}
+
+func TestNonceTable_ReissueReplacesNonce(t *testing.T) {
+	table := newNonceTable(time.Minute, 10, newFakeClock().now)
+	first := table.issue("a")
+	second := table.issue("a")
+	if first == second {
+		t.Errorf("issue(a) returned %d twice", first)
+	}
+	verifyLen(t, table, 1)
+	verifyTake(t, table, "a", second, nil)
+}
+
+func TestNonceTable_Expiry(t *testing.T) {
+	clock := newFakeClock()
+	table := newNonceTable(time.Minute, 10, clock.now)
+	nonce := table.issue("a")
+	table.issue("b")
+	clock.advance(time.Minute - time.Nanosecond)
+	verifyTake(t, table, "a", nonce, nil)
+
+	clock.advance(time.Nanosecond)
+	verifyTake(t, table, "b", 0, errExpiredNonce)
+	//An expired nonce is removed when taken
+	verifyTake(t, table, "b", 0, errUnknownNonce)
+}
+
+func TestNonceTable_Sweep(t *testing.T) {
+	clock := newFakeClock()
+	table := newNonceTable(time.Minute, 10, clock.now)
+	table.issue("a")
+	table.issue("b")
+	clock.advance(30 * time.Second)
+	fresh := table.issue("c")
+	//Reissuing moves a to the back, with a new expiry
+	table.issue("a")
+	clock.advance(30 * time.Second)
+
+	if removed := table.sweep(); removed != 1 {
+		t.Errorf("sweep() == %d, expected 1", removed)
+	}
+	verifyLen(t, table, 2)
+	verifyTake(t, table, "c", fresh, nil)
+
+	clock.advance(30 * time.Second)
+	if removed := table.sweep(); removed != 1 {
+		t.Errorf("sweep() == %d, expected 1", removed)
+	}
+	verifyLen(t, table, 0)
+}
+
+func TestNonceTable_SweepsInBackground(t *testing.T) {
+	clock := newFakeClock()
+	table := newNonceTable(time.Minute, 10, clock.now)
+	stop := make(chan struct{})
+	defer close(stop)
+	go sweepEvery(time.Millisecond, stop, table.sweep)
+
+	table.issue("a")
+	time.Sleep(10 * time.Millisecond)
+	verifyLen(t, table, 1)
+
+	clock.advance(time.Minute)
+	deadline := time.Now().Add(3 * time.Second)
+	for table.len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	verifyLen(t, table, 0)
+}
+
+func TestNonceTable_EvictsOldest(t *testing.T) {
+	table := newNonceTable(time.Minute, 3, newFakeClock().now)
+	nonces := map[string]int64{}
+	for _, addr := range []string{"a", "b", "c", "d", "e"} {
+		nonces[addr] = table.issue(addr)
+	}
+	verifyLen(t, table, 3)
+	verifyTake(t, table, "a", 0, errUnknownNonce)
+	verifyTake(t, table, "b", 0, errUnknownNonce)
+	verifyTake(t, table, "c", nonces["c"], nil)
+	verifyTake(t, table, "e", nonces["e"], nil)
+}
+
+func TestNewNonce(t *testing.T) {
+	seen := map[int64]bool{}
+	for i := 0; i < 1000; i++ {
+		nonce := newNonce()
+		if nonce < 0 || seen[nonce] {
+			t.Fatalf("newNonce() == %d, expected a new non-negative nonce", nonce)
+		}
+		seen[nonce] = true
+	}
+}
+
+func TestNonceTable_Concurrent(t *testing.T) {
+	table := newNonceTable(time.Minute, 100, newFakeClock().now)
+	var wait sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for j := 0; j < 1000; j++ {
+				addr := fmt.Sprintf("%d:%d", i, j%200)
+				nonce := table.issue(addr)
+				if taken, err := table.take(addr); err == nil && taken != nonce {
+					t.Errorf("take(%s) == %d, expected %d", addr, taken, nonce)
+				}
+				table.sweep()
+			}
+		}()
+	}
+	wait.Wait()
+	if table.len() > 100 {
+		t.Errorf("len() == %d, expected at most 100", table.len())
+	}
+}
@@ -0,0 +1,37 @@
+package authServer
+
+import (
+	"fmt"
+	"time"
+)
+
+//Replies sent by client address and request ID, so a retransmitted request is
+//answered with the original reply rather than handled again: a retransmitted
+//nonce request gets the same nonce, and a retransmitted hash gets its goal
+//even though the nonce has been taken.  Replies are kept for ttl, and once
+//the cache holds capacity replies, adding another evicts the oldest.  Safe for
+//concurrent use.
+type replyCache struct {
+	//nil replies while requests are being handled
+	*expiringTable[[]byte]
+}
+
+func newReplyCache(ttl time.Duration, capacity int, now func() time.Time) *replyCache {
+	return &replyCache{newExpiringTable[[]byte](ttl, capacity, now)}
+}
+
+func replyKey(addr string, requestID uint64) string {
+	return fmt.Sprintf("%s/%d", addr, requestID)
+}
+
+//Returns seen == false the first time key is begun, after which the caller
+//must handle the request and finish key with its reply.  Otherwise returns
+//the reply to resend, or nil if the first request is still being handled.
+func (cache *replyCache) begin(key string) (reply []byte, seen bool) {
+	return cache.putIfAbsent(key, nil)
+}
+
+//Records the reply to the request begun with key, unless it has since been evicted
+func (cache *replyCache) finish(key string, reply []byte) {
+	cache.update(key, reply)
+}
@@ -0,0 +1,69 @@
+package authServer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func verifyBegin(t *testing.T, cache *replyCache, key string, expectedReply []byte, expectedSeen bool) {
+	reply, seen := cache.begin(key)
+	if !bytes.Equal(reply, expectedReply) || seen != expectedSeen {
+		t.Errorf("begin(%s) == %q, %v, expected %q, %v", key, reply, seen, expectedReply, expectedSeen)
+	}
+}
+
+func TestReplyCache_Duplicates(t *testing.T) {
+	cache := newReplyCache(time.Minute, 10, newFakeClock().now)
+	key := replyKey("127.0.0.1:1234", 7)
+	verifyBegin(t, cache, key, nil, false)
+	//A duplicate while the first is still being handled is dropped
+	verifyBegin(t, cache, key, nil, true)
+	cache.finish(key, []byte("reply"))
+	verifyBegin(t, cache, key, []byte("reply"), true)
+
+	//Other requests from the same client, and the same request ID from other clients, are handled
+	verifyBegin(t, cache, replyKey("127.0.0.1:1234", 8), nil, false)
+	verifyBegin(t, cache, replyKey("127.0.0.1:1235", 7), nil, false)
+}
+
+func TestReplyCache_Expiry(t *testing.T) {
+	clock := newFakeClock()
+	cache := newReplyCache(time.Minute, 10, clock.now)
+	cache.begin("a")
+	cache.finish("a", []byte("first"))
+	clock.advance(30 * time.Second)
+	cache.begin("b")
+	clock.advance(30 * time.Second)
+
+	//An expired reply is forgotten, so the request is handled again
+	verifyBegin(t, cache, "a", nil, false)
+	cache.finish("a", []byte("second"))
+	verifyBegin(t, cache, "a", []byte("second"), true)
+
+	if removed := cache.sweep(); removed != 0 {
+		t.Errorf("sweep() == %d, expected 0", removed)
+	}
+	clock.advance(30 * time.Second)
+	if removed := cache.sweep(); removed != 1 {
+		t.Errorf("sweep() == %d, expected 1", removed)
+	}
+	if cache.len() != 1 {
+		t.Errorf("len() == %d, expected 1", cache.len())
+	}
+}
+
+func TestReplyCache_EvictsOldest(t *testing.T) {
+	cache := newReplyCache(time.Minute, 2, newFakeClock().now)
+	for _, key := range []string{"a", "b", "c"} {
+		cache.begin(key)
+		cache.finish(key, []byte(key))
+	}
+	if cache.len() != 2 {
+		t.Errorf("len() == %d, expected 2", cache.len())
+	}
+	verifyBegin(t, cache, "c", []byte("c"), true)
+	//Finishing an evicted request is a no-op
+	cache.finish("a", []byte("a"))
+	verifyBegin(t, cache, "a", nil, false)
+}
@@ -0,0 +1,188 @@
+package authServer
+
+import (
+	"clientServer/nonceAuth/common"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+//Returned by Start once the server has been shut down
+var ErrServerClosed = errors.New("authServer: server closed")
+
+const (
+	//Delays before receiving again after ReadFromUDP fails, doubling while it keeps failing
+	minReadRetryDelay = 5 * time.Millisecond
+	maxReadRetryDelay = time.Second
+)
+
+type Config struct {
+	//UDP ip:port to listen on.  A port of 0 picks a free port, which Start returns.
+	Addr string
+	//Key shared with clients
+	Key []byte
+	//Oldest protocol version accepted, common.DefaultMinVersion if 0
+	MinVersion int
+	//How long a client has to answer its nonce, and to retransmit a request
+	//and get the original reply, DefaultNonceTTL if 0
+	NonceTTL time.Duration
+	//Most outstanding nonces, and most replies kept to answer retransmitted
+	//requests, DefaultMaxNonces if 0
+	MaxNonces int
+	//Returns the current time, time.Now if nil
+	Now func() time.Time
+	//Logs errors replying to clients, which cannot be returned, log.Default() if nil
+	ErrorLog *log.Logger
+}
+
+//Authentication server which can be embedded in other services: Start serves
+//in the background until Shutdown
+type Server struct {
+	config  Config
+	nonces  *nonceTable
+	replies *replyCache
+
+	mutex   sync.Mutex
+	conn    *net.UDPConn
+	started bool
+	closed  bool
+	//Closed by Shutdown to stop the background sweep of expired nonces and
+	//replies, and to wake the receive loop if it is backing off
+	stopSweep chan struct{}
+	//Closed once the receive loop has exited, so no handlers are started after it
+	served   chan struct{}
+	handlers sync.WaitGroup
+	//Closed once in-flight handlers have finished and conn is closed
+	drained chan struct{}
+}
+
+//Creates a server from config, filling in defaults for its zero fields
+func New(config Config) (*Server, error) {
+	if len(config.Key) == 0 {
+		return nil, fmt.Errorf("authServer: empty key")
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = common.DefaultMinVersion
+	}
+	if config.MinVersion < common.ProtocolMD5 || config.MinVersion > common.LatestProtocol {
+		return nil, fmt.Errorf("authServer: min protocol version %d is not between %d and %d", config.MinVersion, common.ProtocolMD5, common.LatestProtocol)
+	}
+	if config.NonceTTL <= 0 {
+		config.NonceTTL = DefaultNonceTTL
+	}
+	if config.MaxNonces <= 0 {
+		config.MaxNonces = DefaultMaxNonces
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	if config.ErrorLog == nil {
+		config.ErrorLog = log.Default()
+	}
+	return &Server{
+		config:    config,
+		nonces:    newNonceTable(config.NonceTTL, config.MaxNonces, config.Now),
+		replies:   newReplyCache(config.NonceTTL, config.MaxNonces, config.Now),
+		stopSweep: make(chan struct{}),
+		served:    make(chan struct{}),
+		drained:   make(chan struct{}),
+	}, nil
+}
+
+//Binds to the configured address and serves clients in the background until
+//Shutdown.  Returns the bound address.  ctx only bounds binding.
+func (server *Server) Start(ctx context.Context) (net.Addr, error) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.closed {
+		return nil, ErrServerClosed
+	}
+	if server.started {
+		return nil, fmt.Errorf("authServer: server already started")
+	}
+	var listenConfig net.ListenConfig
+	packetConn, err := listenConfig.ListenPacket(ctx, "udp", server.config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("authServer: listening on %s: %w", server.config.Addr, err)
+	}
+	server.conn = packetConn.(*net.UDPConn)
+	server.started = true
+	go sweepEvery(server.config.NonceTTL/2, server.stopSweep, server.nonces.sweep, server.replies.sweep)
+	go server.serve()
+	return server.conn.LocalAddr(), nil
+}
+
+//Stops receiving messages, then waits for in-flight handlers to send their
+//replies before closing the connection.  Returns ctx.Err() if ctx is done
+//first, in which case Shutdown may be called again to keep waiting.
+func (server *Server) Shutdown(ctx context.Context) error {
+	server.mutex.Lock()
+	if !server.closed {
+		server.closed = true
+		close(server.stopSweep)
+		if server.started {
+			//Unblocks the receive loop, leaving conn open for handlers to reply on
+			server.conn.SetReadDeadline(time.Now())
+			go server.drain()
+		} else {
+			close(server.drained)
+		}
+	}
+	server.mutex.Unlock()
+
+	select {
+	case <-server.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//Closes conn once the receive loop has exited and in-flight handlers have finished
+func (server *Server) drain() {
+	<-server.served
+	server.handlers.Wait()
+	server.conn.Close()
+	close(server.drained)
+}
+
+func (server *Server) isClosed() bool {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.closed
+}
+
+//Receive loop, starting a handler for each message until Shutdown
+func (server *Server) serve() {
+	defer close(server.served)
+	var retryDelay time.Duration
+	for {
+		var buf [1024]byte
+		msgLen, clientUDPAddr, err := server.conn.ReadFromUDP(buf[:])
+		if errors.Is(err, net.ErrClosed) || errors.Is(err, os.ErrDeadlineExceeded) && server.isClosed() {
+			return
+		}
+		if err != nil {
+			//Back off so that a persistent error neither spins nor floods ErrorLog
+			retryDelay = min(max(2*retryDelay, minReadRetryDelay), maxReadRetryDelay)
+			server.config.ErrorLog.Printf("authServer: ReadFromUDP: %v, retrying in %v", err, retryDelay)
+			select {
+			case <-time.After(retryDelay):
+			case <-server.stopSweep:
+			}
+			continue
+		}
+		retryDelay = 0
+		//Start go routine to handle client, continue listening for new clients
+		server.handlers.Add(1)
+		go func() {
+			defer server.handlers.Done()
+			server.handleMessage(clientUDPAddr, buf[0:msgLen])
+		}()
+	}
+}
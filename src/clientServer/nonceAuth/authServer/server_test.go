@@ -0,0 +1,250 @@
+package authServer
+
+import (
+	"clientServer/nonceAuth/common"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newQuietServer(t *testing.T, config Config) *Server {
+	if config.Key == nil {
+		config.Key = testKey
+	}
+	config.ErrorLog = log.New(io.Discard, "", 0)
+	server, err := New(config)
+	if err != nil {
+		t.Fatalf("New(%+v) == %v", config, err)
+	}
+	return server
+}
+
+func verifyShutdown(t *testing.T, server *Server, ctx context.Context, expectedErr error) {
+	if err := server.Shutdown(ctx); err != expectedErr {
+		t.Errorf("Shutdown() == %v, expected %v", err, expectedErr)
+	}
+}
+
+func TestNew(t *testing.T) {
+	testNew_EmptyKey(t)
+	testNew_InvalidMinVersion(t)
+	testNew_Defaults(t)
+}
+
+func testNew_EmptyKey(t *testing.T) {
+	if _, err := New(Config{Addr: "localhost:0"}); err == nil {
+		t.Errorf("New() with no key == nil error, expected an error")
+	}
+}
+
+func testNew_InvalidMinVersion(t *testing.T) {
+	for _, minVersion := range []int{-1, common.LatestProtocol + 1} {
+		if _, err := New(Config{Addr: "localhost:0", Key: testKey, MinVersion: minVersion}); err == nil {
+			t.Errorf("New() with MinVersion %d == nil error, expected an error", minVersion)
+		}
+	}
+}
+
+func testNew_Defaults(t *testing.T) {
+	server, err := New(Config{Addr: "localhost:0", Key: testKey})
+	if err != nil {
+		t.Fatalf("New() == %v", err)
+	}
+	config := server.config
+	if config.MinVersion != common.DefaultMinVersion || config.NonceTTL != DefaultNonceTTL ||
+		config.MaxNonces != DefaultMaxNonces || config.Now == nil || config.ErrorLog == nil {
+		t.Errorf("New() config == %+v, expected defaults filled in", config)
+	}
+}
+
+func TestStart(t *testing.T) {
+	testStart_FreePort(t)
+	testStart_InvalidAddr(t)
+	testStart_Twice(t)
+	testStart_AfterShutdown(t)
+}
+
+func testStart_FreePort(t *testing.T) {
+	server := newQuietServer(t, Config{Addr: "localhost:0"})
+	defer server.Shutdown(context.Background())
+	addr, err := server.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() == %v", err)
+	}
+	if addr.(*net.UDPAddr).Port == 0 {
+		t.Errorf("Start() == %v, expected a nonzero port", addr)
+	}
+}
+
+func testStart_InvalidAddr(t *testing.T) {
+	for _, addr := range []string{"192.1.1.1.1:1234", "localhost:65536"} {
+		server := newQuietServer(t, Config{Addr: addr})
+		if _, err := server.Start(context.Background()); err == nil {
+			t.Errorf("Start() on %s == nil error, expected an error", addr)
+		}
+		verifyShutdown(t, server, context.Background(), nil)
+	}
+}
+
+func testStart_Twice(t *testing.T) {
+	server := newQuietServer(t, Config{Addr: "localhost:0"})
+	defer server.Shutdown(context.Background())
+	if _, err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Start() == %v", err)
+	}
+	if _, err := server.Start(context.Background()); err == nil {
+		t.Errorf("second Start() == nil error, expected an error")
+	}
+}
+
+func testStart_AfterShutdown(t *testing.T) {
+	server := newQuietServer(t, Config{Addr: "localhost:0"})
+	verifyShutdown(t, server, context.Background(), nil)
+	if _, err := server.Start(context.Background()); err != ErrServerClosed {
+		t.Errorf("Start() after Shutdown == %v, expected %v", err, ErrServerClosed)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	testShutdown_StopsServing(t)
+	testShutdown_WaitsForHandlers(t)
+	testShutdown_WhileBackingOff(t)
+}
+
+func testShutdown_StopsServing(t *testing.T) {
+	server := newQuietServer(t, Config{Addr: "localhost:0"})
+	addr, err := server.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() == %v", err)
+	}
+	conn, err := net.Dial("udp", addr.String())
+	if err != nil {
+		t.Fatalf("Dial(%s) == %v", addr, err)
+	}
+	defer conn.Close()
+	requestNonce(t, conn.(*net.UDPConn), []int{common.ProtocolHMACSHA256})
+
+	verifyShutdown(t, server, context.Background(), nil)
+	//Shutdown may be called again once stopped
+	verifyShutdown(t, server, context.Background(), nil)
+
+	conn.Write([]byte("Hello aserver!  I'd like a nonce!"))
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	var buf [1024]byte
+	if msgLen, err := conn.Read(buf[:]); err == nil {
+		t.Errorf("Read after Shutdown == %s, expected no reply", buf[:msgLen])
+	}
+
+	//The port is released
+	rebound, err := net.ListenPacket("udp", addr.String())
+	if err != nil {
+		t.Fatalf("ListenPacket(%s) after Shutdown == %v", addr, err)
+	}
+	rebound.Close()
+}
+
+//Clock which blocks each caller until released, to hold a handler in flight
+type blockingClock struct {
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (clock *blockingClock) now() time.Time {
+	clock.once.Do(func() { close(clock.entered) })
+	<-clock.release
+	return time.Now()
+}
+
+func testShutdown_WaitsForHandlers(t *testing.T) {
+	clock := &blockingClock{entered: make(chan struct{}), release: make(chan struct{})}
+	//Long enough that the sweeper never reads the clock
+	server := newQuietServer(t, Config{Addr: "localhost:0", NonceTTL: time.Hour, Now: clock.now})
+	addr, err := server.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() == %v", err)
+	}
+	conn, err := net.Dial("udp", addr.String())
+	if err != nil {
+		t.Fatalf("Dial(%s) == %v", addr, err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("Hello aserver!  I'd like a nonce!"))
+	select {
+	case <-clock.entered:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("handler never started")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() with a handler in flight == %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	close(clock.release)
+	verifyShutdown(t, server, context.Background(), nil)
+
+	//The handler's reply is sent before the connection is closed
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	var buf [1024]byte
+	msgLen, err := conn.Read(buf[:])
+	if err != nil {
+		t.Fatalf("Read after Shutdown == %v, expected the in-flight nonce reply", err)
+	}
+	var nonceMsg common.NonceMessage
+	if err := json.Unmarshal(buf[:msgLen], &nonceMsg); err != nil || len(nonceMsg.Versions) == 0 {
+		t.Errorf("Read after Shutdown == %s, expected a NonceMessage", buf[:msgLen])
+	}
+}
+
+//Writer counting the lines logged to it
+type lineCounter struct {
+	lines atomic.Int64
+}
+
+func (counter *lineCounter) Write(p []byte) (int, error) {
+	counter.lines.Add(1)
+	return len(p), nil
+}
+
+//Starts a server whose reads fail until Shutdown, logging to counter
+func startFailingServer(t *testing.T, counter *lineCounter) *Server {
+	server, err := New(Config{Addr: "localhost:0", Key: testKey, ErrorLog: log.New(counter, "", 0)})
+	if err != nil {
+		t.Fatalf("New() == %v", err)
+	}
+	if _, err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Start() == %v", err)
+	}
+	server.conn.SetReadDeadline(time.Now().Add(-time.Second))
+	return server
+}
+
+func TestServe_BacksOffReadErrors(t *testing.T) {
+	var counter lineCounter
+	server := startFailingServer(t, &counter)
+	defer server.Shutdown(context.Background())
+	time.Sleep(200 * time.Millisecond)
+	//Retrying after 5, 10, 20, 40, 80ms... logs about 6 errors in 200ms
+	if lines := counter.lines.Load(); lines == 0 || lines > 20 {
+		t.Errorf("Logged %d read errors in 200ms, expected a few", lines)
+	}
+}
+
+func testShutdown_WhileBackingOff(t *testing.T) {
+	var counter lineCounter
+	server := startFailingServer(t, &counter)
+	//Wait for the retry delay to grow past a second
+	time.Sleep(1500 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	verifyShutdown(t, server, ctx, nil)
+}
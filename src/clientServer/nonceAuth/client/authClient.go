@@ -0,0 +1,321 @@
+package client
+
+import (
+	"clientServer/nonceAuth/common"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	//How long Authenticate waits for the aserver if Config.Timeout is 0
+	DefaultTimeout = 10 * time.Second
+	//How long to wait for a reply before the first retransmission if
+	//Config.RetryInterval is 0
+	DefaultRetryInterval = 250 * time.Millisecond
+	//Longest wait between retransmissions if Config.MaxRetryInterval is 0
+	DefaultMaxRetryInterval = 2 * time.Second
+)
+
+var (
+	//The aserver has no outstanding nonce for this client's address
+	ErrUnknownClient = errors.New("client: aserver has no nonce for client")
+	//The aserver's nonce expired before the client answered it
+	ErrExpiredNonce = errors.New("client: nonce expired")
+	//The aserver rejected the hash, so the client and aserver keys differ
+	ErrBadHash = errors.New("client: aserver rejected hash")
+	//The aserver did not reply before the deadline.  Errors wrapping ErrTimeout
+	//also wrap context.DeadlineExceeded.
+	ErrTimeout = errors.New("client: timed out waiting for aserver")
+	//The aserver's reply was not the message expected, such as a goal in
+	//reply to a nonce request
+	ErrBadReply = errors.New("client: malformed reply from aserver")
+)
+
+//ErrMessage reply from an aserver.  Unwraps to ErrUnknownClient,
+//ErrExpiredNonce, ErrBadHash or common.ErrUnsupportedVersion, or to nil if
+//the message is not one of those.
+type ServerError struct {
+	//aserver which replied
+	Server *net.UDPAddr
+	//Error as sent by the aserver
+	Message string
+	Err     error
+}
+
+func (err *ServerError) Error() string {
+	return fmt.Sprintf("client: aserver %v replied %q", err.Server, err.Message)
+}
+
+func (err *ServerError) Unwrap() error {
+	return err.Err
+}
+
+func newServerError(server *net.UDPAddr, message string) *ServerError {
+	serverErr := &ServerError{Server: server, Message: message}
+	switch {
+	case message == common.ErrMsgUnknownClient:
+		serverErr.Err = ErrUnknownClient
+	case message == common.ErrMsgExpiredNonce:
+		serverErr.Err = ErrExpiredNonce
+	case message == common.ErrMsgUnexpectedHash:
+		serverErr.Err = ErrBadHash
+	case strings.HasPrefix(message, common.ErrUnsupportedVersion.Error()):
+		serverErr.Err = common.ErrUnsupportedVersion
+	}
+	return serverErr
+}
+
+type Config struct {
+	//Local UDP ip:port to send from, any free port if empty
+	LocalAddr string
+	//aserver UDP ip:port
+	ServerAddr string
+	//Key shared with the aserver
+	Key []byte
+	//Oldest protocol version the client will use, common.DefaultMinVersion if 0
+	MinVersion int
+	//Longest Authenticate waits for the aserver, DefaultTimeout if 0.  An
+	//earlier deadline on the context passed to Authenticate takes precedence.
+	Timeout time.Duration
+	//How long to wait for a reply before retransmitting a request, doubling
+	//after each retransmission up to MaxRetryInterval.  Defaults to
+	//DefaultRetryInterval and DefaultMaxRetryInterval if 0.
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+	//Logs unparsable datagrams from the aserver, which are skipped,
+	//log.Default() if nil
+	ErrorLog *log.Logger
+}
+
+//Successful authentication
+type Result struct {
+	Goal string
+	//aserver which answered
+	Server *net.UDPAddr
+	//Protocol version the nonce was hashed under
+	Version int
+}
+
+//Authenticates with an aserver, returning errors rather than exiting
+type Client struct {
+	config     Config
+	serverAddr *net.UDPAddr
+}
+
+//Creates a client from config, filling in defaults for its zero fields
+func New(config Config) (*Client, error) {
+	if len(config.Key) == 0 {
+		return nil, fmt.Errorf("client: empty key")
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = common.DefaultMinVersion
+	}
+	if config.MinVersion < common.ProtocolMD5 || config.MinVersion > common.LatestProtocol {
+		return nil, fmt.Errorf("client: min protocol version %d is not between %d and %d", config.MinVersion, common.ProtocolMD5, common.LatestProtocol)
+	}
+	if config.LocalAddr == "" {
+		config.LocalAddr = ":0"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = DefaultRetryInterval
+	}
+	if config.MaxRetryInterval <= 0 {
+		config.MaxRetryInterval = DefaultMaxRetryInterval
+	}
+	config.MaxRetryInterval = max(config.MaxRetryInterval, config.RetryInterval)
+	if config.ErrorLog == nil {
+		config.ErrorLog = log.Default()
+	}
+	serverAddr, err := net.ResolveUDPAddr("udp", config.ServerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("client: resolving aserver address %s: %w", config.ServerAddr, err)
+	}
+	return &Client{config: config, serverAddr: serverAddr}, nil
+}
+
+//Retrieves a nonce from the aserver, hashes it under the latest protocol
+//version both sides accept, and returns the aserver's GoalMessage.  Error
+//replies from the aserver are returned as *ServerError.
+//
+//Requests are retransmitted until answered or the deadline passes.  Replies
+//from addresses other than Config.ServerAddr, replies to other requests, and
+//datagrams which are not JSON are ignored.
+//
+//Each call sends from a new connection, so calls are safe to run concurrently
+//unless Config.LocalAddr names a fixed port.
+func (client *Client) Authenticate(ctx context.Context) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, client.config.Timeout)
+	defer cancel()
+
+	var listenConfig net.ListenConfig
+	packetConn, err := listenConfig.ListenPacket(ctx, "udp", client.config.LocalAddr)
+	if err != nil {
+		return Result{}, fmt.Errorf("client: listening on %s: %w", client.config.LocalAddr, err)
+	}
+	conn := packetConn.(*net.UDPConn)
+	defer conn.Close()
+
+	requestID := newRequestID()
+	nonceReply, server, err := client.exchange(ctx, conn, requestID, common.NonceRequest{RequestID: requestID})
+	if err != nil {
+		return Result{}, err
+	}
+	if nonceReply.Nonce == nil {
+		return Result{}, fmt.Errorf("%w: %v sent no nonce", ErrBadReply, server)
+	}
+	version, err := common.ChooseVersion(nonceReply.Versions, client.config.MinVersion)
+	if err != nil {
+		return Result{}, err
+	}
+	hashMsg, err := common.ComputeVersionedHashMessage(version, *nonceReply.Nonce, client.config.Key)
+	if err != nil {
+		return Result{}, err
+	}
+	hashMsg.RequestID = newRequestID()
+	goalReply, server, err := client.exchange(ctx, conn, hashMsg.RequestID, hashMsg)
+	if err != nil {
+		return Result{}, err
+	}
+	if goalReply.Goal == nil {
+		return Result{}, fmt.Errorf("%w: %v sent no goal", ErrBadReply, server)
+	}
+	return Result{Goal: *goalReply.Goal, Server: server, Version: version}, nil
+}
+
+//Any message from the aserver.  Fields are nil when absent.
+type reply struct {
+	Nonce     *int64
+	Versions  []int
+	Goal      *string
+	Error     *string
+	RequestID uint64
+}
+
+//Returns a random nonzero request ID
+func newRequestID() uint64 {
+	for {
+		if requestID := rand.Uint64(); requestID != 0 {
+			return requestID
+		}
+	}
+}
+
+//Sends request, whose RequestID is requestID, to the aserver, retransmitting
+//it with exponential backoff until the aserver replies or ctx is done.
+//Returns the reply and the address it came from, or a *ServerError for an
+//ErrMessage.  Replies from other addresses, with another request's
+//RequestID, or which cannot be parsed, are ignored.  Replies without a RequestID come from servers
+//predating request IDs, and are taken as the reply.
+func (client *Client) exchange(ctx context.Context, conn *net.UDPConn, requestID uint64, request interface{}) (reply, *net.UDPAddr, error) {
+	req, err := json.Marshal(request)
+	if err != nil {
+		return reply{}, nil, fmt.Errorf("client: marshalling %T: %w", request, err)
+	}
+
+	//Unblock the read if ctx is canceled before its deadline
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	for attempt := 1; ; attempt++ {
+		if _, err := conn.WriteToUDP(req, client.serverAddr); err != nil {
+			return reply{}, nil, fmt.Errorf("client: writing to aserver %v: %w", client.serverAddr, err)
+		}
+		retryAt := time.Now().Add(client.retryInterval(attempt))
+		deadline, ok := ctx.Deadline()
+		if !ok || retryAt.Before(deadline) {
+			deadline = retryAt
+		}
+		conn.SetReadDeadline(deadline)
+		//ctx may have been canceled before the deadline was set, undoing AfterFunc
+		if ctx.Err() != nil {
+			return reply{}, nil, client.timeoutError(ctx, attempt)
+		}
+
+		received, from, err := client.readReply(conn, requestID)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			if ctx.Err() != nil || !deadline.Equal(retryAt) {
+				return reply{}, nil, client.timeoutError(ctx, attempt)
+			}
+			//No reply in time, so the request or reply was lost
+			continue
+		}
+		if err != nil {
+			return reply{}, from, err
+		}
+		if received.Error != nil {
+			return reply{}, from, newServerError(from, *received.Error)
+		}
+		return received, from, nil
+	}
+}
+
+//Reads until a reply to requestID arrives from the aserver, or the read deadline passes
+func (client *Client) readReply(conn *net.UDPConn, requestID uint64) (reply, *net.UDPAddr, error) {
+	for {
+		var buf [1024]byte
+		msgLen, from, err := conn.ReadFromUDP(buf[:])
+		if err != nil {
+			return reply{}, nil, fmt.Errorf("client: reading from aserver %v: %w", client.serverAddr, err)
+		}
+		if !client.isServer(from) {
+			continue
+		}
+		var received reply
+		if err := json.Unmarshal(buf[:msgLen], &received); err != nil {
+			//Corrupted or unrelated, so keep waiting for the real reply
+			client.config.ErrorLog.Printf("client: skipping unparsable datagram from %v %q: %v", from, buf[:msgLen], err)
+			continue
+		}
+		if received.RequestID != 0 && received.RequestID != requestID {
+			//Late reply to an earlier request
+			continue
+		}
+		return received, from, nil
+	}
+}
+
+//Returns true iff from is the aserver's address.  A ServerAddr without a host,
+//like ":port", sends to the local system, which replies from a loopback address.
+func (client *Client) isServer(from *net.UDPAddr) bool {
+	if from.Port != client.serverAddr.Port {
+		return false
+	}
+	if client.serverAddr.IP == nil || client.serverAddr.IP.IsUnspecified() {
+		return from.IP.IsLoopback()
+	}
+	return from.IP.Equal(client.serverAddr.IP)
+}
+
+//Returns how long to wait for a reply to the attempt'th transmission:
+//RetryInterval doubled for each earlier attempt, up to MaxRetryInterval, of
+//which up to half is random so that clients which lost packets at the same
+//time do not all retransmit at the same time
+func (client *Client) retryInterval(attempt int) time.Duration {
+	interval := client.config.RetryInterval
+	for i := 1; i < attempt && interval < client.config.MaxRetryInterval; i++ {
+		interval *= 2
+	}
+	interval = min(interval, client.config.MaxRetryInterval)
+	return interval - time.Duration(rand.Int63n(int64(interval/2)+1))
+}
+
+//Returns ctx.Err() if ctx was canceled, otherwise an error wrapping ErrTimeout
+func (client *Client) timeoutError(ctx context.Context, attempts int) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ctx.Err()
+	}
+	return fmt.Errorf("%w %v after %d attempts: %w", ErrTimeout, client.serverAddr, attempts, context.DeadlineExceeded)
+}
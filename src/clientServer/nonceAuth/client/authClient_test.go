@@ -0,0 +1,255 @@
+package client
+
+import (
+	"clientServer/nonceAuth/authServer"
+	"clientServer/nonceAuth/common"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"testing"
+	"time"
+)
+
+var testKey = []byte("123456")
+
+//Discards the errors logged by clients skipping unparsable replies
+var quietLog = log.New(io.Discard, "", 0)
+
+//Starts an aserver on a free port, shut down when the test ends
+func startTestServer(t *testing.T, minVersion int) net.Addr {
+	return startTestServerAt(t, "localhost:0", minVersion)
+}
+
+//Starts an aserver listening on listenAddr, shut down when the test ends
+func startTestServerAt(t *testing.T, listenAddr string, minVersion int) net.Addr {
+	server, err := authServer.New(authServer.Config{Addr: listenAddr, Key: testKey, MinVersion: minVersion})
+	if err != nil {
+		t.Fatalf("authServer.New() == %v", err)
+	}
+	addr, err := server.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() == %v", err)
+	}
+	t.Cleanup(func() {
+		server.Shutdown(context.Background())
+	})
+	return addr
+}
+
+//Starts a fake aserver on a free port which answers each message with
+//respond's reply, or not at all if respond returns nil
+func startFakeServer(t *testing.T, respond func(msg []byte) []byte) net.Addr {
+	return startFakeServerReplies(t, func(msg []byte, from *net.UDPAddr) [][]byte {
+		if reply := respond(msg); reply != nil {
+			return [][]byte{reply}
+		}
+		return nil
+	})
+}
+
+//Starts a fake aserver on a free port which answers each message from a
+//client with each of respond's replies in turn
+func startFakeServerReplies(t *testing.T, respond func(msg []byte, from *net.UDPAddr) [][]byte) net.Addr {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() == %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	go func() {
+		for {
+			var buf [1024]byte
+			msgLen, from, err := conn.ReadFromUDP(buf[:])
+			if err != nil {
+				return
+			}
+			for _, reply := range respond(buf[:msgLen], from) {
+				conn.WriteToUDP(reply, from)
+			}
+		}
+	}()
+	return conn.LocalAddr()
+}
+
+//Replies to nonce requests with nonceMsg and to hashes with hashReply
+func fakeExchange(nonceMsg interface{}, hashReply interface{}) func(msg []byte) []byte {
+	return func(msg []byte) []byte {
+		var hashMsg common.HashMessage
+		json.Unmarshal(msg, &hashMsg)
+		var reply interface{} = nonceMsg
+		if hashMsg.Hash != "" {
+			reply = hashReply
+		}
+		marshalled, _ := json.Marshal(reply)
+		return marshalled
+	}
+}
+
+func newTestClient(t *testing.T, config Config) *Client {
+	client, err := New(config)
+	if err != nil {
+		t.Fatalf("New(%+v) == %v", config, err)
+	}
+	return client
+}
+
+func verifyAuthenticateErr(t *testing.T, client *Client, ctx context.Context, expectedErrs ...error) error {
+	result, err := client.Authenticate(ctx)
+	for _, expectedErr := range expectedErrs {
+		if !errors.Is(err, expectedErr) {
+			t.Errorf("Authenticate() == %+v, %v, expected %v", result, err, expectedErr)
+		}
+	}
+	return err
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{ServerAddr: "localhost:1234"}); err == nil {
+		t.Errorf("New() with no key == nil error, expected an error")
+	}
+	if _, err := New(Config{ServerAddr: "localhost:1234", Key: testKey, MinVersion: common.LatestProtocol + 1}); err == nil {
+		t.Errorf("New() with MinVersion %d == nil error, expected an error", common.LatestProtocol+1)
+	}
+	if _, err := New(Config{ServerAddr: "localhost:65536", Key: testKey}); err == nil {
+		t.Errorf("New() with invalid port == nil error, expected an error")
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	testAuthenticate_Success(t)
+	testAuthenticate_ServerAddrWithoutHost(t)
+	testAuthenticate_NegotiatesVersion(t)
+	testAuthenticate_BadHash(t)
+	testAuthenticate_UnsupportedVersion(t)
+}
+
+func testAuthenticate_Success(t *testing.T) {
+	serverAddr := startTestServer(t, common.DefaultMinVersion)
+	client := newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey})
+	result, err := client.Authenticate(context.Background())
+	if err != nil {
+		t.Fatalf("Authenticate() == %v", err)
+	}
+	if result.Goal == "" || result.Version != common.ProtocolHMACSHA256 || result.Server.String() != serverAddr.String() {
+		t.Errorf("Authenticate() == %+v, expected a goal using version %d from %v", result, common.ProtocolHMACSHA256, serverAddr)
+	}
+}
+
+func testAuthenticate_ServerAddrWithoutHost(t *testing.T) {
+	serverAddr := startTestServerAt(t, ":0", common.DefaultMinVersion)
+	port := serverAddr.(*net.UDPAddr).Port
+	client := newTestClient(t, Config{ServerAddr: fmt.Sprintf(":%d", port), Key: testKey, Timeout: 2 * time.Second})
+	result, err := client.Authenticate(context.Background())
+	if err != nil || result.Goal == "" {
+		t.Errorf("Authenticate() == %+v, %v, expected a goal from the aserver on :%d", result, err, port)
+	}
+}
+
+func testAuthenticate_NegotiatesVersion(t *testing.T) {
+	//A legacy client with a decimal key falls back to MD5 only when allowed
+	serverAddr := startFakeServer(t, fakeExchange(common.NonceMessage{Nonce: 1}, common.GoalMessage{Goal: "goal"}))
+	client := newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey, MinVersion: common.ProtocolMD5})
+	result, err := client.Authenticate(context.Background())
+	if err != nil || result.Version != common.ProtocolMD5 || result.Goal != "goal" {
+		t.Errorf("Authenticate() == %+v, %v, expected goal using version %d", result, err, common.ProtocolMD5)
+	}
+
+	client = newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey})
+	verifyAuthenticateErr(t, client, context.Background(), common.ErrUnsupportedVersion)
+}
+
+func testAuthenticate_BadHash(t *testing.T) {
+	serverAddr := startTestServer(t, common.DefaultMinVersion)
+	client := newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: []byte("wrong key")})
+	err := verifyAuthenticateErr(t, client, context.Background(), ErrBadHash)
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.Server.String() != serverAddr.String() {
+		t.Errorf("Authenticate() == %v, expected a *ServerError from %v", err, serverAddr)
+	}
+}
+
+func testAuthenticate_UnsupportedVersion(t *testing.T) {
+	//The server rejects a version it offered, as one mid-upgrade might
+	serverAddr := startFakeServer(t, fakeExchange(
+		common.NonceMessage{Nonce: 1, Versions: []int{common.ProtocolHMACSHA256}},
+		common.ErrMessage{Error: "unsupported protocol version 2, server accepts versions [3]"}))
+	client := newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey})
+	verifyAuthenticateErr(t, client, context.Background(), common.ErrUnsupportedVersion)
+}
+
+func TestAuthenticate_ServerErrors(t *testing.T) {
+	nonceMsg := common.NonceMessage{Nonce: 1, Versions: []int{common.ProtocolHMACSHA256}}
+	tests := []struct {
+		message     string
+		expectedErr error
+	}{
+		{common.ErrMsgUnknownClient, ErrUnknownClient},
+		{common.ErrMsgExpiredNonce, ErrExpiredNonce},
+		{common.ErrMsgUnexpectedHash, ErrBadHash},
+	}
+	for _, test := range tests {
+		serverAddr := startFakeServer(t, fakeExchange(nonceMsg, common.ErrMessage{Error: test.message}))
+		client := newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey})
+		verifyAuthenticateErr(t, client, context.Background(), test.expectedErr)
+	}
+
+	//Unrecognised errors are still reported as a *ServerError
+	serverAddr := startFakeServer(t, fakeExchange(common.ErrMessage{Error: "Busy"}, nil))
+	client := newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey})
+	err := verifyAuthenticateErr(t, client, context.Background())
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.Message != "Busy" || serverErr.Err != nil {
+		t.Errorf("Authenticate() == %v, expected a *ServerError with message Busy", err)
+	}
+}
+
+func TestAuthenticate_BadReply(t *testing.T) {
+	//Well-formed JSON which is not a NonceMessage
+	serverAddr := startFakeServer(t, fakeExchange(common.GoalMessage{Goal: "goal"}, nil))
+	client := newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey})
+	verifyAuthenticateErr(t, client, context.Background(), ErrBadReply)
+
+	//Datagrams which are not JSON are skipped, so the client waits for a reply until the deadline
+	serverAddr = startFakeServer(t, func(msg []byte) []byte {
+		return []byte("not json")
+	})
+	client = newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey, Timeout: 200 * time.Millisecond, ErrorLog: quietLog})
+	verifyAuthenticateErr(t, client, context.Background(), ErrTimeout)
+}
+
+func TestAuthenticate_Timeout(t *testing.T) {
+	serverAddr := startFakeServer(t, func(msg []byte) []byte {
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey})
+	start := time.Now()
+	verifyAuthenticateErr(t, client, ctx, ErrTimeout, context.DeadlineExceeded)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Authenticate() took %v, expected to give up at the context deadline", elapsed)
+	}
+
+	//Config.Timeout applies without a context deadline
+	client = newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey, Timeout: 50 * time.Millisecond})
+	verifyAuthenticateErr(t, client, context.Background(), ErrTimeout)
+}
+
+func TestAuthenticate_Canceled(t *testing.T) {
+	serverAddr := startFakeServer(t, func(msg []byte) []byte {
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	client := newTestClient(t, Config{ServerAddr: serverAddr.String(), Key: testKey})
+	err := verifyAuthenticateErr(t, client, ctx, context.Canceled)
+	if errors.Is(err, ErrTimeout) {
+		t.Errorf("Authenticate() == %v, expected cancellation not to be reported as a timeout", err)
+	}
+}
@@ -3,83 +3,80 @@ Client
 
 Usage:
 1. Import the client package
-2. Call client.RunClient(clientIpPort string, aserverIpPort string, secret int64)
+2. Create a client with client.New(client.Config{ServerAddr: aserverIpPort, Key: key})
+3. Call c.Authenticate(ctx), which returns the aserver's goal and which aserver
+answered, or an error such as ErrUnknownClient, ErrBadHash or ErrTimeout
 */
 
 package client
 
 import (
 	"clientServer/nonceAuth/common"
-	"encoding/json"
+	"context"
 	"fmt"
+	"log"
 	"net"
 	"os"
-	"time"
 )
 
+//Client for the deprecated functions below, which bring their own connection
+func newLegacyClient(aserverUDPAddr *net.UDPAddr) *Client {
+	config := Config{RetryInterval: DefaultRetryInterval, MaxRetryInterval: DefaultMaxRetryInterval, ErrorLog: log.Default()}
+	return &Client{config: config, serverAddr: aserverUDPAddr}
+}
+
+//Retrieves a NonceMessage from aserver, exiting on any error.
+//
+//Deprecated: use Client.Authenticate, which returns errors.
 func RetrieveNonce(clientConn net.UDPConn, aserverUDPAddr *net.UDPAddr) common.NonceMessage {
-	//Send arbitrary UDP message to aserver to get nonce
-	nonceReq := []byte("Hello aserver!  I'd like a nonce!")
-	_, err := clientConn.WriteToUDP(nonceReq, aserverUDPAddr)
-	if err != nil {
-		fmt.Println("Error writing to aserver: ", err)
-		os.Exit(-1)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	requestID := newRequestID()
+	nonceReply, _, err := newLegacyClient(aserverUDPAddr).exchange(ctx, &clientConn, requestID, common.NonceRequest{RequestID: requestID})
+	if err == nil && nonceReply.Nonce == nil {
+		err = ErrBadReply
 	}
-
-	//Receive NonceMessage reply
-	var buf [1024]byte
-	msgLen, err := clientConn.Read(buf[:])
 	if err != nil {
-		fmt.Println("Error on read: ", err)
+		fmt.Println("Error retrieving nonce: ", err)
 		os.Exit(-1)
 	}
-	nonceReplyStr := string(buf[0:msgLen])
-	bufBytes := []byte(nonceReplyStr)
-
-	var nonce common.NonceMessage
-	json.Unmarshal(bufBytes, &nonce)
-	return nonce
+	return common.NonceMessage{Nonce: *nonceReply.Nonce, Versions: nonceReply.Versions}
 }
 
-//Retrieves GoalMessage from aserver
+//Retrieves GoalMessage from aserver, exiting on any error.
+//
+//Deprecated: use Client.Authenticate, which returns errors.
 func RetrieveGoalMsg(clientConn net.UDPConn, aserverUDPAddr *net.UDPAddr, hashMsg common.HashMessage) common.GoalMessage {
-	//Send HashMessage to aserver to get GoalMessage
-	req, err := json.Marshal(hashMsg)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	if hashMsg.RequestID == 0 {
+		hashMsg.RequestID = newRequestID()
+	}
+	goalReply, _, err := newLegacyClient(aserverUDPAddr).exchange(ctx, &clientConn, hashMsg.RequestID, hashMsg)
+	if err == nil && goalReply.Goal == nil {
+		err = ErrBadReply
+	}
 	if err != nil {
-		fmt.Println("Error marshalling hashMsg: ", err)
+		fmt.Println("Error retrieving goal message: ", err)
 		os.Exit(-1)
 	}
-	_, err = clientConn.WriteToUDP(req, aserverUDPAddr)
+	return common.GoalMessage{Goal: *goalReply.Goal}
+}
+
+//Authenticates with the aserver using the latest protocol version it offers,
+//refusing versions older than minVersion.  Exits on any error.
+//
+//Deprecated: use Client.Authenticate, which returns errors.
+func RunClient(clientIpPort string, aserverIpPort string, key []byte, minVersion int) {
+	client, err := New(Config{LocalAddr: clientIpPort, ServerAddr: aserverIpPort, Key: key, MinVersion: minVersion})
 	if err != nil {
-		fmt.Println("Error writing hashMsg to aserver: ", err)
+		fmt.Println("Error creating client: ", err)
 		os.Exit(-1)
 	}
-
-	//Receive GoalMessage reply
-	var buf [1024]byte
-	msgLen, err := clientConn.Read(buf[:])
+	result, err := client.Authenticate(context.Background())
 	if err != nil {
-		fmt.Println("Error on read: ", err)
+		fmt.Println("Error authenticating: ", err)
 		os.Exit(-1)
 	}
-	var goalMsg common.GoalMessage
-	json.Unmarshal(buf[0:msgLen], &goalMsg)
-	return goalMsg
-}
-
-func RunClient(clientIpPort string, aserverIpPort string, secret int64) {
-	clientConn := common.InitUDPConn(clientIpPort)
-	aserverUDPAddr := common.ResolveUDPAddr(aserverIpPort)
-
-	//Set 10 second timeout for ReadFromUDP
-	clientConn.SetReadDeadline(time.Now().Add(10 * time.Second))
-
-	//Retrieve nonce from aserver and compute MD5(nonce + secret)
-	nonceMsg := RetrieveNonce(clientConn, &aserverUDPAddr)
-
-	hashMsg := common.ComputeHashMessage(nonceMsg.Nonce, secret)
-
-	//Retrieve GoalMessage from aserver
-	goalMsg := RetrieveGoalMsg(clientConn, &aserverUDPAddr, hashMsg)
-	fmt.Println("Received goal message from aserver: ", goalMsg)
+	fmt.Println("Received goal message from aserver: ", common.GoalMessage{Goal: result.Goal})
 }
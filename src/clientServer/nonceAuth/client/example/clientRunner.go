@@ -2,7 +2,10 @@
 Example client runner
 
 Usage:
-$ go run clientRunner.go [local UDP ip:port] [aserver UDP ip:port] [secret]
+$ go run clientRunner.go [local UDP ip:port] [aserver UDP ip:port] [secret] [min protocol version]
+
+The min protocol version defaults to common.DefaultMinVersion.  Pass 1 to
+fall back to MD5 for servers not yet upgraded.
 */
 
 package main
@@ -10,19 +13,37 @@ package main
 import (
 	"clientServer/nonceAuth/client"
 	"clientServer/nonceAuth/common"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 )
 
 func main() {
 	args := os.Args //os.Args[0] is program name
-	if len(args) != 4 {
-		fmt.Println("Usage: clientRunner [local UDP ip:port] [aserver UDP ip:port] [secret]")
+	if len(args) != 4 && len(args) != 5 {
+		fmt.Println("Usage: clientRunner [local UDP ip:port] [aserver UDP ip:port] [secret] [min protocol version]")
 		os.Exit(-1)
 	}
 	clientIpPort := args[1]
 	aserverIpPort := args[2]
-	secret := common.ParseIntFromStr(args[3])
+	key := []byte(args[3])
+	minVersion := common.DefaultMinVersion
+	if len(args) == 5 {
+		minVersion = int(common.ParseIntFromStr(args[4]))
+	}
 
-	client.RunClient(clientIpPort, aserverIpPort, secret)
+	authClient, err := client.New(client.Config{LocalAddr: clientIpPort, ServerAddr: aserverIpPort, Key: key, MinVersion: minVersion})
+	if err != nil {
+		fmt.Println("Error creating client: ", err)
+		os.Exit(-1)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := authClient.Authenticate(ctx)
+	if err != nil {
+		fmt.Println("Error authenticating: ", err)
+		os.Exit(-1)
+	}
+	fmt.Printf("Received goal message from aserver %v using protocol version %d: %s\n", result.Server, result.Version, result.Goal)
 }
@@ -0,0 +1,270 @@
+package client
+
+import (
+	"clientServer/nonceAuth/common"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+//UDP proxy between clients and an aserver which drops, and optionally
+//duplicates, datagrams.  Each client is given its own connection to the
+//aserver, so the aserver sees one address per client.
+type lossyProxy struct {
+	conn       *net.UDPConn
+	serverAddr *net.UDPAddr
+	//Whether to drop the nth datagram to the aserver or to a client, counting from 0
+	dropToServer func(n int) bool
+	dropToClient func(n int) bool
+	//Sends each datagram to the aserver twice
+	duplicate bool
+
+	mutex       sync.Mutex
+	upstreams   map[string]*net.UDPConn
+	toServer    int
+	toClient    int
+	dropped     int
+	transmitted int
+}
+
+//Starts a proxy to serverAddr, closed when the test ends.  Returns the address clients should send to.
+func startLossyProxy(t *testing.T, serverAddr net.Addr, proxy *lossyProxy) net.Addr {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() == %v", err)
+	}
+	proxy.conn = conn
+	proxy.serverAddr = serverAddr.(*net.UDPAddr)
+	proxy.upstreams = map[string]*net.UDPConn{}
+	t.Cleanup(proxy.close)
+	go proxy.forwardToServer()
+	return conn.LocalAddr()
+}
+
+func (proxy *lossyProxy) close() {
+	proxy.mutex.Lock()
+	defer proxy.mutex.Unlock()
+	proxy.conn.Close()
+	for _, upstream := range proxy.upstreams {
+		upstream.Close()
+	}
+}
+
+//Returns whether to drop the next datagram in a direction, counting it
+func (proxy *lossyProxy) drop(toServer bool) bool {
+	proxy.mutex.Lock()
+	defer proxy.mutex.Unlock()
+	var dropped bool
+	if toServer {
+		dropped = proxy.dropToServer != nil && proxy.dropToServer(proxy.toServer)
+		proxy.toServer++
+	} else {
+		dropped = proxy.dropToClient != nil && proxy.dropToClient(proxy.toClient)
+		proxy.toClient++
+	}
+	if dropped {
+		proxy.dropped++
+	} else {
+		proxy.transmitted++
+	}
+	return dropped
+}
+
+//Returns the connection to the aserver for client, dialing it the first time
+func (proxy *lossyProxy) upstream(client *net.UDPAddr) (*net.UDPConn, error) {
+	proxy.mutex.Lock()
+	defer proxy.mutex.Unlock()
+	if upstream, ok := proxy.upstreams[client.String()]; ok {
+		return upstream, nil
+	}
+	upstream, err := net.DialUDP("udp", nil, proxy.serverAddr)
+	if err != nil {
+		return nil, err
+	}
+	proxy.upstreams[client.String()] = upstream
+	go proxy.forwardToClient(upstream, client)
+	return upstream, nil
+}
+
+func (proxy *lossyProxy) forwardToServer() {
+	for {
+		var buf [1024]byte
+		msgLen, client, err := proxy.conn.ReadFromUDP(buf[:])
+		if err != nil {
+			return
+		}
+		upstream, err := proxy.upstream(client)
+		if err != nil {
+			return
+		}
+		if proxy.drop(true) {
+			continue
+		}
+		upstream.Write(buf[:msgLen])
+		if proxy.duplicate {
+			upstream.Write(buf[:msgLen])
+		}
+	}
+}
+
+func (proxy *lossyProxy) forwardToClient(upstream *net.UDPConn, client *net.UDPAddr) {
+	for {
+		var buf [1024]byte
+		msgLen, err := upstream.Read(buf[:])
+		if err != nil {
+			return
+		}
+		if !proxy.drop(false) {
+			proxy.conn.WriteToUDP(buf[:msgLen], client)
+		}
+	}
+}
+
+func (proxy *lossyProxy) stats() (dropped int, transmitted int) {
+	proxy.mutex.Lock()
+	defer proxy.mutex.Unlock()
+	return proxy.dropped, proxy.transmitted
+}
+
+//Drops datagrams with probability p, from a fixed seed so failures can be reproduced
+func dropRandomly(seed int64, p float64) func(n int) bool {
+	random := rand.New(rand.NewSource(seed))
+	return func(n int) bool {
+		return random.Float64() < p
+	}
+}
+
+func newRetryingClient(t *testing.T, serverAddr net.Addr) *Client {
+	return newTestClient(t, Config{
+		ServerAddr:       serverAddr.String(),
+		Key:              testKey,
+		Timeout:          10 * time.Second,
+		RetryInterval:    20 * time.Millisecond,
+		MaxRetryInterval: 100 * time.Millisecond,
+		ErrorLog:         quietLog,
+	})
+}
+
+func verifyAuthenticates(t *testing.T, client *Client) {
+	result, err := client.Authenticate(context.Background())
+	if err != nil || result.Goal == "" {
+		t.Errorf("Authenticate() == %+v, %v, expected a goal", result, err)
+	}
+}
+
+func TestAuthenticateOverLossyNetwork(t *testing.T) {
+	testAuthenticateOverLossyNetwork_LostRequests(t)
+	testAuthenticateOverLossyNetwork_LostReplies(t)
+	testAuthenticateOverLossyNetwork_Duplicates(t)
+	testAuthenticateOverLossyNetwork_RandomLoss(t)
+}
+
+func testAuthenticateOverLossyNetwork_LostRequests(t *testing.T) {
+	//Only every third datagram reaches the aserver
+	proxy := &lossyProxy{dropToServer: func(n int) bool { return n%3 != 2 }}
+	proxyAddr := startLossyProxy(t, startTestServer(t, common.DefaultMinVersion), proxy)
+	verifyAuthenticates(t, newRetryingClient(t, proxyAddr))
+	if dropped, _ := proxy.stats(); dropped < 4 {
+		t.Errorf("proxy dropped %d datagrams, expected at least 4", dropped)
+	}
+}
+
+func testAuthenticateOverLossyNetwork_LostReplies(t *testing.T) {
+	//The aserver handles every request but its first two replies to each are
+	//lost, so it must answer retransmitted hashes although the nonce is taken
+	proxy := &lossyProxy{dropToClient: func(n int) bool { return n%3 != 2 }}
+	proxyAddr := startLossyProxy(t, startTestServer(t, common.DefaultMinVersion), proxy)
+	verifyAuthenticates(t, newRetryingClient(t, proxyAddr))
+	if dropped, _ := proxy.stats(); dropped < 4 {
+		t.Errorf("proxy dropped %d datagrams, expected at least 4", dropped)
+	}
+}
+
+func testAuthenticateOverLossyNetwork_Duplicates(t *testing.T) {
+	proxy := &lossyProxy{duplicate: true}
+	proxyAddr := startLossyProxy(t, startTestServer(t, common.DefaultMinVersion), proxy)
+	client := newRetryingClient(t, proxyAddr)
+	for i := 0; i < 5; i++ {
+		verifyAuthenticates(t, client)
+	}
+}
+
+func testAuthenticateOverLossyNetwork_RandomLoss(t *testing.T) {
+	proxy := &lossyProxy{dropToServer: dropRandomly(1, 0.3), dropToClient: dropRandomly(2, 0.3), duplicate: true}
+	proxyAddr := startLossyProxy(t, startTestServer(t, common.DefaultMinVersion), proxy)
+	client := newRetryingClient(t, proxyAddr)
+	for i := 0; i < 20; i++ {
+		verifyAuthenticates(t, client)
+	}
+	if dropped, _ := proxy.stats(); dropped == 0 {
+		t.Errorf("proxy dropped no datagrams, expected some to be lost")
+	}
+}
+
+func TestAuthenticate_GivesUpWhenAllLost(t *testing.T) {
+	proxy := &lossyProxy{dropToServer: func(n int) bool { return true }}
+	proxyAddr := startLossyProxy(t, startTestServer(t, common.DefaultMinVersion), proxy)
+	client := newTestClient(t, Config{
+		ServerAddr:       proxyAddr.String(),
+		Key:              testKey,
+		Timeout:          200 * time.Millisecond,
+		RetryInterval:    20 * time.Millisecond,
+		MaxRetryInterval: 40 * time.Millisecond,
+	})
+	verifyAuthenticateErr(t, client, context.Background(), ErrTimeout)
+	//Retransmissions back off, but do not stop before the deadline
+	if dropped, _ := proxy.stats(); dropped < 3 {
+		t.Errorf("proxy dropped %d datagrams, expected at least 3 attempts", dropped)
+	}
+}
+
+//Answers nonce requests with nonce 1 and hashes with a goal, echoing RequestIDs
+func fakeReply(msg []byte) (request common.HashMessage, reply []byte) {
+	json.Unmarshal(msg, &request)
+	if request.Hash == "" {
+		reply, _ = json.Marshal(common.NonceMessage{Nonce: 1, Versions: []int{common.ProtocolHMACSHA256}, RequestID: request.RequestID})
+	} else {
+		reply, _ = json.Marshal(common.GoalMessage{Goal: "goal", RequestID: request.RequestID})
+	}
+	return request, reply
+}
+
+func TestAuthenticate_IgnoresOtherSenders(t *testing.T) {
+	spoofer, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() == %v", err)
+	}
+	defer spoofer.Close()
+	//Before each reply, another sender gets an error to the client first
+	serverAddr := startFakeServerReplies(t, func(msg []byte, from *net.UDPAddr) [][]byte {
+		request, reply := fakeReply(msg)
+		spoof, _ := json.Marshal(common.ErrMessage{Error: common.ErrMsgUnexpectedHash, RequestID: request.RequestID})
+		spoofer.WriteToUDP(spoof, from)
+		time.Sleep(5 * time.Millisecond)
+		return [][]byte{reply}
+	})
+	verifyAuthenticates(t, newRetryingClient(t, serverAddr))
+}
+
+func TestAuthenticate_IgnoresRepliesToOtherRequests(t *testing.T) {
+	//Before each reply, the aserver sends a late reply to another request
+	serverAddr := startFakeServerReplies(t, func(msg []byte, from *net.UDPAddr) [][]byte {
+		request, reply := fakeReply(msg)
+		stale, _ := json.Marshal(common.ErrMessage{Error: common.ErrMsgUnknownClient, RequestID: request.RequestID + 1})
+		return [][]byte{stale, reply}
+	})
+	verifyAuthenticates(t, newRetryingClient(t, serverAddr))
+}
+
+func TestAuthenticate_SkipsUnparsableReplies(t *testing.T) {
+	//Before each reply, the aserver sends a datagram which is not JSON
+	serverAddr := startFakeServerReplies(t, func(msg []byte, from *net.UDPAddr) [][]byte {
+		_, reply := fakeReply(msg)
+		return [][]byte{[]byte("not json"), reply[:len(reply)/2], reply}
+	})
+	verifyAuthenticates(t, newRetryingClient(t, serverAddr))
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestComputeHMACMessage(t *testing.T) {
+	//Expected hashes computed independently with Python's hmac module
+	cases := []struct {
+		nonce    int64
+		key      string
+		expected string
+	}{
+		{123456789, "secret", "57837b7986cb1d71471079f752ede80555867f42bd84e9478987293065d953c8"},
+		{-1, "", "408ac12673ac14b4acff15c51497df11906b1eacc13c5685bbd09cb502ddc901"},
+	}
+	for _, c := range cases {
+		hashMsg := ComputeHMACMessage(c.nonce, []byte(c.key))
+		if hashMsg.Hash != c.expected || hashMsg.Version != ProtocolHMACSHA256 {
+			t.Errorf("ComputeHMACMessage(%d, %q) == %v, expected {%s %d}", c.nonce, c.key, hashMsg, c.expected, ProtocolHMACSHA256)
+		}
+	}
+}
+
+//nonce + secret overflowed, so different nonces could hash the same under ProtocolMD5
+func TestComputeHMACMessage_NoOverflowCollision(t *testing.T) {
+	key := []byte("1")
+	if ComputeHashMessage(-1<<63, 1) != ComputeHashMessage(0, -1<<63+1) {
+		t.Fatalf("expected ProtocolMD5 hashes to collide")
+	}
+	if ComputeHMACMessage(-1<<63, key) == ComputeHMACMessage(0, key) {
+		t.Errorf("ComputeHMACMessage collides for different nonces")
+	}
+}
+
+func verifyVerifyErr(t *testing.T, received HashMessage, minVersion int, expectedErr error) {
+	err := VerifyHashMessage(received, 42, []byte("2016"), minVersion)
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("VerifyHashMessage(%v, minVersion %d) == %v, expected %v", received, minVersion, err, expectedErr)
+	}
+}
+
+func TestVerifyHashMessage(t *testing.T) {
+	hmacMsg := ComputeHMACMessage(42, []byte("2016"))
+	legacyMsg := ComputeHashMessage(42, 2016)
+	verifyVerifyErr(t, hmacMsg, DefaultMinVersion, nil)
+	verifyVerifyErr(t, hmacMsg, ProtocolMD5, nil)
+	verifyVerifyErr(t, legacyMsg, ProtocolMD5, nil)
+	verifyVerifyErr(t, legacyMsg, DefaultMinVersion, ErrUnsupportedVersion)
+	verifyVerifyErr(t, HashMessage{Hash: hmacMsg.Hash, Version: LatestProtocol + 1}, ProtocolMD5, ErrUnsupportedVersion)
+	verifyVerifyErr(t, ComputeHMACMessage(43, []byte("2016")), DefaultMinVersion, ErrUnexpectedHash)
+	verifyVerifyErr(t, ComputeHMACMessage(42, []byte("2017")), DefaultMinVersion, ErrUnexpectedHash)
+	//A legacy hash relabelled with a newer version is checked as that version
+	verifyVerifyErr(t, HashMessage{Hash: legacyMsg.Hash, Version: ProtocolHMACSHA256}, ProtocolMD5, ErrUnexpectedHash)
+
+	err := VerifyHashMessage(legacyMsg, 42, []byte("not an integer"), ProtocolMD5)
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("VerifyHashMessage with a non-integer ProtocolMD5 secret == %v, expected %v", err, ErrUnsupportedVersion)
+	}
+}
+
+func TestChooseVersion(t *testing.T) {
+	cases := []struct {
+		offered    []int
+		minVersion int
+		expected   int
+	}{
+		{[]int{1, 2}, ProtocolMD5, ProtocolHMACSHA256},
+		{[]int{2}, DefaultMinVersion, ProtocolHMACSHA256},
+		{[]int{2, 3}, DefaultMinVersion, ProtocolHMACSHA256},
+		//Servers predating versioning offer nothing, and only know ProtocolMD5
+		{nil, ProtocolMD5, ProtocolMD5},
+		{nil, DefaultMinVersion, 0},
+		{[]int{1}, DefaultMinVersion, 0},
+		{[]int{3}, ProtocolMD5, 0},
+	}
+	for _, c := range cases {
+		version, err := ChooseVersion(c.offered, c.minVersion)
+		if version != c.expected || (c.expected == 0) != errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("ChooseVersion(%v, %d) == %d, %v, expected %d", c.offered, c.minVersion, version, err, c.expected)
+		}
+	}
+}
+
+func TestSupportedVersions(t *testing.T) {
+	if versions := SupportedVersions(ProtocolMD5); len(versions) != 2 || versions[0] != ProtocolMD5 || versions[1] != ProtocolHMACSHA256 {
+		t.Errorf("SupportedVersions(ProtocolMD5) == %v", versions)
+	}
+	if versions := SupportedVersions(DefaultMinVersion); len(versions) != 1 || versions[0] != ProtocolHMACSHA256 {
+		t.Errorf("SupportedVersions(DefaultMinVersion) == %v", versions)
+	}
+}
@@ -4,15 +4,20 @@ import (
 	aserver "clientServer/nonceAuth/authServer"
 	client "clientServer/nonceAuth/client"
 	"clientServer/nonceAuth/common"
+	"context"
 	"fmt"
-	"time"
+	"os"
 )
 
-func startAserver(aserverIpPort string, secret int64) {
-	go aserver.RunAuthServer(aserverIpPort, secret)
-
-	//Give time for aserver to set up
-	time.Sleep(time.Millisecond * 1500)
+func startAserver(aserverIpPort string, key []byte) {
+	server, err := aserver.New(aserver.Config{Addr: aserverIpPort, Key: key})
+	if err == nil {
+		_, err = server.Start(context.Background())
+	}
+	if err != nil {
+		fmt.Println("FAIL, could not start aserver: ", err)
+		os.Exit(-1)
+	}
 }
 
 func getNonceFromAserver(clientIpPort string, aserverIpPort string) int64 {
@@ -27,9 +32,9 @@ func testAssignsNewNonceForNewAddr() {
 	firstClientAddr := "localhost:56146"
 	secondClientAddr := "localhost:56147"
 	aserverAddr := "localhost:56149"
-	var secret int64 = 123456
+	key := []byte("123456")
 
-	startAserver(aserverAddr, secret)
+	startAserver(aserverAddr, key)
 
 	firstNonce := getNonceFromAserver(firstClientAddr, aserverAddr)
 	secondNonce := getNonceFromAserver(secondClientAddr, aserverAddr)
@@ -43,9 +48,9 @@ func testAssignsNewNonceForNewAddr() {
 func testAssignsNewNonceForSameAddr() {
 	clientAddr := "localhost:56146"
 	aserverAddr := "localhost:56147"
-	var secret int64 = 123456
+	key := []byte("123456")
 
-	startAserver(aserverAddr, secret)
+	startAserver(aserverAddr, key)
 
 	nonceFromFirstConn := getNonceFromAserver(clientAddr, aserverAddr)
 	nonceFromSubsequentConn := getNonceFromAserver(clientAddr, aserverAddr)
@@ -1,247 +1,484 @@
 package avlTree
 
 import (
+	"cmp"
 	"fmt"
+	"os"
+	"reflect"
 )
 
-type AvlNode struct {
-	data     string
-	priority int
+//Element stored in an AvlTree, ordered by Key
+type AvlNode[K, V any] struct {
+	Key   K
+	Value V
 }
 
-func (node *AvlNode) compare(other *AvlNode) int {
-	if node == other {
-		return 0
-	}
-	if other == nil || node.priority > other.priority {
+//Creates a node holding key and value
+func NewAvlNode[K, V any](key K, value V) *AvlNode[K, V] {
+	return &AvlNode[K, V]{key, value}
+}
+
+//Key ordering nodes by priority, then by data
+type PriorityKey struct {
+	Data     string
+	Priority int
+}
+
+//Returns a positive value if key > other, a negative value if key < other, or 0 if equal
+func (key PriorityKey) Compare(other PriorityKey) int {
+	if key.Priority > other.Priority {
 		return 1
 	}
-	if node.priority < other.priority {
+	if key.Priority < other.Priority {
 		return -1
 	}
-	if node.data > other.data {
+	if key.Data > other.Data {
 		return 1
 	}
-	if node.data < other.data {
+	if key.Data < other.Data {
 		return -1
 	}
 	return 0
 }
 
-type AvlTree struct {
-	root   *AvlNode
-	height int
-	left   *AvlTree
-	right  *AvlTree
+//Formats key as "data (priority)", as shown by WriteDOT and WriteASCII
+func (key PriorityKey) String() string {
+	return fmt.Sprintf("%s (%d)", key.Data, key.Priority)
 }
 
-//Creates an empty AVL tree
-func NewAvlTree() *AvlTree {
-	var tree AvlTree
+//Comparator ordering PriorityKeys by priority, then by data
+func ComparePriorityKeys(first PriorityKey, second PriorityKey) int {
+	return first.Compare(second)
+}
+
+//Each subtree keeps a copy of the comparator so that children can be created
+//and compared without access to the enclosing tree.  Augmented trees also keep
+//an augment func, which recomputes summary from the root and its children's
+//summaries whenever the subtree's height and size are updated.  Observed trees
+//share one TreeObserver between all their subtrees.
+type AvlTree[K, V any] struct {
+	root     *AvlNode[K, V]
+	height   int
+	size     int
+	left     *AvlTree[K, V]
+	right    *AvlTree[K, V]
+	compare  func(K, K) int
+	augment  func(*AvlTree[K, V])
+	summary  *AvlNode[K, V]
+	observer *TreeObserver[K, V]
+}
+
+//Creates an empty AVL tree ordered by the natural ordering of K
+func NewAvlTree[K cmp.Ordered, V any]() *AvlTree[K, V] {
+	return NewAvlTreeFunc[K, V](cmp.Compare[K])
+}
+
+//Creates an empty AVL tree ordered by compare, which returns a negative value
+//if a < b, a positive value if a > b and 0 if a and b are equivalent
+func NewAvlTreeFunc[K, V any](compare func(a, b K) int) *AvlTree[K, V] {
+	var tree AvlTree[K, V]
 	tree.height = -1
+	tree.compare = compare
 	return &tree
 }
 
-//Inserts node into *ptree and rebalances the tree
-func Insert(ptree **AvlTree, node *AvlNode) {
+//Returns a comparator for keys which have a Compare(K) int method, like
+//PriorityKey, or a natural ordering, or nil if K has neither
+func defaultCompare[K any]() func(a, b K) int {
+	keyType := reflect.TypeFor[K]()
+	if keyType.Kind() != reflect.Interface {
+		//The method expression K.Compare, called without boxing each key
+		if method, ok := keyType.MethodByName("Compare"); ok {
+			if compare, ok := method.Func.Interface().(func(K, K) int); ok {
+				return compare
+			}
+		}
+	}
+	type comparer interface{ Compare(K) int }
+	var zero K
+	if _, ok := any(zero).(comparer); ok {
+		return func(a, b K) int {
+			return any(a).(comparer).Compare(b)
+		}
+	}
+	switch any(zero).(type) {
+	case int:
+		return any(cmp.Compare[int]).(func(K, K) int)
+	case int64:
+		return any(cmp.Compare[int64]).(func(K, K) int)
+	case float64:
+		return any(cmp.Compare[float64]).(func(K, K) int)
+	case string:
+		return any(cmp.Compare[string]).(func(K, K) int)
+	}
+	//Other types, including named types, ordered by their underlying kind
+	switch keyType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(a, b K) int {
+			return cmp.Compare(reflect.ValueOf(a).Int(), reflect.ValueOf(b).Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(a, b K) int {
+			return cmp.Compare(reflect.ValueOf(a).Uint(), reflect.ValueOf(b).Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(a, b K) int {
+			return cmp.Compare(reflect.ValueOf(a).Float(), reflect.ValueOf(b).Float())
+		}
+	case reflect.String:
+		return func(a, b K) int {
+			return cmp.Compare(reflect.ValueOf(a).String(), reflect.ValueOf(b).String())
+		}
+	}
+	return nil
+}
+
+//Returns the tree's comparator.  A zero-value AvlTree has none until its first
+//Insert, so until then takes the default ordering of K, or nil if K has none.
+//The default is not stored, so that reads stay safe for concurrent use.
+func (tree *AvlTree[K, V]) resolveComparator() func(K, K) int {
+	if tree.compare == nil {
+		return defaultCompare[K]()
+	}
+	return tree.compare
+}
+
+//Stores the default ordering of K as the comparator of a zero-value tree.
+//Only called by operations which modify the tree.
+func (tree *AvlTree[K, V]) initComparator() {
+	if tree.compare == nil {
+		tree.compare = defaultCompare[K]()
+	}
+}
+
+//Returns the tree's comparator as resolveComparator, panicking if there is none
+func (tree *AvlTree[K, V]) comparator() func(K, K) int {
+	compare := tree.resolveComparator()
+	if compare == nil {
+		var key K
+		panic(fmt.Sprintf("avlTree: zero-value AvlTree with %T keys has no comparator, create it with NewAvlTreeFunc", key))
+	}
+	return compare
+}
+
+//Returns a positive value if node's key > other's key, a negative value if
+//node's key < other's key, or 0 if equal.  A nil other sorts below every node.
+func (tree *AvlTree[K, V]) compareNodes(node *AvlNode[K, V], other *AvlNode[K, V]) int {
+	if node == other {
+		return 0
+	}
+	if other == nil {
+		return 1
+	}
+	return tree.comparator()(node.Key, other.Key)
+}
+
+//Inserts node into *ptree, rebalancing only the subtrees on the path to the new leaf.
+//If *ptree is nil, a new tree is created ordered by K's Compare method or
+//natural ordering.  Zero-value trees are ordered the same way.
+func Insert[K, V any](ptree **AvlTree[K, V], node *AvlNode[K, V]) {
 	if ptree == nil || node == nil {
 		return
 	}
-	tree := getTreePtrForInsert(ptree)
-	if tree.root == nil {
-		tree.root = node
-		tree.height = 0
-	} else {
-		insertInChild(tree, node)
-		tree.updateHeight()
-		balance(&tree)
+	if *ptree == nil {
+		*ptree = NewAvlTreeFunc[K, V](defaultCompare[K]())
 	}
-	*ptree = tree
+	(*ptree).initComparator()
+	observer := (*ptree).observer
+	insertNode(ptree, node)
+	observer.inserted(node)
 }
 
-//Removes node from *ptree and rebalances the tree
-func Remove(ptree **AvlTree, node *AvlNode) {
+//Removes node from *ptree, rebalancing only the subtrees on the path to the removed node
+func Remove[K, V any](ptree **AvlTree[K, V], node *AvlNode[K, V]) {
 	if ptree == nil || (*ptree).isEmpty() || node == nil {
 		return
 	}
-	tree := *ptree
-	rootToNodeComparison := tree.root.compare(node)
-	if rootToNodeComparison == 0 {
-		if tree.left != nil {
-			replaceWithLeftMax(tree)
-		} else if tree.right != nil {
-			tree = tree.right
-		} else {
-			removeLastNode(tree)
-		}
-	} else {
-		removeFromChild(tree, node, rootToNodeComparison)
-		tree.updateHeight()
+	observer := (*ptree).observer
+	if removed := removeNode(ptree, node); removed != nil {
+		observer.removed(removed)
 	}
-	balance(&tree)
-	*ptree = tree
 }
 
-func RemoveMax(ptree **AvlTree) {
-	if ptree == nil || *ptree == nil {
+//Removes the max node from *ptree, rebalancing only the subtrees on the tree's right spine
+func RemoveMax[K, V any](ptree **AvlTree[K, V]) {
+	if ptree == nil || (*ptree).isEmpty() {
 		return
 	}
+	observer := (*ptree).observer
+	observer.removed(removeMaxNode(ptree))
+}
+
+//Insert without notifying the tree's observer
+func insertNode[K, V any](ptree **AvlTree[K, V], node *AvlNode[K, V]) {
 	tree := *ptree
-	if tree.right != nil {
-		removeMaxFromSubtree(&tree.right)
+	if tree.root == nil {
+		tree.root = node
 		tree.updateHeight()
-		balance(&tree)
-	} else if tree.left != nil {
-		replaceWithLeftMax(tree)
+		return
+	}
+	path := []**AvlTree[K, V]{}
+	slot := ptree
+	for *slot != nil {
+		path = append(path, slot)
+		subtree := *slot
+		if subtree.compareNodes(subtree.root, node) >= 0 {
+			slot = &subtree.left
+		} else {
+			slot = &subtree.right
+		}
+	}
+	leaf := newSubtreeLike(tree)
+	leaf.root = node
+	leaf.updateHeight()
+	*slot = leaf
+	rebalancePath(path)
+}
+
+//Remove without notifying the tree's observer, returns the removed node or nil if there is none
+func removeNode[K, V any](ptree **AvlTree[K, V], node *AvlNode[K, V]) *AvlNode[K, V] {
+	path := []**AvlTree[K, V]{}
+	slot := ptree
+	for {
+		subtree := *slot
+		if subtree == nil {
+			return nil
+		}
+		rootToNodeComparison := subtree.compareNodes(subtree.root, node)
+		if rootToNodeComparison == 0 {
+			break
+		}
+		path = append(path, slot)
+		if rootToNodeComparison > 0 {
+			slot = &subtree.left
+		} else {
+			slot = &subtree.right
+		}
+	}
+	return unlinkSlot(ptree, path, slot)
+}
+
+//Remove of the node at index in ascending order without notifying the tree's
+//observer, returns the removed node.  index must be less than the tree's size.
+func removeNodeAt[K, V any](ptree **AvlTree[K, V], index int) *AvlNode[K, V] {
+	path := []**AvlTree[K, V]{}
+	slot := ptree
+	for {
+		subtree := *slot
+		leftSize := subtree.left.getSize()
+		if index == leftSize {
+			break
+		}
+		path = append(path, slot)
+		if index < leftSize {
+			slot = &subtree.left
+		} else {
+			index -= leftSize + 1
+			slot = &subtree.right
+		}
+	}
+	return unlinkSlot(ptree, path, slot)
+}
+
+//Unlinks the root of the subtree in slot, reached from *ptree along path, and
+//rebalances the path.  Returns the unlinked node.
+func unlinkSlot[K, V any](ptree **AvlTree[K, V], path []**AvlTree[K, V], slot **AvlTree[K, V]) *AvlNode[K, V] {
+	target := *slot
+	removed := target.root
+	if target.left != nil {
+		path = append(path, slot)
+		path, target.root = unlinkMax(path, &target.left)
+	} else if target.right != nil || slot != ptree {
+		*slot = target.right
 	} else {
+		removeLastNode(target)
+	}
+	rebalancePath(path)
+	return removed
+}
+
+//RemoveMax of a non-empty tree without notifying the tree's observer, returns the removed node
+func removeMaxNode[K, V any](ptree **AvlTree[K, V]) *AvlNode[K, V] {
+	tree := *ptree
+	if tree.left == nil && tree.right == nil {
+		removed := tree.root
 		removeLastNode(tree)
+		return removed
 	}
-	*ptree = tree
+	path, removed := unlinkMax(nil, ptree)
+	rebalancePath(path)
+	return removed
 }
 
 //Returns max element in AVL tree
-func Max(tree *AvlTree) *AvlNode {
-	if tree == nil || tree.root == nil {
+func Max[K, V any](tree *AvlTree[K, V]) *AvlNode[K, V] {
+	if tree.isEmpty() {
 		return nil
 	}
-	if tree.right == nil {
-		return tree.root
+	for tree.right != nil {
+		tree = tree.right
 	}
-	return Max(tree.right)
+	return tree.root
 }
 
 //Returns min element in AVL tree
-func Min(tree *AvlTree) *AvlNode {
-	if tree == nil || tree.root == nil {
+func Min[K, V any](tree *AvlTree[K, V]) *AvlNode[K, V] {
+	if tree.isEmpty() {
 		return nil
 	}
-	if tree.left == nil {
-		return tree.root
+	for tree.left != nil {
+		tree = tree.left
 	}
-	return Min(tree.left)
+	return tree.root
 }
 
-//Returns true iff tree contains node
-func Has(tree *AvlTree, node *AvlNode) bool {
+//Returns true iff tree contains a node with the same key as node
+func Has[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V]) bool {
 	return findSubtreeWithNodeAsRoot(tree, node) != nil
 }
 
-//Replaces specified node in tree with newNode
-func UpdateNode(tree *AvlTree, node *AvlNode, newNode *AvlNode) {
-	Remove(&tree, node)
-	Insert(&tree, newNode)
+//Returns the node with the greatest key <= node's key, or nil if there is none
+func Floor[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V]) *AvlNode[K, V] {
+	return findNearestBelow(tree, node, true)
 }
 
-func findSubtreeWithNodeAsRoot(tree *AvlTree, node *AvlNode) *AvlTree {
-	if tree != nil && tree.root != nil && node != nil {
-		rootToNodeCompare := tree.root.compare(node)
+//Returns the node with the least key >= node's key, or nil if there is none
+func Ceiling[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V]) *AvlNode[K, V] {
+	return findNearestAbove(tree, node, true)
+}
+
+//Returns the node with the greatest key < node's key, or nil if there is none
+func Predecessor[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V]) *AvlNode[K, V] {
+	return findNearestBelow(tree, node, false)
+}
+
+//Returns the node with the least key > node's key, or nil if there is none
+func Successor[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V]) *AvlNode[K, V] {
+	return findNearestAbove(tree, node, false)
+}
+
+//Replaces specified node in *ptree with newNode and rebalances the tree.
+//Returns false and leaves the tree unchanged if *ptree does not contain node.
+func UpdateNode[K, V any](ptree **AvlTree[K, V], node *AvlNode[K, V], newNode *AvlNode[K, V]) bool {
+	if ptree == nil || newNode == nil || !Has(*ptree, node) {
+		return false
+	}
+	observer := (*ptree).observer
+	removed := removeNode(ptree, node)
+	insertNode(ptree, newNode)
+	observer.updated(removed, newNode)
+	return true
+}
+
+//Returns the subtree whose root has the same key as node, or nil if there is none
+func findSubtreeWithNodeAsRoot[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V]) *AvlTree[K, V] {
+	if node == nil {
+		return nil
+	}
+	for !tree.isEmpty() {
+		rootToNodeCompare := tree.compareNodes(tree.root, node)
 		if rootToNodeCompare == 0 {
 			return tree
 		}
 		if rootToNodeCompare > 0 {
-			return findSubtreeWithNodeAsRoot(tree.left, node)
+			tree = tree.left
+		} else {
+			tree = tree.right
 		}
-		return findSubtreeWithNodeAsRoot(tree.right, node)
 	}
 	return nil
 }
 
-func getTreePtrForInsert(ptree **AvlTree) *AvlTree {
-	var tree *AvlTree
-	if *ptree == nil {
-		tree = NewAvlTree()
-	} else {
-		tree = *ptree
-	}
-	return tree
-}
-
-func insertInChild(tree *AvlTree, node *AvlNode) {
-	if tree == nil {
-		return
-	}
-	rootToNodeCompare := tree.root.compare(node)
-	if rootToNodeCompare >= 0 {
-		Insert(&tree.left, node)
-	} else {
-		Insert(&tree.right, node)
-	}
-}
-
-//Removes node from appropriate child branch
-func removeFromChild(tree *AvlTree, node *AvlNode, rootToNodeComparison int) {
-	if tree == nil {
-		return
+//Descends as in findSubtreeWithNodeAsRoot, keeping the last root below node.
+//Roots equal to node count as below iff inclusive.
+func findNearestBelow[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V], inclusive bool) *AvlNode[K, V] {
+	var nearest *AvlNode[K, V]
+	if node == nil {
+		return nil
 	}
-	if rootToNodeComparison < 0 {
-		removeFromSubtree(&tree.right, node)
-	} else {
-		removeFromSubtree(&tree.left, node)
+	for !tree.isEmpty() {
+		rootToNodeCompare := tree.compareNodes(tree.root, node)
+		if rootToNodeCompare > 0 || (rootToNodeCompare == 0 && !inclusive) {
+			tree = tree.left
+		} else {
+			nearest = tree.root
+			tree = tree.right
+		}
 	}
+	return nearest
 }
 
-//Removes non-root node from *ptree and rebalances the tree
-func removeFromSubtree(ptree **AvlTree, node *AvlNode) {
-	if ptree == nil || *ptree == nil {
-		return
+//Descends as in findSubtreeWithNodeAsRoot, keeping the last root above node.
+//Roots equal to node count as above iff inclusive.
+func findNearestAbove[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V], inclusive bool) *AvlNode[K, V] {
+	var nearest *AvlNode[K, V]
+	if node == nil {
+		return nil
 	}
-	tree := *ptree
-	rootToNodeComparison := tree.root.compare(node)
-	if rootToNodeComparison == 0 {
-		if tree.left != nil {
-			tree.root = Max(tree.left)
-			removeMaxFromSubtree(&tree.left)
-			tree.updateHeight()
-		} else if tree.right != nil {
+	for !tree.isEmpty() {
+		rootToNodeCompare := tree.compareNodes(tree.root, node)
+		if rootToNodeCompare < 0 || (rootToNodeCompare == 0 && !inclusive) {
 			tree = tree.right
 		} else {
-			tree = nil
+			nearest = tree.root
+			tree = tree.left
 		}
-	} else {
-		removeFromChild(tree, node, rootToNodeComparison)
-		tree.updateHeight()
 	}
-	*ptree = tree
+	return nearest
 }
 
-//Remove max from non-root branch
-func removeMaxFromSubtree(ptree **AvlTree) {
-	if ptree == nil || *ptree == nil {
-		return
-	}
-	tree := *ptree
-	if tree.right == nil {
-		tree = tree.left
-	} else {
-		removeMaxFromSubtree(&tree.right)
-		tree.updateHeight()
-	}
-	*ptree = tree
+//Unlinks the max node of the non-empty subtree at slot, appending the slots
+//on the right spine above it to path.  Returns the extended path and the max node.
+func unlinkMax[K, V any](path []**AvlTree[K, V], slot **AvlTree[K, V]) ([]**AvlTree[K, V], *AvlNode[K, V]) {
+	for (*slot).right != nil {
+		path = append(path, slot)
+		slot = &(*slot).right
+	}
+	max := (*slot).root
+	*slot = (*slot).left
+	return path, max
 }
 
-//Requires that tree.left is non-nil
-func replaceWithLeftMax(tree *AvlTree) {
-	tree.root = Max(tree.left)
-	removeMaxFromSubtree(&tree.left)
-	tree.updateHeight()
+//Updates and rebalances the subtree in each slot of path, deepest first.
+//path runs from the top of the tree down to the parent of the changed subtree,
+//so each rotation happens after the subtrees below it are balanced again.
+func rebalancePath[K, V any](path []**AvlTree[K, V]) {
+	for i := len(path) - 1; i >= 0; i-- {
+		(*path[i]).updateHeight()
+		balanceRoot(path[i])
+	}
 }
 
-func removeLastNode(tree *AvlTree) {
+func removeLastNode[K, V any](tree *AvlTree[K, V]) {
 	tree.root = nil
 	tree.height = -1
+	tree.size = 0
+	tree.summary = nil
 }
 
-//Updates height of tree based on heights of children
-func (tree *AvlTree) updateHeight() {
+//Updates height, size and any augmented summary of tree based on its children
+func (tree *AvlTree[K, V]) updateHeight() {
 	if !tree.isEmpty() {
 		height := tree.calcHeightFromChildren()
 		if tree.height != height {
 			tree.height = height
 		}
+		tree.size = tree.left.getSize() + tree.right.getSize() + 1
+		if tree.augment != nil {
+			tree.augment(tree)
+		}
 	}
 }
 
-func (tree *AvlTree) calcHeightFromChildren() int {
+//Creates an empty subtree with the same comparator, augmentation and observer as template
+func newSubtreeLike[K, V any](template *AvlTree[K, V]) *AvlTree[K, V] {
+	tree := NewAvlTreeFunc[K, V](template.compare)
+	tree.augment = template.augment
+	tree.observer = template.observer
+	return tree
+}
+
+func (tree *AvlTree[K, V]) calcHeightFromChildren() int {
 	if tree.isEmpty() {
 		return -1
 	}
@@ -251,20 +488,41 @@ func (tree *AvlTree) calcHeightFromChildren() int {
 	return maxChildHeight + 1
 }
 
-func (tree *AvlTree) getHeight() int {
+func (tree *AvlTree[K, V]) getHeight() int {
 	if tree.isEmpty() {
 		return -1
 	}
 	return tree.height
 }
 
-func balance(ptree **AvlTree) {
+func (tree *AvlTree[K, V]) getSize() int {
+	if tree.isEmpty() {
+		return 0
+	}
+	return tree.size
+}
+
+//Rebalances every subtree of *ptree bottom-up in O(n).  Insert and Remove
+//only rebalance along the path they change; see rebalancePath.
+func balance[K, V any](ptree **AvlTree[K, V]) {
 	if ptree == nil || *ptree == nil {
 		return
 	}
 	tree := *ptree
 	balance(&tree.left)
 	balance(&tree.right)
+	tree.updateHeight()
+	balanceRoot(&tree)
+	*ptree = tree
+}
+
+//Rebalances *ptree with at most one single or double rotation at its root,
+//assuming both children are already balanced
+func balanceRoot[K, V any](ptree **AvlTree[K, V]) {
+	if ptree == nil || *ptree == nil {
+		return
+	}
+	tree := *ptree
 	currBalance := tree.left.getHeight() - tree.right.getHeight()
 	if currBalance > 1 {
 		if tree.left.left.getHeight() >= tree.left.right.getHeight() {
@@ -286,7 +544,7 @@ func balance(ptree **AvlTree) {
 //       t                   tL
 //   tL     tR     ->    tLL     t
 //tLL tLR                      tLR tR
-func rotateLeftToRoot(ptree **AvlTree) {
+func rotateLeftToRoot[K, V any](ptree **AvlTree[K, V]) {
 	if ptree == nil || (*ptree).isEmpty() {
 		return
 	}
@@ -297,6 +555,7 @@ func rotateLeftToRoot(ptree **AvlTree) {
 		prevLeft.right = tree
 		tree.updateHeight()
 		prevLeft.updateHeight()
+		tree.observer.rotated(tree.root, prevLeft)
 		tree = prevLeft
 	}
 	*ptree = tree
@@ -306,7 +565,7 @@ func rotateLeftToRoot(ptree **AvlTree) {
 //       t                   tR
 //   tL     tR     ->     t     tRR
 //        tRL tRR       tL tRL
-func rotateRightToRoot(ptree **AvlTree) {
+func rotateRightToRoot[K, V any](ptree **AvlTree[K, V]) {
 	if ptree == nil || (*ptree).isEmpty() {
 		return
 	}
@@ -317,6 +576,7 @@ func rotateRightToRoot(ptree **AvlTree) {
 		prevRight.left = tree
 		tree.updateHeight()
 		prevRight.updateHeight()
+		tree.observer.rotated(tree.root, prevRight)
 		tree = prevRight
 	}
 	*ptree = tree
@@ -327,7 +587,7 @@ func rotateRightToRoot(ptree **AvlTree) {
 //     L         R             LR         R           L          T
 // LL    LR          ->     L     LRR         ->   LL  LRL    LRR  R
 //     LRL LRR           LL  LRL
-func doubleRotateLeftToRoot(ptree **AvlTree) {
+func doubleRotateLeftToRoot[K, V any](ptree **AvlTree[K, V]) {
 	if ptree == nil || *ptree == nil {
 		return
 	}
@@ -339,7 +599,7 @@ func doubleRotateLeftToRoot(ptree **AvlTree) {
 	}
 }
 
-func doubleRotateRightToRoot(ptree **AvlTree) {
+func doubleRotateRightToRoot[K, V any](ptree **AvlTree[K, V]) {
 	if ptree == nil || *ptree == nil {
 		return
 	}
@@ -351,18 +611,14 @@ func doubleRotateRightToRoot(ptree **AvlTree) {
 	}
 }
 
-func (tree *AvlTree) isEmpty() bool {
+func (tree *AvlTree[K, V]) isEmpty() bool {
 	return tree == nil || tree.root == nil
 }
 
-func debug_printTree(tree *AvlTree, prefix string) {
-	if tree == nil {
-		fmt.Println(prefix + ": AvlTree<nil>")
-	} else if !tree.isEmpty() {
-		fmt.Printf(prefix+": %v\n  root: %v\n", tree, tree.root)
-		debug_printTree(tree.left, prefix+".left")
-		debug_printTree(tree.right, prefix+".right")
-	}
+//Prints tree to stdout in the format of WriteASCII, headed by prefix
+func debug_printTree[K, V any](tree *AvlTree[K, V], prefix string) {
+	fmt.Println(prefix + ":")
+	WriteASCII(os.Stdout, tree)
 }
 
 //Return max of two ints
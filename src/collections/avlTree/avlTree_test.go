@@ -1,69 +1,71 @@
 package avlTree
 
 import (
-	// "fmt"
+	"fmt"
 	"runtime/debug"
+	"strings"
+	"sync"
 	"testing"
 )
 
-func verifyCompareVal(t *testing.T, node *AvlNode, other *AvlNode, expectedVal int) {
-	compareVal := node.compare(other)
+func verifyCompareVal(t *testing.T, node *PriorityKey, other *PriorityKey, expectedVal int) {
+	compareVal := node.Compare(*other)
 	if compareVal != expectedVal {
 		t.Errorf("%v.compare(%v) == %d, expected %d", *node, *other, compareVal, expectedVal)
 		debug.PrintStack()
 	}
 }
 
-func testNodeCompare_OtherIsNil(t *testing.T) {
-	var nilNode AvlNode
-	node := AvlNode{"A", 5}
+func testNodeCompare_OtherIsZero(t *testing.T) {
+	var zeroKey PriorityKey
+	node := PriorityKey{"A", 5}
 	expectedCompareResult := 1
-	verifyCompareVal(t, &node, &nilNode, expectedCompareResult)
+	verifyCompareVal(t, &node, &zeroKey, expectedCompareResult)
 }
 
 func testNodeCompare_SameNode(t *testing.T) {
-	node := AvlNode{"A", 5}
+	node := PriorityKey{"A", 5}
 	expectedCompareResult := 0
 	verifyCompareVal(t, &node, &node, expectedCompareResult)
 }
 
 func testNodeCompare_OtherIsEquivalent(t *testing.T) {
-	node := AvlNode{"A", 5}
-	equivNode := AvlNode{"A", 5}
+	node := PriorityKey{"A", 5}
+	equivNode := PriorityKey{"A", 5}
 	expectedCompareResult := 0
 	verifyCompareVal(t, &node, &equivNode, expectedCompareResult)
 }
 
 func testNodeCompare_OtherHasLowerPriority(t *testing.T) {
-	node := AvlNode{"A", 5}
-	lowerPriorityNode := AvlNode{"B", 3}
+	node := PriorityKey{"A", 5}
+	lowerPriorityNode := PriorityKey{"B", 3}
 	expectedCompareResult := 1
 	verifyCompareVal(t, &node, &lowerPriorityNode, expectedCompareResult)
 }
 
 func testNodeCompare_OtherHasHigherPriority(t *testing.T) {
-	node := AvlNode{"A", 5}
-	higherPriorityNode := AvlNode{"B", 8}
+	node := PriorityKey{"A", 5}
+	higherPriorityNode := PriorityKey{"B", 8}
 	expectedCompareResult := -1
 	verifyCompareVal(t, &node, &higherPriorityNode, expectedCompareResult)
 }
 
 func testNodeCompare_OtherHasSamePriorityLowerData(t *testing.T) {
-	node := AvlNode{"ABC", 5}
-	other := AvlNode{"AAA", 5}
+	node := PriorityKey{"ABC", 5}
+	other := PriorityKey{"AAA", 5}
 	expectedCompareResult := 1
 	verifyCompareVal(t, &node, &other, expectedCompareResult)
 }
 
 func testNodeCompare_OtherHasSamePriorityHigherData(t *testing.T) {
-	node := AvlNode{"AAA", 5}
-	other := AvlNode{"ABC", 5}
+	node := PriorityKey{"AAA", 5}
+	other := PriorityKey{"ABC", 5}
 	expectedCompareResult := -1
 	verifyCompareVal(t, &node, &other, expectedCompareResult)
 }
 
 func TestNodeCompare(t *testing.T) {
-	testNodeCompare_OtherIsNil(t)
+	testNodeCompare_OtherIsZero(t)
 	testNodeCompare_SameNode(t)
 	testNodeCompare_OtherIsEquivalent(t)
 	testNodeCompare_OtherHasLowerPriority(t)
@@ -72,8 +74,20 @@ func TestNodeCompare(t *testing.T) {
 	testNodeCompare_OtherHasSamePriorityHigherData(t)
 }
 
+//Keys with a Compare method are compared by calling it directly, without boxing
+func TestDefaultCompare_CompareMethodDoesNotAllocate(t *testing.T) {
+	compare := defaultCompare[PriorityKey]()
+	first, second := PriorityKey{"a", 1}, PriorityKey{"b", 1}
+	if compare(first, second) >= 0 {
+		t.Errorf("compare(%v, %v) >= 0, expected < 0", first, second)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { compare(first, second) }); allocs != 0 {
+		t.Errorf("compare allocated %v times per call, expected 0", allocs)
+	}
+}
+
 func TestNewAvlTree(t *testing.T) {
-	tree := NewAvlTree()
+	tree := newPriorityTree()
 	expectedHeight := -1
 	if tree.height != expectedHeight {
 		t.Errorf("tree.height == %d, expected %d", tree.height, expectedHeight)
@@ -89,10 +103,69 @@ func TestNewAvlTree(t *testing.T) {
 	}
 }
 
-func verifyTreeIsEmptyVal(t *testing.T, tree *AvlTree, expected bool) {
+func testNewAvlTree_OrderedKeys(t *testing.T) {
+	tree := NewAvlTree[int, string]()
+	for _, key := range []int{5, 3, 8, 1, 4} {
+		Insert(&tree, NewAvlNode(key, "value"))
+	}
+	if min := Min(tree); min == nil || min.Key != 1 {
+		t.Errorf("Min(tree) == %v, expected key 1", min)
+	}
+	if max := Max(tree); max == nil || max.Key != 8 {
+		t.Errorf("Max(tree) == %v, expected key 8", max)
+	}
+	if !Has(tree, NewAvlNode(4, "")) {
+		t.Errorf("Has(tree, 4) == false, expected true")
+	}
+	if Has(tree, NewAvlNode(7, "")) {
+		t.Errorf("Has(tree, 7) == true, expected false")
+	}
+}
+
+func testNewAvlTreeFunc_CustomComparator(t *testing.T) {
+	descending := func(a, b int) int { return b - a }
+	tree := NewAvlTreeFunc[int, string](descending)
+	for _, key := range []int{5, 3, 8, 1, 4} {
+		Insert(&tree, NewAvlNode(key, "value"))
+	}
+	if min := Min(tree); min == nil || min.Key != 8 {
+		t.Errorf("Min(tree) == %v, expected key 8", min)
+	}
+	if max := Max(tree); max == nil || max.Key != 1 {
+		t.Errorf("Max(tree) == %v, expected key 1", max)
+	}
+}
+
+func testNewAvlTreeFunc_KeepsValues(t *testing.T) {
+	type record struct {
+		name  string
+		owner string
+	}
+	tree := NewAvlTreeFunc[PriorityKey, record](ComparePriorityKeys)
+	Insert(&tree, NewAvlNode(PriorityKey{"b", 2}, record{"second", "bob"}))
+	Insert(&tree, NewAvlNode(PriorityKey{"a", 2}, record{"first", "alice"}))
+	Insert(&tree, NewAvlNode(PriorityKey{"c", 1}, record{"lowest", "carol"}))
+
+	max := Max(tree)
+	if max == nil || max.Value.name != "second" {
+		t.Errorf("Max(tree) == %v, expected node with value %q", max, "second")
+	}
+	min := Min(tree)
+	if min == nil || min.Value.owner != "carol" {
+		t.Errorf("Min(tree) == %v, expected node owned by %q", min, "carol")
+	}
+}
+
+func TestNewAvlTreeGeneric(t *testing.T) {
+	testNewAvlTree_OrderedKeys(t)
+	testNewAvlTreeFunc_CustomComparator(t)
+	testNewAvlTreeFunc_KeepsValues(t)
+}
+
+func verifyTreeIsEmptyVal(t *testing.T, tree *priorityTree, expected bool) {
 	isEmpty := tree.isEmpty()
 	if isEmpty != expected {
-		t.Errorf("isEmpty == %b, expected %b", isEmpty, expected)
+		t.Errorf("isEmpty == %t, expected %t", isEmpty, expected)
 		debug.PrintStack()
 	}
 }
@@ -101,10 +174,10 @@ func testTreeIsEmpty_EmptyTree(t *testing.T) {
 	expected := true
 	verifyTreeIsEmptyVal(t, nil, expected)
 
-	var nilTree AvlTree
+	var nilTree priorityTree
 	verifyTreeIsEmptyVal(t, &nilTree, expected)
 
-	emptyTree := NewAvlTree()
+	emptyTree := newPriorityTree()
 	verifyTreeIsEmptyVal(t, emptyTree, expected)
 }
 
@@ -119,7 +192,7 @@ func TestTreeIsEmpty(t *testing.T) {
 	testTreeIsEmpty_Leaf(t)
 }
 
-func verifyTreeCalcHeightFromChildrenVal(t *testing.T, tree *AvlTree, expectedHeight int) {
+func verifyTreeCalcHeightFromChildrenVal(t *testing.T, tree *priorityTree, expectedHeight int) {
 	height := tree.calcHeightFromChildren()
 	if height != expectedHeight {
 		t.Errorf("calcHeightFromChildren() == %d, expected %d", height, expectedHeight)
@@ -131,7 +204,7 @@ func testTreeCalcHeightFromChildren_NilTree(t *testing.T) {
 	expectedHeight := -1
 	verifyTreeCalcHeightFromChildrenVal(t, nil, expectedHeight)
 
-	var nilTree AvlTree
+	var nilTree priorityTree
 	verifyTreeCalcHeightFromChildrenVal(t, &nilTree, expectedHeight)
 }
 
@@ -165,7 +238,7 @@ func TestTreeCalcHeight(t *testing.T) {
 	testTreeCalcHeightFromChildren_Grandparent(t)
 }
 
-func verifyGetHeightVal(t *testing.T, tree *AvlTree, expectedHeight int) {
+func verifyGetHeightVal(t *testing.T, tree *priorityTree, expectedHeight int) {
 	height := tree.getHeight()
 	if height != expectedHeight {
 		t.Errorf("getHeight() == %d, expected %d", height, expectedHeight)
@@ -177,13 +250,13 @@ func testTreeGetHeight_NilTree(t *testing.T) {
 	expectedHeight := -1
 	verifyGetHeightVal(t, nil, expectedHeight)
 
-	var nilTree AvlTree
+	var nilTree priorityTree
 	verifyGetHeightVal(t, &nilTree, expectedHeight)
 }
 
 func testTreeGetHeight_Leaf(t *testing.T) {
 	leafNode := createAvlNode("alpha", 3)
-	var leaf AvlTree
+	var leaf priorityTree
 	leafPtr := &leaf
 	Insert(&leafPtr, leafNode)
 	expectedHeight := 0
@@ -195,7 +268,7 @@ func TestTreeGetHeight(t *testing.T) {
 	testTreeGetHeight_Leaf(t)
 }
 
-func verifyUpdateHeight(t *testing.T, tree *AvlTree, expectedNewHeight int) {
+func verifyUpdateHeight(t *testing.T, tree *priorityTree, expectedNewHeight int) {
 	tree.updateHeight()
 	updatedHeight := tree.getHeight()
 	if updatedHeight != expectedNewHeight {
@@ -208,10 +281,10 @@ func testTreeUpdateHeight_EmptyTree(t *testing.T) {
 	expectedNewHeight := -1
 	verifyUpdateHeight(t, nil, expectedNewHeight)
 
-	var nilTree AvlTree
+	var nilTree priorityTree
 	verifyUpdateHeight(t, &nilTree, expectedNewHeight)
 
-	emptyTree := NewAvlTree()
+	emptyTree := newPriorityTree()
 	verifyUpdateHeight(t, emptyTree, expectedNewHeight)
 }
 
@@ -236,7 +309,7 @@ func TestTreeUpdateHeight(t *testing.T) {
 	testTreeUpdateHeight_Parent(t)
 }
 
-func verifyTreeRotateLeft_Empty(t *testing.T, tree *AvlTree) {
+func verifyTreeRotateLeft_Empty(t *testing.T, tree *priorityTree) {
 	if tree == nil {
 		rotateLeftToRoot(&tree)
 		verifyTreePointersEqual(t, tree, nil)
@@ -247,7 +320,7 @@ func verifyTreeRotateLeft_Empty(t *testing.T, tree *AvlTree) {
 	}
 }
 
-func verifyTreeRotateRight_Empty(t *testing.T, tree *AvlTree) {
+func verifyTreeRotateRight_Empty(t *testing.T, tree *priorityTree) {
 	if tree == nil {
 		rotateRightToRoot(&tree)
 		verifyTreePointersEqual(t, tree, nil)
@@ -258,7 +331,7 @@ func verifyTreeRotateRight_Empty(t *testing.T, tree *AvlTree) {
 	}
 }
 
-func verifyTreePointersEqual(t *testing.T, tree *AvlTree, expected *AvlTree) {
+func verifyTreePointersEqual(t *testing.T, tree *priorityTree, expected *priorityTree) {
 	if tree != expected {
 		if tree == nil {
 			t.Errorf("tree == nil, expected %v\nexpected.root == %v", expected, expected.root)
@@ -269,7 +342,16 @@ func verifyTreePointersEqual(t *testing.T, tree *AvlTree, expected *AvlTree) {
 	}
 }
 
-func verifyTreeLAndR(t *testing.T, tree *AvlTree, expectedLeft *AvlTree, expectedRight *AvlTree) {
+//Compares tree fields individually since AvlTree holds a comparator func
+func verifyTreeValsEqual(t *testing.T, tree *priorityTree, expected priorityTree) {
+	if tree.root != expected.root || tree.height != expected.height || tree.size != expected.size ||
+		tree.left != expected.left || tree.right != expected.right {
+		t.Errorf("tree == &%v, expected &%v", *tree, expected)
+		debug.PrintStack()
+	}
+}
+
+func verifyTreeLAndR(t *testing.T, tree *priorityTree, expectedLeft *priorityTree, expectedRight *priorityTree) {
 	verifyTreePointersEqual(t, tree.left, expectedLeft)
 	verifyTreePointersEqual(t, tree.right, expectedRight)
 }
@@ -277,20 +359,20 @@ func verifyTreeLAndR(t *testing.T, tree *AvlTree, expectedLeft *AvlTree, expecte
 func testTreeRotateLeft_EmptyTree(t *testing.T) {
 	verifyTreeRotateLeft_Empty(t, nil)
 
-	var nilTree AvlTree
+	var nilTree priorityTree
 	verifyTreeRotateLeft_Empty(t, &nilTree)
 
-	emptyTree := NewAvlTree()
+	emptyTree := newPriorityTree()
 	verifyTreeRotateLeft_Empty(t, emptyTree)
 }
 
 func testTreeRotateRight_EmptyTree(t *testing.T) {
 	verifyTreeRotateRight_Empty(t, nil)
 
-	var nilTree AvlTree
+	var nilTree priorityTree
 	verifyTreeRotateRight_Empty(t, &nilTree)
 
-	emptyTree := NewAvlTree()
+	emptyTree := newPriorityTree()
 	verifyTreeRotateRight_Empty(t, emptyTree)
 }
 
@@ -298,18 +380,14 @@ func testTreeRotateLeft_Leaf(t *testing.T) {
 	leaf := createAvlTree_Leaf("a", 1)
 	prevLeafVal := *leaf
 	rotateLeftToRoot(&leaf)
-	if *leaf != prevLeafVal {
-		t.Errorf("tree == &%v, expected &%v", *leaf, prevLeafVal)
-	}
+	verifyTreeValsEqual(t, leaf, prevLeafVal)
 }
 
 func testTreeRotateRight_Leaf(t *testing.T) {
 	leaf := createAvlTree_Leaf("a", 1)
 	prevLeafVal := *leaf
 	rotateRightToRoot(&leaf)
-	if *leaf != prevLeafVal {
-		t.Errorf("tree == &%v, expected &%v", *leaf, prevLeafVal)
-	}
+	verifyTreeValsEqual(t, leaf, prevLeafVal)
 }
 
 func testTreeRotateLeft_ParentWithNoLeft(t *testing.T) {
@@ -317,9 +395,7 @@ func testTreeRotateLeft_ParentWithNoLeft(t *testing.T) {
 	parent := createAvlTree("parent", 1, 1, nil, right)
 	prevParentVal := *parent
 	rotateLeftToRoot(&parent)
-	if *parent != prevParentVal {
-		t.Errorf("tree == &%v, expected &%v", *parent, prevParentVal)
-	}
+	verifyTreeValsEqual(t, parent, prevParentVal)
 }
 
 func testTreeRotateLeft_ParentWithNoRight(t *testing.T) {
@@ -357,9 +433,7 @@ func testTreeRotateRight_ParentWithNoRight(t *testing.T) {
 	tree := createAvlTree("parent", 5, 1, left, nil)
 	prevTree := &*tree
 	rotateRightToRoot(&tree)
-	if *tree != *prevTree {
-		t.Errorf("tree == &%v, expected &%v", *tree, *prevTree)
-	}
+	verifyTreeValsEqual(t, tree, *prevTree)
 }
 
 func testTreeRotateLeft_ParentWithLAndR(t *testing.T) {
@@ -454,7 +528,7 @@ func TestTreeRotateRightToRoot(t *testing.T) {
 	testTreeRotateRight_LongRightTail(t)
 }
 
-func verifyTreeDoubleRotateLeft_Empty(t *testing.T, tree *AvlTree) {
+func verifyTreeDoubleRotateLeft_Empty(t *testing.T, tree *priorityTree) {
 	if tree == nil {
 		doubleRotateLeftToRoot(&tree)
 		verifyTreePointersEqual(t, tree, nil)
@@ -465,7 +539,7 @@ func verifyTreeDoubleRotateLeft_Empty(t *testing.T, tree *AvlTree) {
 	}
 }
 
-func verifyTreeDoubleRotateRight_Empty(t *testing.T, tree *AvlTree) {
+func verifyTreeDoubleRotateRight_Empty(t *testing.T, tree *priorityTree) {
 	if tree == nil {
 		doubleRotateRightToRoot(&tree)
 		verifyTreePointersEqual(t, tree, nil)
@@ -479,20 +553,20 @@ func verifyTreeDoubleRotateRight_Empty(t *testing.T, tree *AvlTree) {
 func testTreeDoubleRotateLeft_EmptyTree(t *testing.T) {
 	verifyTreeDoubleRotateLeft_Empty(t, nil)
 
-	var nilTree AvlTree
+	var nilTree priorityTree
 	verifyTreeDoubleRotateLeft_Empty(t, &nilTree)
 
-	emptyTree := NewAvlTree()
+	emptyTree := newPriorityTree()
 	verifyTreeDoubleRotateLeft_Empty(t, emptyTree)
 }
 
 func testTreeDoubleRotateRight_EmptyTree(t *testing.T) {
 	verifyTreeDoubleRotateRight_Empty(t, nil)
 
-	var nilTree AvlTree
+	var nilTree priorityTree
 	verifyTreeDoubleRotateRight_Empty(t, &nilTree)
 
-	emptyTree := NewAvlTree()
+	emptyTree := newPriorityTree()
 	verifyTreeDoubleRotateRight_Empty(t, emptyTree)
 }
 
@@ -542,7 +616,7 @@ func testTreeDoubleRotateLeft_LeftWithRight(t *testing.T) {
 
 	doubleRotateLeftToRoot(&tree)
 	if tree != leftR {
-		debug_printTree(tree, "T")
+		dumpTree(t, "T", tree)
 		t.Errorf("tree == %v, expected %v", tree, leftR)
 	}
 	verifyTreeLAndR(t, tree, left, prevTree)
@@ -644,14 +718,14 @@ func TestTreeDoubleRotateRightToRoot(t *testing.T) {
 	testTreeDoubleRotateRight_RightWithGrandchildren(t)
 }
 
-func verifyTreeBalanceHasNoEffect(t *testing.T, tree *AvlTree) {
+func verifyTreeBalanceHasNoEffect(t *testing.T, tree *priorityTree) {
 	prevTree := &*tree
 	balance(&tree)
 	verifyTreePointersEqual(t, tree, prevTree)
 }
 
 func testTreeBalance_Nil(t *testing.T) {
-	var tree *AvlTree = nil
+	var tree *priorityTree = nil
 	balance(&tree)
 	verifyTreePointersEqual(t, tree, nil)
 }
@@ -672,10 +746,10 @@ func testTreeBalance_AlreadyBalanced(t *testing.T) {
 }
 
 func testTreeBalance_Empty(t *testing.T) {
-	var nilTree AvlTree
+	var nilTree priorityTree
 	verifyTreeBalanceHasNoEffect(t, &nilTree)
 
-	emptyTree := NewAvlTree()
+	emptyTree := newPriorityTree()
 	verifyTreeBalanceHasNoEffect(t, emptyTree)
 }
 
@@ -811,7 +885,7 @@ func TestTreeBalance(t *testing.T) {
 	testTreeBalance_NoRight_LeftGrandchildren(t)
 }
 
-func verifyNodePointersEqual(t *testing.T, node *AvlNode, expected *AvlNode) {
+func verifyNodePointersEqual(t *testing.T, node *priorityNode, expected *priorityNode) {
 	if node != expected {
 		t.Errorf("node == %v, expected %v", node, expected)
 		debug.PrintStack()
@@ -819,16 +893,82 @@ func verifyNodePointersEqual(t *testing.T, node *AvlNode, expected *AvlNode) {
 }
 
 func testTreeInsert_NilTree(t *testing.T) {
-	var nilTree *AvlTree = nil
-	node := createAvlNode("a", 1)
-	prevNode := &*node
+	var nilTree *priorityTree = nil
+	node := createAvlNode("b", 1)
 	Insert(&nilTree, node)
-	//Expect no effect, just verify no errors
-	verifyCompareVal(t, node, prevNode, 0)
+	if nilTree == nil {
+		t.Fatalf("tree == nil after Insert, expected a new tree")
+	}
+	verifyNodePointersEqual(t, nilTree.root, node)
+	verifyGetHeightVal(t, nilTree, 0)
+
+	//The new tree is ordered by PriorityKey.Compare
+	Insert(&nilTree, createAvlNode("a", 1))
+	Insert(&nilTree, createAvlNode("c", 0))
+	verifyNodePointersEqual(t, Max(nilTree), node)
+	if err := Validate(nilTree); err != nil {
+		t.Errorf("Validate(tree) == %v", err)
+	}
+}
+
+//Zero-value trees, with no comparator, are ordered by the key's natural ordering
+func testTreeInsert_ZeroValueTree(t *testing.T) {
+	type celsius int
+	var tree AvlTree[celsius, string]
+	treePtr := &tree
+	for _, key := range []celsius{5, -3, 12, 0, 7} {
+		Insert(&treePtr, NewAvlNode(key, ""))
+	}
+	if treePtr.size != 5 {
+		t.Errorf("tree.size == %d, expected 5", treePtr.size)
+	}
+	if min, max := Min(treePtr), Max(treePtr); min.Key != -3 || max.Key != 12 {
+		t.Errorf("Min, Max == %v, %v, expected -3, 12", min.Key, max.Key)
+	}
+	if err := Validate(treePtr); err != nil {
+		t.Errorf("Validate(tree) == %v", err)
+	}
+}
+
+func testTreeInsert_ZeroValueTreeUnorderedKeys(t *testing.T) {
+	type point struct{ x, y int }
+	var tree AvlTree[point, string]
+	treePtr := &tree
+	Insert(&treePtr, NewAvlNode(point{1, 2}, ""))
+	defer func() {
+		if recovered := recover(); recovered == nil || !strings.Contains(fmt.Sprint(recovered), "no comparator") {
+			t.Errorf("Insert recovered %v, expected a panic naming the missing comparator", recovered)
+		}
+	}()
+	Insert(&treePtr, NewAvlNode(point{3, 4}, ""))
+}
+
+//Insert stores the default comparator, so reads of a zero-value tree do not write it
+func testTreeInsert_ZeroValueTreeConcurrentReads(t *testing.T) {
+	var tree AvlTree[int, string]
+	treePtr := &tree
+	Insert(&treePtr, NewAvlNode(1, "one"))
+	if tree.compare == nil {
+		t.Fatalf("tree.compare == nil after Insert, expected the default comparator")
+	}
+	var readers sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			if !Has(treePtr, NewAvlNode(1, "")) || Has(treePtr, NewAvlNode(2, "")) {
+				t.Errorf("Has == wrong membership for keys 1 and 2")
+			}
+			if err := Validate(treePtr); err != nil {
+				t.Errorf("Validate(tree) == %v", err)
+			}
+		}()
+	}
+	readers.Wait()
 }
 
 func testTreeInsert_EmptyTree(t *testing.T) {
-	var tree AvlTree
+	var tree priorityTree
 	treePtr := &tree
 	node := createAvlNode("a", 1)
 	Insert(&treePtr, node)
@@ -885,16 +1025,37 @@ func testTreeInsert_LongTailShouldBalance(t *testing.T) {
 	verifyGetHeightVal(t, tree.right, 0)
 }
 
+//Ascending inserts always descend the right spine, which only stays short
+//if every insert rebalances the whole path back up to the root
+func testTreeInsert_AscendingKeysStayBalanced(t *testing.T) {
+	tree := NewAvlTree[int, string]()
+	for key := 0; key < 1<<14; key++ {
+		Insert(&tree, NewAvlNode(key, ""))
+	}
+	if err := Validate(tree); err != nil {
+		t.Errorf("Validate(tree) == %v", err)
+	}
+	//AVL trees of n nodes have height < 1.45 log2(n + 2)
+	if tree.height > 20 {
+		t.Errorf("tree.height == %d, expected <= 20", tree.height)
+	}
+	verifySizeVal(t, tree, 1<<14)
+}
+
 func TestTreeInsert(t *testing.T) {
 	testTreeInsert_NilTree(t)
 	testTreeInsert_EmptyTree(t)
+	testTreeInsert_ZeroValueTree(t)
+	testTreeInsert_ZeroValueTreeUnorderedKeys(t)
+	testTreeInsert_ZeroValueTreeConcurrentReads(t)
 	testTreeInsert_FirstChild_LowerPriority(t)
 	testTreeInsert_FirstGrandchild_InitBalanced(t)
 	testTreeInsert_LongTailShouldBalance(t)
+	testTreeInsert_AscendingKeysStayBalanced(t)
 }
 
 func testTreeRemoveMax_EmptyTree(t *testing.T) {
-	var tree AvlTree
+	var tree priorityTree
 	treePtr := &tree
 	RemoveMax(&treePtr)
 
@@ -906,12 +1067,12 @@ func testTreeRemoveMax_EmptyTree(t *testing.T) {
 }
 
 func testTreeRemoveMax_NilNode(t *testing.T) {
-	tree := NewAvlTree()
+	tree := newPriorityTree()
 	rootNode := createAvlNode("a", 1)
 	Insert(&tree, rootNode)
 
 	prevTree := &*tree
-	var nilNode *AvlNode = nil
+	var nilNode *priorityNode = nil
 	Remove(&tree, nilNode)
 
 	verifyTreePointersEqual(t, tree, prevTree)
@@ -965,7 +1126,7 @@ func TestTreeRemoveMax(t *testing.T) {
 }
 
 func testTreeRemove_NilTree(t *testing.T) {
-	var nilTree *AvlTree = nil
+	var nilTree *priorityTree = nil
 	node := createAvlNode("a", 1)
 	Remove(&nilTree, node)
 	if nilTree != nil {
@@ -974,7 +1135,7 @@ func testTreeRemove_NilTree(t *testing.T) {
 }
 
 func testTreeRemove_EmptyTree(t *testing.T) {
-	var tree AvlTree
+	var tree priorityTree
 	treePtr := &tree
 	node := createAvlNode("a", 1)
 	Remove(&treePtr, node)
@@ -987,12 +1148,12 @@ func testTreeRemove_EmptyTree(t *testing.T) {
 }
 
 func testTreeRemove_NilNode(t *testing.T) {
-	tree := NewAvlTree()
+	tree := newPriorityTree()
 	rootNode := createAvlNode("a", 1)
 	Insert(&tree, rootNode)
 
 	prevTree := &*tree
-	var nilNode *AvlNode = nil
+	var nilNode *priorityNode = nil
 	Remove(&tree, nilNode)
 
 	verifyTreePointersEqual(t, tree, prevTree)
@@ -1001,7 +1162,7 @@ func testTreeRemove_NilNode(t *testing.T) {
 }
 
 func testTreeRemove_OnlyNode(t *testing.T) {
-	tree := NewAvlTree()
+	tree := newPriorityTree()
 	rootNode := createAvlNode("a", 1)
 	Insert(&tree, rootNode)
 
@@ -1099,6 +1260,24 @@ func testTreeRemove_RootWithLeftBranch(t *testing.T) {
 	verifyGetHeightVal(t, left, 1)
 }
 
+func testTreeRemove_AlternateKeysStayBalanced(t *testing.T) {
+	tree := NewAvlTree[int, string]()
+	for key := 0; key < 1<<12; key++ {
+		Insert(&tree, NewAvlNode(key, ""))
+	}
+	for key := 0; key < 1<<12; key += 2 {
+		Remove(&tree, NewAvlNode(key, ""))
+	}
+	RemoveMax(&tree)
+	if err := Validate(tree); err != nil {
+		t.Errorf("Validate(tree) == %v", err)
+	}
+	verifySizeVal(t, tree, 1<<11-1)
+	if Has(tree, NewAvlNode(2, "")) || !Has(tree, NewAvlNode(3, "")) {
+		t.Errorf("expected odd keys only")
+	}
+}
+
 func TestTreeRemove(t *testing.T) {
 	testTreeRemove_NilTree(t)
 	testTreeRemove_EmptyTree(t)
@@ -1109,30 +1288,31 @@ func TestTreeRemove(t *testing.T) {
 	testTreeRemove_Leaf_RequiringRebalance(t)
 	testTreeRemove_Root_NoLeft(t)
 	testTreeRemove_RootWithLeftBranch(t)
+	testTreeRemove_AlternateKeysStayBalanced(t)
 }
 
 func testTreeMax_NilTree(t *testing.T) {
-	var nilTree *AvlTree = nil
+	var nilTree *priorityTree = nil
 	max := Max(nilTree)
 	verifyNodePointersEqual(t, max, nil)
 }
 
 func testTreeMax_EmptyTree(t *testing.T) {
-	emptyTree := NewAvlTree()
+	emptyTree := newPriorityTree()
 	max := Max(emptyTree)
 	verifyNodePointersEqual(t, max, nil)
 }
 
 func testTreeMax_SingleElement(t *testing.T) {
 	node := createAvlNode("data", 6)
-	tree := NewAvlTree()
+	tree := newPriorityTree()
 	Insert(&tree, node)
 	max := Max(tree)
 	verifyNodePointersEqual(t, max, node)
 }
 
 func testTreeMax_NoRightNodes(t *testing.T) {
-	tree := NewAvlTree()
+	tree := newPriorityTree()
 	rootNode := createAvlNode("root", -6)
 	leftNode := createAvlNode("left", -9)
 	Insert(&tree, rootNode)
@@ -1153,7 +1333,7 @@ func testTreeMax_HasRightGrandchildOnLeft(t *testing.T) {
 	max := Max(tree)
 	if max == nil {
 		t.Errorf("Max(tree) == nil, expected &{%s %d}", rightData, rightPriority)
-	} else if max.data != rightData || max.priority != rightPriority {
+	} else if max.Key.Data != rightData || max.Key.Priority != rightPriority {
 		t.Errorf("Max(tree) == %v, expected &{%s %d}", max, rightData, rightPriority)
 	}
 }
@@ -1170,7 +1350,7 @@ func testTreeMax_HasRightGrandchildOnRight(t *testing.T) {
 	max := Max(tree)
 	if max == nil {
 		t.Errorf("Max(tree) == nil, expected &{%s %d}", maxData, maxPriority)
-	} else if max.data != maxData || max.priority != maxPriority {
+	} else if max.Key.Data != maxData || max.Key.Priority != maxPriority {
 		t.Errorf("Max(tree) == %v, expected &{%s %d}", max, maxData, maxPriority)
 	}
 }
@@ -1185,27 +1365,27 @@ func TestTreeMax(t *testing.T) {
 }
 
 func testTreeMin_NilTree(t *testing.T) {
-	var nilTree *AvlTree = nil
+	var nilTree *priorityTree = nil
 	min := Min(nilTree)
 	verifyNodePointersEqual(t, min, nil)
 }
 
 func testTreeMin_EmptyTree(t *testing.T) {
-	emptyTree := NewAvlTree()
+	emptyTree := newPriorityTree()
 	min := Min(emptyTree)
 	verifyNodePointersEqual(t, min, nil)
 }
 
 func testTreeMin_SingleElement(t *testing.T) {
 	node := createAvlNode("data", 6)
-	tree := NewAvlTree()
+	tree := newPriorityTree()
 	Insert(&tree, node)
 	min := Min(tree)
 	verifyNodePointersEqual(t, min, node)
 }
 
 func testTreeMin_NoLeftNodes(t *testing.T) {
-	tree := NewAvlTree()
+	tree := newPriorityTree()
 	rootNode := createAvlNode("root", -6)
 	rightNode := createAvlNode("right", 3)
 	Insert(&tree, rootNode)
@@ -1226,7 +1406,7 @@ func testTreeMin_HasLeftGrandchildOnRight(t *testing.T) {
 	min := Min(tree)
 	if min == nil {
 		t.Errorf("Min(tree) == nil, expected &{%s %d}", leftData, leftPriority)
-	} else if min.data != leftData || min.priority != leftPriority {
+	} else if min.Key.Data != leftData || min.Key.Priority != leftPriority {
 		t.Errorf("Min(tree) == %v, expected &{%s %d}", min, leftData, leftPriority)
 	}
 }
@@ -1244,7 +1424,7 @@ func testTreeMin_HasLeftGrandchildOnLeft(t *testing.T) {
 	min := Min(tree)
 	if min == nil {
 		t.Errorf("Min(tree) == nil, expected &{%s %d}", minData, minPriority)
-	} else if min.data != minData || min.priority != minPriority {
+	} else if min.Key.Data != minData || min.Key.Priority != minPriority {
 		t.Errorf("Min(tree) == %v, expected &{%s %d}", min, minData, minPriority)
 	}
 }
@@ -1259,7 +1439,7 @@ func TestTreeMin(t *testing.T) {
 }
 
 func testTreeHas_NilTree(t *testing.T) {
-	var nilTree *AvlTree = nil
+	var nilTree *priorityTree = nil
 	node := createAvlNode("data", 5)
 	hasNode := Has(nilTree, node)
 	if hasNode {
@@ -1268,7 +1448,7 @@ func testTreeHas_NilTree(t *testing.T) {
 }
 
 func testTreeHas_EmptyTree(t *testing.T) {
-	var tree AvlTree
+	var tree priorityTree
 	node := createAvlNode("data", 5)
 	hasNode := Has(&tree, node)
 	if hasNode {
@@ -1278,7 +1458,7 @@ func testTreeHas_EmptyTree(t *testing.T) {
 
 func testTreeHas_NilNode(t *testing.T) {
 	tree := createAvlTree_Leaf("data", 5)
-	var node *AvlNode = nil
+	var node *priorityNode = nil
 	hasNode := Has(tree, node)
 	if hasNode {
 		t.Errorf("Has(%v, %v) == true, expected false", tree, node)
@@ -1287,7 +1467,7 @@ func testTreeHas_NilNode(t *testing.T) {
 
 func testTreeHas_IsRoot(t *testing.T) {
 	node := createAvlNode("data", 6)
-	tree := NewAvlTree()
+	tree := newPriorityTree()
 	Insert(&tree, node)
 	hasNode := Has(tree, node)
 	if !hasNode {
@@ -1309,7 +1489,7 @@ func testTreeHas_IsChild(t *testing.T) {
 	hasNode := Has(tree, searchNode)
 	if !hasNode {
 		t.Errorf("Has(%v, %v) == false, expected true", tree, searchNode)
-		debug_printTree(tree, "T")
+		dumpTree(t, "T", tree)
 	}
 }
 
@@ -1327,7 +1507,7 @@ func testTreeHas_IsGrandchild(t *testing.T) {
 	hasNode := Has(tree, searchNode)
 	if !hasNode {
 		t.Errorf("Has(%v, %v) == false, expected true", tree, searchNode)
-		debug_printTree(tree, "T")
+		dumpTree(t, "T", tree)
 	}
 }
 
@@ -1345,7 +1525,7 @@ func testTreeHas_PriorityNotInTree(t *testing.T) {
 	hasNode := Has(tree, searchNode)
 	if hasNode {
 		t.Errorf("Has(%v, %v) == true, expected false", tree, searchNode)
-		debug_printTree(tree, "T")
+		dumpTree(t, "T", tree)
 	}
 }
 
@@ -1363,7 +1543,7 @@ func testTreeHas_DataNotInTree(t *testing.T) {
 	hasNode := Has(tree, searchNode)
 	if hasNode {
 		t.Errorf("Has(%v, %v) == true, expected false", tree, searchNode)
-		debug_printTree(tree, "T")
+		dumpTree(t, "T", tree)
 	}
 }
 
@@ -1378,6 +1558,135 @@ func TestTreeHas(t *testing.T) {
 	testTreeHas_DataNotInTree(t)
 }
 
+func testTreeUpdateNode_NilTreeOrNodes(t *testing.T) {
+	node := createAvlNode("a", 1)
+	if UpdateNode(nil, node, node) {
+		t.Errorf("UpdateNode(nil, ...) == true, expected false")
+	}
+	var nilTree *priorityTree
+	if UpdateNode(&nilTree, node, node) {
+		t.Errorf("UpdateNode(&nilTree, ...) == true, expected false")
+	}
+	tree := createAvlTree_Leaf("a", 1)
+	if UpdateNode(&tree, tree.root, nil) {
+		t.Errorf("UpdateNode(&tree, root, nil) == true, expected false")
+	}
+	verifyGetHeightVal(t, tree, 0)
+}
+
+func testTreeUpdateNode_NodeNotInTree(t *testing.T) {
+	tree := createAvlTree_Leaf("a", 1)
+	prevRoot := tree.root
+	if UpdateNode(&tree, createAvlNode("b", 2), createAvlNode("c", 3)) {
+		t.Errorf("UpdateNode of missing node == true, expected false")
+	}
+	verifyNodePointersEqual(t, tree.root, prevRoot)
+	verifyTreeLAndR(t, tree, nil, nil)
+}
+
+func testTreeUpdateNode_ReplacesRoot(t *testing.T) {
+	rightNode := createAvlNode("right", 5)
+	tree := newPriorityTree()
+	Insert(&tree, createAvlNode("root", 3))
+	Insert(&tree, rightNode)
+
+	newNode := createAvlNode("new", 9)
+	if !UpdateNode(&tree, createAvlNode("root", 3), newNode) {
+		t.Errorf("UpdateNode of root == false, expected true")
+	}
+	verifyNodePointersEqual(t, tree.root, rightNode)
+	verifyTreeLAndR(t, tree, nil, tree.right)
+	verifyNodePointersEqual(t, Max(tree), newNode)
+	verifyGetHeightVal(t, tree, 1)
+}
+
+func TestTreeUpdateNode(t *testing.T) {
+	testTreeUpdateNode_NilTreeOrNodes(t)
+	testTreeUpdateNode_NodeNotInTree(t)
+	testTreeUpdateNode_ReplacesRoot(t)
+}
+
+type nearestNodeFn func(*priorityTree, *priorityNode) *priorityNode
+
+func verifyNearestNode(t *testing.T, fnName string, fn nearestNodeFn, tree *priorityTree, query *priorityNode, expected *priorityNode) {
+	nearest := fn(tree, query)
+	if nearest != expected {
+		t.Errorf("%s(tree, %v) == %v, expected %v", fnName, query, nearest, expected)
+		debug.PrintStack()
+	}
+}
+
+//Builds a tree of priorities 10, 20, ..., 70, returning the tree and its nodes in ascending order
+func createNearestNodeFixture() (*priorityTree, []*priorityNode) {
+	tree := newPriorityTree()
+	nodes := []*priorityNode{}
+	for priority := 10; priority <= 70; priority += 10 {
+		node := createAvlNode("n", priority)
+		Insert(&tree, node)
+		nodes = append(nodes, node)
+	}
+	return tree, nodes
+}
+
+func testTreeNearest_NilTreeOrNode(t *testing.T) {
+	tree, nodes := createNearestNodeFixture()
+	fns := map[string]nearestNodeFn{
+		"Floor":       Floor[PriorityKey, struct{}],
+		"Ceiling":     Ceiling[PriorityKey, struct{}],
+		"Predecessor": Predecessor[PriorityKey, struct{}],
+		"Successor":   Successor[PriorityKey, struct{}],
+	}
+	for fnName, fn := range fns {
+		verifyNearestNode(t, fnName, fn, nil, nodes[0], nil)
+		verifyNearestNode(t, fnName, fn, newPriorityTree(), nodes[0], nil)
+		verifyNearestNode(t, fnName, fn, tree, nil, nil)
+	}
+}
+
+func testTreeFloor(t *testing.T) {
+	tree, nodes := createNearestNodeFixture()
+	verifyNearestNode(t, "Floor", Floor, tree, createAvlNode("n", 40), nodes[3])
+	verifyNearestNode(t, "Floor", Floor, tree, createAvlNode("n", 45), nodes[3])
+	verifyNearestNode(t, "Floor", Floor, tree, createAvlNode("a", 40), nodes[2])
+	verifyNearestNode(t, "Floor", Floor, tree, createAvlNode("n", 100), nodes[6])
+	verifyNearestNode(t, "Floor", Floor, tree, createAvlNode("n", 5), nil)
+}
+
+func testTreeCeiling(t *testing.T) {
+	tree, nodes := createNearestNodeFixture()
+	verifyNearestNode(t, "Ceiling", Ceiling, tree, createAvlNode("n", 40), nodes[3])
+	verifyNearestNode(t, "Ceiling", Ceiling, tree, createAvlNode("n", 45), nodes[4])
+	verifyNearestNode(t, "Ceiling", Ceiling, tree, createAvlNode("z", 40), nodes[4])
+	verifyNearestNode(t, "Ceiling", Ceiling, tree, createAvlNode("n", 5), nodes[0])
+	verifyNearestNode(t, "Ceiling", Ceiling, tree, createAvlNode("n", 100), nil)
+}
+
+func testTreePredecessor(t *testing.T) {
+	tree, nodes := createNearestNodeFixture()
+	for i := 1; i < len(nodes); i++ {
+		verifyNearestNode(t, "Predecessor", Predecessor, tree, nodes[i], nodes[i-1])
+	}
+	verifyNearestNode(t, "Predecessor", Predecessor, tree, nodes[0], nil)
+	verifyNearestNode(t, "Predecessor", Predecessor, tree, createAvlNode("n", 35), nodes[2])
+}
+
+func testTreeSuccessor(t *testing.T) {
+	tree, nodes := createNearestNodeFixture()
+	for i := 0; i < len(nodes)-1; i++ {
+		verifyNearestNode(t, "Successor", Successor, tree, nodes[i], nodes[i+1])
+	}
+	verifyNearestNode(t, "Successor", Successor, tree, nodes[len(nodes)-1], nil)
+	verifyNearestNode(t, "Successor", Successor, tree, createAvlNode("n", 35), nodes[3])
+}
+
+func TestTreeNearest(t *testing.T) {
+	testTreeNearest_NilTreeOrNode(t)
+	testTreeFloor(t)
+	testTreeCeiling(t)
+	testTreePredecessor(t)
+	testTreeSuccessor(t)
+}
+
 func testMaxInt_DiffVals(t *testing.T) {
 	lower := -1
 	higher := 0
@@ -1404,22 +1713,31 @@ func TestMaxInt(t *testing.T) {
 	testMaxInt_SameVals(t)
 }
 
-func createAvlTree(data string, priority int, height int, left *AvlTree, right *AvlTree) *AvlTree {
-	tree := NewAvlTree()
+type priorityTree = AvlTree[PriorityKey, struct{}]
+type priorityNode = AvlNode[PriorityKey, struct{}]
+
+func newPriorityTree() *priorityTree {
+	return NewAvlTreeFunc[PriorityKey, struct{}](ComparePriorityKeys)
+}
+
+func createAvlTree(data string, priority int, height int, left *priorityTree, right *priorityTree) *priorityTree {
+	tree := newPriorityTree()
 	tree.root = createAvlNode(data, priority)
 	tree.height = height
 	tree.left = left
 	tree.right = right
+	tree.size = left.getSize() + right.getSize() + 1
 	return tree
 }
 
-func createAvlTree_Leaf(data string, priority int) *AvlTree {
-	tree := NewAvlTree()
+func createAvlTree_Leaf(data string, priority int) *priorityTree {
+	tree := newPriorityTree()
 	tree.root = createAvlNode(data, priority)
 	tree.height = 0
+	tree.size = 1
 	return tree
 }
 
-func createAvlNode(data string, priority int) *AvlNode {
-	return &AvlNode{data, priority}
+func createAvlNode(data string, priority int) *priorityNode {
+	return NewAvlNode(PriorityKey{data, priority}, struct{}{})
 }
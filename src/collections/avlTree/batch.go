@@ -0,0 +1,234 @@
+package avlTree
+
+import (
+	"fmt"
+	"slices"
+)
+
+//Group of Insert, Remove and UpdateNode calls applied to *ptree all at once
+//by Commit, or not at all.  Operations are only recorded until Commit, so
+//neither the tree nor its observer sees any of them before then.
+//
+//Commit replays the operations in order against an overlay of the tree,
+//failing without changing the tree if an UpdateNode finds no node to replace.
+//The overlay decides which nodes the batch removes, taking nodes the batch
+//added before nodes already in the tree, and which added nodes survive.  Large
+//batches merge the surviving nodes and rebuild the tree in a single balancing
+//pass, taking O(n + k log k) for k changes instead of k separate O(log n)
+//rebalances.  Small batches apply the same changes in place, each rebalancing
+//only its path, so both leave the tree holding the same nodes in the same order.
+type Batch[K, V any] struct {
+	ptree **AvlTree[K, V]
+	ops   []batchOp[K, V]
+	done  bool
+}
+
+type batchOpKind int
+
+const (
+	batchInsert batchOpKind = iota
+	batchRemove
+	batchUpdate
+)
+
+type batchOp[K, V any] struct {
+	kind    batchOpKind
+	node    *AvlNode[K, V]
+	newNode *AvlNode[K, V]
+}
+
+//Changes made by replaying a batch, which Commit applies to the tree
+type batchOverlay[K, V any] struct {
+	tree *AvlTree[K, V]
+	//Nodes inserted by the batch and not removed again, ordered like tree
+	added *AvlTree[K, V]
+	//Number of tree's nodes removed per key, the removed nodes themselves and
+	//their indexes in tree's ascending order
+	removedCounts  *SortedMultiset[K]
+	removed        map[*AvlNode[K, V]]bool
+	removedIndexes []int
+	//Observer notifications for each operation which changed the tree, in order
+	events []batchOp[K, V]
+}
+
+//Starts a batch of updates to *ptree.  If *ptree is nil, Commit creates a tree
+//ordered as Insert would.
+func NewBatch[K, V any](ptree **AvlTree[K, V]) *Batch[K, V] {
+	return &Batch[K, V]{ptree: ptree}
+}
+
+//Records an Insert of node
+func (batch *Batch[K, V]) Insert(node *AvlNode[K, V]) {
+	batch.record(batchOp[K, V]{batchInsert, node, nil})
+}
+
+//Records a Remove of node
+func (batch *Batch[K, V]) Remove(node *AvlNode[K, V]) {
+	batch.record(batchOp[K, V]{batchRemove, node, nil})
+}
+
+//Records an UpdateNode of node to newNode.  Commit fails if, after the
+//operations recorded before it, the tree does not contain node.
+func (batch *Batch[K, V]) UpdateNode(node *AvlNode[K, V], newNode *AvlNode[K, V]) {
+	batch.record(batchOp[K, V]{batchUpdate, node, newNode})
+}
+
+//Applies the recorded operations to *ptree, then notifies the tree's observer
+//of each insert, remove and update in the order recorded.  Returns an error
+//and leaves the tree unchanged if any UpdateNode has no node to replace, or if
+//the batch was already committed or rolled back.  A failed commit may be
+//retried once the tree has changed.
+//
+//Small batches notify the observer of rotations as they are applied, before
+//the other notifications.  Batches large enough to rebuild the tree do not.
+func (batch *Batch[K, V]) Commit() error {
+	if batch.done {
+		return fmt.Errorf("avlTree: batch already committed or rolled back")
+	}
+	if batch.ptree == nil {
+		return fmt.Errorf("avlTree: cannot commit a batch to a nil tree pointer")
+	}
+	tree := *batch.ptree
+	if tree == nil {
+		//Only stored in *ptree if the commit succeeds
+		tree = NewAvlTreeFunc[K, V](defaultCompare[K]())
+	}
+	if tree.resolveComparator() == nil {
+		return fmt.Errorf("avlTree: cannot commit a batch to a tree without a comparator")
+	}
+	tree.initComparator()
+	overlay := newBatchOverlay(tree)
+	for i, op := range batch.ops {
+		if !overlay.apply(op) {
+			return fmt.Errorf("avlTree: batch operation %d updates %v, which is not in the tree", i, op.node.Key)
+		}
+	}
+	batch.done = true
+	if overlay.appliesInPlace() {
+		overlay.applyInPlace(batch.ptree)
+	} else {
+		rebuilt := buildFromSorted(overlay.mergedNodes(), tree)
+		if rebuilt == nil {
+			rebuilt = newSubtreeLike(tree)
+		}
+		*batch.ptree = rebuilt
+	}
+	for _, event := range overlay.events {
+		switch event.kind {
+		case batchInsert:
+			tree.observer.inserted(event.node)
+		case batchRemove:
+			tree.observer.removed(event.node)
+		case batchUpdate:
+			tree.observer.updated(event.node, event.newNode)
+		}
+	}
+	return nil
+}
+
+//Discards the recorded operations, leaving the tree as it was
+func (batch *Batch[K, V]) Rollback() {
+	batch.done = true
+	batch.ops = nil
+}
+
+func (batch *Batch[K, V]) record(op batchOp[K, V]) {
+	if batch.done || op.node == nil || (op.kind == batchUpdate && op.newNode == nil) {
+		return
+	}
+	batch.ops = append(batch.ops, op)
+}
+
+func newBatchOverlay[K, V any](tree *AvlTree[K, V]) *batchOverlay[K, V] {
+	return &batchOverlay[K, V]{
+		tree:          tree,
+		added:         NewAvlTreeFunc[K, V](tree.resolveComparator()),
+		removedCounts: NewSortedMultisetFunc[K](tree.resolveComparator()),
+		removed:       map[*AvlNode[K, V]]bool{},
+	}
+}
+
+//Applies op to the overlay, returns false iff op is an UpdateNode with no node to replace
+func (overlay *batchOverlay[K, V]) apply(op batchOp[K, V]) bool {
+	switch op.kind {
+	case batchInsert:
+		Insert(&overlay.added, op.node)
+		overlay.events = append(overlay.events, op)
+	case batchRemove:
+		if removed := overlay.remove(op.node); removed != nil {
+			overlay.events = append(overlay.events, batchOp[K, V]{batchRemove, removed, nil})
+		}
+	case batchUpdate:
+		removed := overlay.remove(op.node)
+		if removed == nil {
+			return false
+		}
+		Insert(&overlay.added, op.newNode)
+		overlay.events = append(overlay.events, batchOp[K, V]{batchUpdate, removed, op.newNode})
+	}
+	return true
+}
+
+//Removes a node with node's key, preferring one added by the batch.
+//Returns the removed node, or nil if neither the batch nor the tree has one.
+func (overlay *batchOverlay[K, V]) remove(node *AvlNode[K, V]) *AvlNode[K, V] {
+	if subtree := findSubtreeWithNodeAsRoot(overlay.added, node); subtree != nil {
+		removed := subtree.root
+		Remove(&overlay.added, removed)
+		return removed
+	}
+	//Nodes with equal keys are adjacent in order, so the tree's j-th node with
+	//node's key is at index Rank + j.  They are removed first to last.
+	removedCount := overlay.removedCounts.Count(node.Key)
+	if CountRange(overlay.tree, node.Key, node.Key) <= removedCount {
+		return nil
+	}
+	index := Rank(overlay.tree, node) + removedCount
+	removed := Select(overlay.tree, index)
+	overlay.removedCounts.Add(node.Key)
+	overlay.removed[removed] = true
+	overlay.removedIndexes = append(overlay.removedIndexes, index)
+	return removed
+}
+
+//Returns whether applying each change along its path, O(k log n) for k
+//changes, is cheaper than rebuilding the tree in O(n)
+func (overlay *batchOverlay[K, V]) appliesInPlace() bool {
+	changes := len(overlay.removedIndexes) + Size(overlay.added)
+	return changes*(overlay.tree.height+2) < Size(overlay.tree)
+}
+
+//Removes the removed nodes from *ptree and inserts the added ones, without
+//notifying the observer of anything but rotations
+func (overlay *batchOverlay[K, V]) applyInPlace(ptree **AvlTree[K, V]) {
+	//Removing from the greatest index down leaves the lesser indexes unchanged
+	slices.Sort(overlay.removedIndexes)
+	for _, index := range slices.Backward(overlay.removedIndexes) {
+		removeNodeAt(ptree, index)
+	}
+	//Insert places each node before nodes with an equal key, so inserting the
+	//added nodes in descending order leaves them in the merged order
+	added := collectNodes(overlay.added)
+	for _, node := range slices.Backward(added) {
+		insertNode(ptree, node)
+	}
+}
+
+//Returns the tree's remaining nodes merged with the added nodes, in ascending
+//key order.  Added nodes come before the tree's nodes with an equal key, where
+//Insert would put them.
+func (overlay *batchOverlay[K, V]) mergedNodes() []*AvlNode[K, V] {
+	merged := make([]*AvlNode[K, V], 0, Size(overlay.tree)-len(overlay.removed)+Size(overlay.added))
+	added := collectNodes(overlay.added)
+	for node := range overlay.tree.All() {
+		if overlay.removed[node] {
+			continue
+		}
+		for len(added) > 0 && overlay.tree.comparator()(added[0].Key, node.Key) <= 0 {
+			merged = append(merged, added[0])
+			added = added[1:]
+		}
+		merged = append(merged, node)
+	}
+	return append(merged, added...)
+}
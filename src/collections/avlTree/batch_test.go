@@ -0,0 +1,330 @@
+package avlTree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func verifyCommitErr(t *testing.T, err error, expectedErrMsg string) {
+	if expectedErrMsg == "" {
+		if err != nil {
+			t.Errorf("Commit() == %v, expected nil", err)
+		}
+	} else if err == nil || !strings.Contains(err.Error(), expectedErrMsg) {
+		t.Errorf("Commit() == %v, expected error containing %q", err, expectedErrMsg)
+	}
+}
+
+func verifyValidTree(t *testing.T, tree *AvlTree[int, string]) {
+	if err := Validate(tree); err != nil {
+		dumpTree(t, "tree", tree)
+		t.Errorf("Validate(tree) == %v", err)
+	}
+}
+
+func testBatch_CommitAppliesAll(t *testing.T) {
+	tree := createIntTree(1, 2, 3, 4, 5)
+	batch := NewBatch(&tree)
+	batch.Insert(NewAvlNode(10, ""))
+	batch.Remove(NewAvlNode(2, ""))
+	batch.UpdateNode(NewAvlNode(4, ""), NewAvlNode(0, ""))
+	batch.Remove(NewAvlNode(7, ""))
+	verifyIteratedKeys(t, tree.All(), []int{1, 2, 3, 4, 5})
+
+	verifyCommitErr(t, batch.Commit(), "")
+	verifyIteratedKeys(t, tree.All(), []int{0, 1, 3, 5, 10})
+	verifyValidTree(t, tree)
+}
+
+func testBatch_LaterOpsSeeEarlierOnes(t *testing.T) {
+	tree := createIntTree(1)
+	batch := NewBatch(&tree)
+	batch.Insert(NewAvlNode(5, ""))
+	batch.UpdateNode(NewAvlNode(5, ""), NewAvlNode(6, ""))
+	batch.Remove(NewAvlNode(1, ""))
+	batch.Insert(NewAvlNode(1, ""))
+	batch.UpdateNode(NewAvlNode(6, ""), NewAvlNode(7, ""))
+	verifyCommitErr(t, batch.Commit(), "")
+	verifyIteratedKeys(t, tree.All(), []int{1, 7})
+}
+
+func testBatch_DuplicateKeys(t *testing.T) {
+	tree := createIntTree(2, 2, 3)
+	batch := NewBatch(&tree)
+	batch.Insert(NewAvlNode(2, ""))
+	for i := 0; i < 4; i++ {
+		batch.Remove(NewAvlNode(2, ""))
+	}
+	verifyCommitErr(t, batch.Commit(), "")
+	verifyIteratedKeys(t, tree.All(), []int{3})
+	verifyValidTree(t, tree)
+}
+
+func testBatch_FailedUpdateLeavesTreeUnchanged(t *testing.T) {
+	tree := createIntTree(1, 2, 3)
+	original := tree
+	events := []string{}
+	SetObserver(tree, createRecordingObserver(&events))
+
+	batch := NewBatch(&tree)
+	batch.Insert(NewAvlNode(4, ""))
+	batch.Remove(NewAvlNode(2, ""))
+	batch.UpdateNode(NewAvlNode(2, ""), NewAvlNode(9, ""))
+	verifyCommitErr(t, batch.Commit(), "batch operation 2 updates 2")
+	if tree != original {
+		t.Errorf("tree == %p after a failed commit, expected %p", tree, original)
+	}
+	verifyIteratedKeys(t, tree.All(), []int{1, 2, 3})
+	verifyValidTree(t, tree)
+	verifyEvents(t, events)
+}
+
+func testBatch_RetryFailedCommit(t *testing.T) {
+	tree := createIntTree(1, 3)
+	batch := NewBatch(&tree)
+	batch.UpdateNode(NewAvlNode(2, ""), NewAvlNode(5, ""))
+	verifyCommitErr(t, batch.Commit(), "batch operation 0 updates 2")
+	Insert(&tree, NewAvlNode(2, ""))
+	verifyCommitErr(t, batch.Commit(), "")
+	verifyIteratedKeys(t, tree.All(), []int{1, 3, 5})
+}
+
+//A few operations on a large tree rebalance their paths rather than rebuilding
+//the tree, so the observer sees their rotations
+func testBatch_SmallBatchNotifiesRotations(t *testing.T) {
+	keys := make([]int, 1000)
+	for i := range keys {
+		keys[i] = i
+	}
+	tree := createIntTree(keys...)
+	events := []string{}
+	SetObserver(tree, createRecordingObserver(&events))
+	batch := NewBatch(&tree)
+	for key := 1000; key < 1003; key++ {
+		batch.Insert(NewAvlNode(key, ""))
+	}
+	verifyCommitErr(t, batch.Commit(), "")
+
+	inserts, rotations := 0, 0
+	for _, event := range events {
+		if strings.HasPrefix(event, "insert") {
+			inserts++
+		} else if strings.HasPrefix(event, "rotate") {
+			rotations++
+		}
+	}
+	if inserts != 3 || rotations == 0 {
+		t.Errorf("events == %q, expected 3 inserts and their rotations", events)
+	}
+	verifySizeVal(t, tree, 1003)
+	verifyValidTree(t, tree)
+}
+
+func testBatch_Rollback(t *testing.T) {
+	tree := createIntTree(1, 2, 3)
+	batch := NewBatch(&tree)
+	batch.Insert(NewAvlNode(4, ""))
+	batch.Remove(NewAvlNode(1, ""))
+	batch.Rollback()
+	verifyIteratedKeys(t, tree.All(), []int{1, 2, 3})
+
+	batch.Insert(NewAvlNode(5, ""))
+	verifyCommitErr(t, batch.Commit(), "already committed or rolled back")
+	verifyIteratedKeys(t, tree.All(), []int{1, 2, 3})
+}
+
+func testBatch_CommitTwice(t *testing.T) {
+	tree := createIntTree(1)
+	batch := NewBatch(&tree)
+	batch.Insert(NewAvlNode(2, ""))
+	verifyCommitErr(t, batch.Commit(), "")
+	verifyCommitErr(t, batch.Commit(), "already committed or rolled back")
+	verifyIteratedKeys(t, tree.All(), []int{1, 2})
+}
+
+func testBatch_EmptiesTree(t *testing.T) {
+	tree := createIntTree(1, 2)
+	batch := NewBatch(&tree)
+	batch.Remove(NewAvlNode(1, ""))
+	batch.Remove(NewAvlNode(2, ""))
+	verifyCommitErr(t, batch.Commit(), "")
+	verifyIteratedKeys(t, tree.All(), []int{})
+	Insert(&tree, NewAvlNode(3, ""))
+	verifyIteratedKeys(t, tree.All(), []int{3})
+}
+
+func testBatch_NotifiesObserverInOrder(t *testing.T) {
+	tree := createIntTree(1, 2)
+	events := []string{}
+	SetObserver(tree, createRecordingObserver(&events))
+	batch := NewBatch(&tree)
+	batch.Insert(NewAvlNode(3, ""))
+	batch.UpdateNode(NewAvlNode(1, ""), NewAvlNode(4, ""))
+	batch.Remove(NewAvlNode(2, ""))
+	batch.Remove(NewAvlNode(8, ""))
+	verifyEvents(t, events)
+
+	verifyCommitErr(t, batch.Commit(), "")
+	verifyEvents(t, events, "insert 3", "update 1 4", "remove 2")
+	Insert(&tree, NewAvlNode(5, ""))
+	verifyEvents(t, events, "insert 3", "update 1 4", "remove 2", "insert 5")
+}
+
+func testBatch_KeepsAugmentation(t *testing.T) {
+	itree := NewIntervalTree[int, string]()
+	itree.Insert(1, 2, "")
+	batch := NewBatch(&itree.tree)
+	batch.Insert(NewAvlNode(Interval[int]{5, 50}, ""))
+	batch.Insert(NewAvlNode(Interval[int]{10, 11}, ""))
+	verifyCommitErr(t, batch.Commit(), "")
+	verifyMaxEndpoints(t, itree.tree)
+	verifyIntervals(t, collectIntervals(itree.Stabbing(20)), []Interval[int]{{5, 50}})
+}
+
+//Commits random batches and checks each against applying the same operations one at a time
+func testBatch_RandomAgainstSequential(t *testing.T) {
+	rng := rand.New(rand.NewSource(18))
+	tree := NewAvlTree[int, string]()
+	for round := 0; round < 200; round++ {
+		sequential := FromSorted(collectNodes(tree))
+		batch := NewBatch(&tree)
+		updated := true
+		for i := 0; i < 1+rng.Intn(20); i++ {
+			key := rng.Intn(40)
+			switch rng.Intn(3) {
+			case 0:
+				batch.Insert(NewAvlNode(key, ""))
+				Insert(&sequential, NewAvlNode(key, ""))
+			case 1:
+				batch.Remove(NewAvlNode(key, ""))
+				Remove(&sequential, NewAvlNode(key, ""))
+			case 2:
+				newKey := rng.Intn(40)
+				batch.UpdateNode(NewAvlNode(key, ""), NewAvlNode(newKey, ""))
+				updated = UpdateNode(&sequential, NewAvlNode(key, ""), NewAvlNode(newKey, "")) && updated
+			}
+		}
+		before := collectKeys(tree.All())
+		err := batch.Commit()
+		if updated {
+			verifyCommitErr(t, err, "")
+			verifyIteratedKeys(t, tree.All(), collectKeys(sequential.All()))
+		} else {
+			verifyCommitErr(t, err, "not in the tree")
+			verifyIteratedKeys(t, tree.All(), before)
+		}
+		verifyValidTree(t, tree)
+		if !slices.IsSorted(collectKeys(tree.All())) {
+			t.Fatalf("round %d: keys out of order", round)
+		}
+	}
+}
+
+//Returns the tree's nodes in order, so trees can be compared node for node
+func collectNodeValues(tree *AvlTree[int, string]) []string {
+	values := []string{}
+	for node := range tree.All() {
+		values = append(values, fmt.Sprintf("%d:%s", node.Key, node.Value))
+	}
+	return values
+}
+
+//The same batch leaves the same nodes whether it is applied in place or rebuilds the tree
+func testBatch_InPlaceAgreesWithRebuild(t *testing.T) {
+	commit := func(fillers int) []string {
+		events := []string{}
+		tree := createIntTree(createKeyRange(1000, 1000+fillers)...)
+		Insert(&tree, NewAvlNode(5, "orig"))
+		SetObserver(tree, createRecordingObserver(&events))
+		batch := NewBatch(&tree)
+		batch.Insert(NewAvlNode(5, "batch"))
+		batch.Remove(NewAvlNode(5, ""))
+		verifyCommitErr(t, batch.Commit(), "")
+		verifyValidTree(t, tree)
+		found := Floor(tree, NewAvlNode(5, ""))
+		if found == nil || found.Key != 5 || found.Value != "orig" {
+			t.Errorf("tree with %d fillers holds %v at key 5, expected orig", fillers, found)
+		}
+		notifications := []string{}
+		for _, event := range events {
+			if !strings.HasPrefix(event, "rotate") {
+				notifications = append(notifications, event)
+			}
+		}
+		return notifications
+	}
+	rebuiltEvents := commit(0)
+	inPlaceEvents := commit(1000)
+	if !slices.Equal(rebuiltEvents, inPlaceEvents) {
+		t.Errorf("observer saw %q when rebuilding, %q in place", rebuiltEvents, inPlaceEvents)
+	}
+}
+
+//Applies random batches with duplicate keys both ways to copies of the same tree
+func testBatch_RandomInPlaceAgainstRebuild(t *testing.T) {
+	rng := rand.New(rand.NewSource(18))
+	for round := 0; round < 200; round++ {
+		tree := NewAvlTree[int, string]()
+		for i := 0; i < rng.Intn(50); i++ {
+			Insert(&tree, NewAvlNode(rng.Intn(10), fmt.Sprintf("tree%d", i)))
+		}
+		overlay := newBatchOverlay(tree)
+		for i := 0; i < 1+rng.Intn(20); i++ {
+			key := rng.Intn(10)
+			switch rng.Intn(3) {
+			case 0:
+				overlay.apply(batchOp[int, string]{batchInsert, NewAvlNode(key, fmt.Sprintf("batch%d", i)), nil})
+			case 1:
+				overlay.apply(batchOp[int, string]{batchRemove, NewAvlNode(key, ""), nil})
+			case 2:
+				overlay.apply(batchOp[int, string]{batchUpdate, NewAvlNode(key, ""), NewAvlNode(rng.Intn(10), fmt.Sprintf("update%d", i))})
+			}
+		}
+		rebuilt := orNewTree(buildFromSorted(overlay.mergedNodes(), tree), NewAvlTree[int, string]())
+		overlay.applyInPlace(&tree)
+		verifyValidTree(t, tree)
+		if inPlace, expected := collectNodeValues(tree), collectNodeValues(rebuilt); !slices.Equal(inPlace, expected) {
+			t.Fatalf("round %d: in place left %q, rebuilding left %q", round, inPlace, expected)
+		}
+	}
+}
+
+//A batch on a nil tree creates it as Insert does, but only if the commit succeeds
+func testBatch_NilTree(t *testing.T) {
+	var tree *AvlTree[int, string]
+	batch := NewBatch(&tree)
+	batch.UpdateNode(NewAvlNode(1, ""), NewAvlNode(2, ""))
+	verifyCommitErr(t, batch.Commit(), "not in the tree")
+	if tree != nil {
+		t.Errorf("tree == %v after failed Commit, expected nil", tree)
+	}
+
+	batch = NewBatch(&tree)
+	batch.Insert(NewAvlNode(2, ""))
+	batch.Insert(NewAvlNode(1, ""))
+	verifyCommitErr(t, batch.Commit(), "")
+	verifyIteratedKeys(t, tree.All(), []int{1, 2})
+	Insert(&tree, NewAvlNode(0, ""))
+	verifyIteratedKeys(t, tree.All(), []int{0, 1, 2})
+}
+
+func TestBatch(t *testing.T) {
+	testBatch_CommitAppliesAll(t)
+	testBatch_LaterOpsSeeEarlierOnes(t)
+	testBatch_DuplicateKeys(t)
+	testBatch_FailedUpdateLeavesTreeUnchanged(t)
+	testBatch_RetryFailedCommit(t)
+	testBatch_SmallBatchNotifiesRotations(t)
+	testBatch_Rollback(t)
+	testBatch_CommitTwice(t)
+	testBatch_EmptiesTree(t)
+	testBatch_NotifiesObserverInOrder(t)
+	testBatch_KeepsAugmentation(t)
+	testBatch_RandomAgainstSequential(t)
+	testBatch_InPlaceAgreesWithRebuild(t)
+	testBatch_RandomInPlaceAgainstRebuild(t)
+	testBatch_NilTree(t)
+}
@@ -0,0 +1,116 @@
+package avlTree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+//Tree sizes for the scaling benchmarks.  With O(log n) updates, going from
+//1e5 to 1e6 nodes should add only a few steps to each operation, not 10x the time.
+var benchmarkSizes = []int{1e5, 1e6}
+
+//Builds a tree holding the even keys 0, 2, ..., 2 * (size - 1)
+func createEvenKeyTree(size int) *AvlTree[int, string] {
+	nodes := make([]*AvlNode[int, string], size)
+	for i := range nodes {
+		nodes[i] = NewAvlNode(2*i, "")
+	}
+	return FromSorted(nodes)
+}
+
+//Inserts then removes a random odd key, so the tree keeps its size across iterations
+func BenchmarkInsertRemove(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("n=%d", size), func(b *testing.B) {
+			tree := createEvenKeyTree(size)
+			rng := rand.New(rand.NewSource(1))
+			for b.Loop() {
+				node := NewAvlNode(2*rng.Intn(size)+1, "")
+				Insert(&tree, node)
+				Remove(&tree, node)
+			}
+		})
+	}
+}
+
+//Removes the max key then inserts it back
+func BenchmarkRemoveMax(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("n=%d", size), func(b *testing.B) {
+			tree := createEvenKeyTree(size)
+			for b.Loop() {
+				max := Max(tree)
+				RemoveMax(&tree)
+				Insert(&tree, max)
+			}
+		})
+	}
+}
+
+func BenchmarkHas(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("n=%d", size), func(b *testing.B) {
+			tree := createEvenKeyTree(size)
+			rng := rand.New(rand.NewSource(1))
+			for b.Loop() {
+				Has(tree, NewAvlNode(rng.Intn(2*size), ""))
+			}
+		})
+	}
+}
+
+//Builds an ordered set of impl holding the even keys 0, 2, ..., 2 * (size - 1), inserted in random order
+func createEvenKeyOrderedSet(impl orderedSetImpl, size int) OrderedSet[int, string] {
+	set := impl.create()
+	for _, i := range rand.New(rand.NewSource(1)).Perm(size) {
+		set.Insert(NewAvlNode(2*i, ""))
+	}
+	return set
+}
+
+//Compares the balancing schemes behind OrderedSet on the workload of BenchmarkInsertRemove
+func BenchmarkOrderedSetInsertRemove(b *testing.B) {
+	for _, impl := range orderedSetImpls {
+		for _, size := range benchmarkSizes {
+			b.Run(fmt.Sprintf("%s/n=%d", impl.name, size), func(b *testing.B) {
+				set := createEvenKeyOrderedSet(impl, size)
+				rng := rand.New(rand.NewSource(1))
+				for b.Loop() {
+					node := NewAvlNode(2*rng.Intn(size)+1, "")
+					set.Insert(node)
+					set.Remove(node)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkOrderedSetHas(b *testing.B) {
+	for _, impl := range orderedSetImpls {
+		for _, size := range benchmarkSizes {
+			b.Run(fmt.Sprintf("%s/n=%d", impl.name, size), func(b *testing.B) {
+				set := createEvenKeyOrderedSet(impl, size)
+				rng := rand.New(rand.NewSource(1))
+				for b.Loop() {
+					set.Has(NewAvlNode(rng.Intn(2*size), ""))
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkOrderedSetRemoveMax(b *testing.B) {
+	for _, impl := range orderedSetImpls {
+		for _, size := range benchmarkSizes {
+			b.Run(fmt.Sprintf("%s/n=%d", impl.name, size), func(b *testing.B) {
+				set := createEvenKeyOrderedSet(impl, size)
+				for b.Loop() {
+					max := set.Max()
+					set.RemoveMax()
+					set.Insert(max)
+				}
+			})
+		}
+	}
+}
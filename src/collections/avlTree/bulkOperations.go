@@ -0,0 +1,174 @@
+package avlTree
+
+import (
+	"cmp"
+)
+
+//Builds a balanced tree from nodes in O(n), ordered by the natural ordering of K.
+//nodes must already be in ascending key order.
+func FromSorted[K cmp.Ordered, V any](nodes []*AvlNode[K, V]) *AvlTree[K, V] {
+	return FromSortedFunc(nodes, cmp.Compare[K])
+}
+
+//Builds a balanced tree from nodes in O(n), ordered by compare.
+//nodes must already be in ascending order according to compare.
+func FromSortedFunc[K, V any](nodes []*AvlNode[K, V], compare func(a, b K) int) *AvlTree[K, V] {
+	template := NewAvlTreeFunc[K, V](compare)
+	return orNewTree(buildFromSorted(nodes, template), template)
+}
+
+//Splits tree into a tree holding nodes with keys < key and a tree holding
+//nodes with keys >= key, in O(log n).  Both keep tree's comparator,
+//augmentation and observer.  tree is consumed and must not be used afterwards.
+func Split[K, V any](tree *AvlTree[K, V], key K) (*AvlTree[K, V], *AvlTree[K, V]) {
+	if tree == nil {
+		return nil, nil
+	}
+	template := templateOf(tree)
+	less, rest := splitSubtree(tree, key)
+	return orNewTree(less, template), orNewTree(rest, newSubtreeLike(template))
+}
+
+//Joins left, pivot and right into a single balanced tree in O(log n).
+//All keys in left must be <= pivot's key, which must be <= all keys in right.
+//If pivot is nil, the max node of left is used as the pivot.  The result keeps
+//the comparator, augmentation and observer of left, or of right if left is
+//empty.  If the other tree had a different observer, every subtree of the
+//result is set to the kept one, in O(n).
+//left and right are consumed and must not be used afterwards.
+func Join[K, V any](left *AvlTree[K, V], pivot *AvlNode[K, V], right *AvlTree[K, V]) *AvlTree[K, V] {
+	template := templateOf(left, right)
+	inputs := []*AvlTree[K, V]{left, right}
+	var joined *AvlTree[K, V]
+	if pivot == nil && left.isEmpty() {
+		joined = orNewTree(nonEmptyOrNil(right), template)
+	} else {
+		if pivot == nil {
+			left, pivot = splitMax(left)
+		}
+		joined = joinSubtrees(nonEmptyOrNil(left), pivot, nonEmptyOrNil(right), template)
+	}
+	unifyObserver(joined, template, inputs...)
+	return joined
+}
+
+//Builds a balanced subtree of sorted nodes, with the comparator and augmentation of template
+func buildFromSorted[K, V any](nodes []*AvlNode[K, V], template *AvlTree[K, V]) *AvlTree[K, V] {
+	if len(nodes) == 0 {
+		return nil
+	}
+	mid := len(nodes) / 2
+	tree := newSubtreeLike(template)
+	tree.root = nodes[mid]
+	tree.left = buildFromSorted(nodes[:mid], template)
+	tree.right = buildFromSorted(nodes[mid+1:], template)
+	tree.updateHeight()
+	return tree
+}
+
+//Returns trees with keys < key and keys >= key, either of which may be nil
+func splitSubtree[K, V any](tree *AvlTree[K, V], key K) (*AvlTree[K, V], *AvlTree[K, V]) {
+	if tree.isEmpty() {
+		return nil, nil
+	}
+	if tree.comparator()(tree.root.Key, key) < 0 {
+		less, rest := splitSubtree(tree.right, key)
+		return joinSubtrees(tree.left, tree.root, less, tree), rest
+	}
+	less, rest := splitSubtree(tree.left, key)
+	return less, joinSubtrees(rest, tree.root, tree.right, tree)
+}
+
+//Removes the max node from a non-empty tree in O(log n), returning the remaining tree and the max node
+func splitMax[K, V any](tree *AvlTree[K, V]) (*AvlTree[K, V], *AvlNode[K, V]) {
+	if tree.right.isEmpty() {
+		return tree.left, tree.root
+	}
+	rest, max := splitMax(tree.right)
+	return joinSubtrees(tree.left, tree.root, rest, tree), max
+}
+
+//Descends the spine of the taller tree until the heights are within one,
+//attaches pivot there in a subtree like template and rebalances on the way back up
+func joinSubtrees[K, V any](left *AvlTree[K, V], pivot *AvlNode[K, V], right *AvlTree[K, V], template *AvlTree[K, V]) *AvlTree[K, V] {
+	if left.getHeight() > right.getHeight()+1 {
+		left.right = joinSubtrees(left.right, pivot, right, template)
+		left.updateHeight()
+		balanceRoot(&left)
+		return left
+	}
+	if right.getHeight() > left.getHeight()+1 {
+		right.left = joinSubtrees(left, pivot, right.left, template)
+		right.updateHeight()
+		balanceRoot(&right)
+		return right
+	}
+	tree := newSubtreeLike(template)
+	tree.root = pivot
+	tree.left = nonEmptyOrNil(left)
+	tree.right = nonEmptyOrNil(right)
+	tree.updateHeight()
+	return tree
+}
+
+//Returns the comparator of the first non-nil tree which has one
+func comparatorOf[K, V any](trees ...*AvlTree[K, V]) func(K, K) int {
+	for _, tree := range trees {
+		if tree == nil {
+			continue
+		}
+		if compare := tree.resolveComparator(); compare != nil {
+			return compare
+		}
+	}
+	return nil
+}
+
+//Returns a new empty tree with the comparator, augmentation and observer of
+//the first non-empty tree, or else of the first non-nil tree, to build the
+//result of an operation on trees from.  Without either, the tree is ordered
+//by K's default ordering.
+func templateOf[K, V any](trees ...*AvlTree[K, V]) *AvlTree[K, V] {
+	var source *AvlTree[K, V]
+	for _, tree := range trees {
+		if !tree.isEmpty() {
+			source = tree
+			break
+		}
+		if source == nil {
+			source = tree
+		}
+	}
+	if source == nil {
+		return NewAvlTreeFunc[K, V](defaultCompare[K]())
+	}
+	template := newSubtreeLike(source)
+	template.compare = source.resolveComparator()
+	return template
+}
+
+//Sets template's observer on every subtree of result if any of inputs, the
+//trees result was built from, had a different one
+func unifyObserver[K, V any](result *AvlTree[K, V], template *AvlTree[K, V], inputs ...*AvlTree[K, V]) {
+	for _, input := range inputs {
+		if input != nil && input.observer != template.observer {
+			SetObserver(result, template.observer)
+			return
+		}
+	}
+}
+
+func nonEmptyOrNil[K, V any](tree *AvlTree[K, V]) *AvlTree[K, V] {
+	if tree.isEmpty() {
+		return nil
+	}
+	return tree
+}
+
+//Returns tree, or empty if tree is nil
+func orNewTree[K, V any](tree *AvlTree[K, V], empty *AvlTree[K, V]) *AvlTree[K, V] {
+	if tree == nil {
+		return empty
+	}
+	return tree
+}
@@ -0,0 +1,159 @@
+package avlTree
+
+import (
+	"testing"
+)
+
+func createSortedIntNodes(lo int, hi int) []*AvlNode[int, string] {
+	nodes := []*AvlNode[int, string]{}
+	for key := lo; key < hi; key++ {
+		nodes = append(nodes, NewAvlNode(key, ""))
+	}
+	return nodes
+}
+
+func keyRange(lo int, hi int) []int {
+	keys := []int{}
+	for key := lo; key < hi; key++ {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func testFromSorted_Empty(t *testing.T) {
+	tree := FromSorted[int, string](nil)
+	verifySizeVal(t, tree, 0)
+	Insert(&tree, NewAvlNode(1, ""))
+	verifyIteratedKeys(t, tree.All(), []int{1})
+}
+
+func testFromSorted_IsBalanced(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 8, 100, 1023, 1024} {
+		tree := FromSorted(createSortedIntNodes(0, n))
+		verifyAvlInvariants(t, tree)
+		verifySizeVal(t, tree, n)
+		verifyIteratedKeys(t, tree.All(), keyRange(0, n))
+	}
+}
+
+func testFromSorted_SupportsUpdates(t *testing.T) {
+	tree := FromSorted(createSortedIntNodes(0, 100))
+	Insert(&tree, NewAvlNode(-1, ""))
+	Remove(&tree, NewAvlNode(50, ""))
+	RemoveMax(&tree)
+	verifyAvlInvariants(t, tree)
+	verifySizeVal(t, tree, 99)
+}
+
+func testFromSortedFunc_PriorityKeys(t *testing.T) {
+	nodes := []*priorityNode{createAvlNode("a", 1), createAvlNode("b", 1), createAvlNode("a", 5)}
+	tree := FromSortedFunc(nodes, ComparePriorityKeys)
+	verifyAvlInvariants(t, tree)
+	verifyNodePointersEqual(t, Max(tree), nodes[2])
+	verifyNodePointersEqual(t, Min(tree), nodes[0])
+}
+
+func TestFromSorted(t *testing.T) {
+	testFromSorted_Empty(t)
+	testFromSorted_IsBalanced(t)
+	testFromSorted_SupportsUpdates(t)
+	testFromSortedFunc_PriorityKeys(t)
+}
+
+func verifySplit(t *testing.T, n int, key int) {
+	tree := FromSorted(createSortedIntNodes(0, n))
+	less, rest := Split(tree, key)
+	splitAt := min(max(key, 0), n)
+	verifyAvlInvariants(t, less)
+	verifyAvlInvariants(t, rest)
+	verifyIteratedKeys(t, less.All(), keyRange(0, splitAt))
+	verifyIteratedKeys(t, rest.All(), keyRange(splitAt, n))
+}
+
+func testSplit_Nil(t *testing.T) {
+	less, rest := Split[int, string](nil, 5)
+	if less != nil || rest != nil {
+		t.Errorf("Split(nil, 5) == (%v, %v), expected (nil, nil)", less, rest)
+	}
+}
+
+func testSplit_EveryPosition(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 100} {
+		for key := -1; key <= n+1; key++ {
+			verifySplit(t, n, key)
+		}
+	}
+}
+
+func testSplit_Duplicates(t *testing.T) {
+	tree := createIntTree(5, 5, 5, 1, 9, 5)
+	less, rest := Split(tree, 5)
+	verifyIteratedKeys(t, less.All(), []int{1})
+	verifyIteratedKeys(t, rest.All(), []int{5, 5, 5, 5, 9})
+}
+
+func testSplit_ResultsAreUsable(t *testing.T) {
+	less, rest := Split(FromSorted(createSortedIntNodes(0, 20)), 10)
+	Insert(&less, NewAvlNode(100, ""))
+	Insert(&rest, NewAvlNode(-100, ""))
+	verifyAvlInvariants(t, less)
+	verifyAvlInvariants(t, rest)
+	verifySizeVal(t, less, 11)
+	verifySizeVal(t, rest, 11)
+}
+
+func TestSplit(t *testing.T) {
+	testSplit_Nil(t)
+	testSplit_EveryPosition(t)
+	testSplit_Duplicates(t)
+	testSplit_ResultsAreUsable(t)
+}
+
+func testJoin_UnevenHeights(t *testing.T) {
+	for _, sizes := range [][2]int{{0, 0}, {0, 50}, {50, 0}, {1, 1000}, {1000, 1}, {300, 700}, {64, 63}} {
+		left := FromSorted(createSortedIntNodes(0, sizes[0]))
+		right := FromSorted(createSortedIntNodes(sizes[0]+1, sizes[0]+1+sizes[1]))
+		joined := Join(left, NewAvlNode(sizes[0], ""), right)
+		verifyAvlInvariants(t, joined)
+		verifySizeVal(t, joined, sizes[0]+sizes[1]+1)
+		verifyIteratedKeys(t, joined.All(), keyRange(0, sizes[0]+sizes[1]+1))
+	}
+}
+
+func testJoin_NilPivot(t *testing.T) {
+	for _, sizes := range [][2]int{{0, 0}, {0, 50}, {50, 0}, {1, 1000}, {1000, 1}, {300, 700}} {
+		left := FromSorted(createSortedIntNodes(0, sizes[0]))
+		right := FromSorted(createSortedIntNodes(sizes[0], sizes[0]+sizes[1]))
+		joined := Join(left, nil, right)
+		verifyAvlInvariants(t, joined)
+		verifyIteratedKeys(t, joined.All(), keyRange(0, sizes[0]+sizes[1]))
+	}
+}
+
+func testJoin_InsertedTrees(t *testing.T) {
+	left := createIntTree(3, 1, 2, 0)
+	right := createIntTree()
+	for key := 100; key > 5; key-- {
+		Insert(&right, NewAvlNode(key, ""))
+	}
+	joined := Join(left, NewAvlNode(4, ""), right)
+	verifyAvlInvariants(t, joined)
+	verifySizeVal(t, joined, 100)
+}
+
+func testJoin_SplitRoundTrip(t *testing.T) {
+	tree := FromSorted(createSortedIntNodes(0, 200))
+	for _, key := range []int{0, 1, 57, 100, 199, 200} {
+		less, rest := Split(tree, key)
+		tree = Join(less, nil, rest)
+		verifyAvlInvariants(t, tree)
+		verifyIteratedKeys(t, tree.All(), keyRange(0, 200))
+	}
+}
+
+func TestJoin(t *testing.T) {
+	testJoin_UnevenHeights(t)
+	testJoin_NilPivot(t)
+	testJoin_InsertedTrees(t)
+	testJoin_SplitRoundTrip(t)
+}
@@ -0,0 +1,158 @@
+package avlTree
+
+import (
+	"cmp"
+	"sync"
+)
+
+//AvlTree guarded by an RWMutex, safe for use by multiple goroutines.
+//Reads hold the read lock so that any number of them may run at once,
+//while Insert, Remove, RemoveMax and UpdateNode hold the write lock.
+type ConcurrentAvlTree[K, V any] struct {
+	mutex sync.RWMutex
+	tree  *AvlTree[K, V]
+}
+
+//Creates an empty concurrent AVL tree ordered by the natural ordering of K
+func NewConcurrentAvlTree[K cmp.Ordered, V any]() *ConcurrentAvlTree[K, V] {
+	return &ConcurrentAvlTree[K, V]{tree: NewAvlTree[K, V]()}
+}
+
+//Creates an empty concurrent AVL tree ordered by compare
+func NewConcurrentAvlTreeFunc[K, V any](compare func(a, b K) int) *ConcurrentAvlTree[K, V] {
+	return &ConcurrentAvlTree[K, V]{tree: NewAvlTreeFunc[K, V](compare)}
+}
+
+//Inserts node and rebalances the tree
+func (ctree *ConcurrentAvlTree[K, V]) Insert(node *AvlNode[K, V]) {
+	ctree.mutex.Lock()
+	defer ctree.mutex.Unlock()
+	Insert(&ctree.tree, node)
+}
+
+//Removes node and rebalances the tree
+func (ctree *ConcurrentAvlTree[K, V]) Remove(node *AvlNode[K, V]) {
+	ctree.mutex.Lock()
+	defer ctree.mutex.Unlock()
+	Remove(&ctree.tree, node)
+}
+
+//Removes the max node and rebalances the tree
+func (ctree *ConcurrentAvlTree[K, V]) RemoveMax() {
+	ctree.mutex.Lock()
+	defer ctree.mutex.Unlock()
+	RemoveMax(&ctree.tree)
+}
+
+//Replaces node with newNode as a single atomic update, returns false if the tree does not contain node
+func (ctree *ConcurrentAvlTree[K, V]) UpdateNode(node *AvlNode[K, V], newNode *AvlNode[K, V]) bool {
+	ctree.mutex.Lock()
+	defer ctree.mutex.Unlock()
+	return UpdateNode(&ctree.tree, node, newNode)
+}
+
+//Sets the observer notified of changes to the tree, as in SetObserver.
+//Callbacks run on the writing goroutine while it holds the write lock.
+func (ctree *ConcurrentAvlTree[K, V]) SetObserver(observer *TreeObserver[K, V]) {
+	ctree.mutex.Lock()
+	defer ctree.mutex.Unlock()
+	SetObserver(ctree.tree, observer)
+}
+
+//Returns max element in the tree
+func (ctree *ConcurrentAvlTree[K, V]) Max() *AvlNode[K, V] {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Max(ctree.tree)
+}
+
+//Returns min element in the tree
+func (ctree *ConcurrentAvlTree[K, V]) Min() *AvlNode[K, V] {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Min(ctree.tree)
+}
+
+//Returns true iff the tree contains a node with the same key as node
+func (ctree *ConcurrentAvlTree[K, V]) Has(node *AvlNode[K, V]) bool {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Has(ctree.tree, node)
+}
+
+//Returns the node with the greatest key <= node's key, or nil if there is none
+func (ctree *ConcurrentAvlTree[K, V]) Floor(node *AvlNode[K, V]) *AvlNode[K, V] {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Floor(ctree.tree, node)
+}
+
+//Returns the node with the least key >= node's key, or nil if there is none
+func (ctree *ConcurrentAvlTree[K, V]) Ceiling(node *AvlNode[K, V]) *AvlNode[K, V] {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Ceiling(ctree.tree, node)
+}
+
+//Returns the node with the greatest key < node's key, or nil if there is none
+func (ctree *ConcurrentAvlTree[K, V]) Predecessor(node *AvlNode[K, V]) *AvlNode[K, V] {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Predecessor(ctree.tree, node)
+}
+
+//Returns the node with the least key > node's key, or nil if there is none
+func (ctree *ConcurrentAvlTree[K, V]) Successor(node *AvlNode[K, V]) *AvlNode[K, V] {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Successor(ctree.tree, node)
+}
+
+//Returns the number of nodes in the tree
+func (ctree *ConcurrentAvlTree[K, V]) Size() int {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Size(ctree.tree)
+}
+
+//Returns the node at index k in ascending key order, or nil if k is out of range
+func (ctree *ConcurrentAvlTree[K, V]) Select(k int) *AvlNode[K, V] {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Select(ctree.tree, k)
+}
+
+//Returns the number of nodes with keys less than node's key
+func (ctree *ConcurrentAvlTree[K, V]) Rank(node *AvlNode[K, V]) int {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return Rank(ctree.tree, node)
+}
+
+//Returns the number of nodes with lo <= key <= hi
+func (ctree *ConcurrentAvlTree[K, V]) CountRange(lo K, hi K) int {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return CountRange(ctree.tree, lo, hi)
+}
+
+//Returns a copy of the tree's current structure.  The copy is taken under the
+//read lock, after which it can be read or iterated while writers proceed on
+//the original.  Nodes are shared with the original tree and must not be modified.
+func (ctree *ConcurrentAvlTree[K, V]) Snapshot() *AvlTree[K, V] {
+	ctree.mutex.RLock()
+	defer ctree.mutex.RUnlock()
+	return cloneTree(ctree.tree)
+}
+
+//Copies the structure of tree without its observer, sharing its nodes
+func cloneTree[K, V any](tree *AvlTree[K, V]) *AvlTree[K, V] {
+	if tree == nil {
+		return nil
+	}
+	clone := *tree
+	clone.observer = nil
+	clone.left = cloneTree(tree.left)
+	clone.right = cloneTree(tree.right)
+	return &clone
+}
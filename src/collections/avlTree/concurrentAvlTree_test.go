@@ -0,0 +1,187 @@
+package avlTree
+
+//These tests are intended to be run with the race detector: go test -race
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+const (
+	numWriters       = 16
+	numReaders       = 16
+	insertsPerWriter = 200
+)
+
+func testConcurrentTree_ParallelInserts(t *testing.T) {
+	ctree := NewConcurrentAvlTree[int, string]()
+	var wg sync.WaitGroup
+	for writer := 0; writer < numWriters; writer++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for i := 0; i < insertsPerWriter; i++ {
+				ctree.Insert(NewAvlNode(writer*insertsPerWriter+i, "value"))
+			}
+		}(writer)
+	}
+	wg.Wait()
+
+	expectedSize := numWriters * insertsPerWriter
+	if size := ctree.Size(); size != expectedSize {
+		t.Errorf("Size() == %d, expected %d", size, expectedSize)
+	}
+	snapshot := ctree.Snapshot()
+	verifySubtreeSizes(t, snapshot)
+	keys := collectKeys(snapshot.All())
+	if len(keys) != expectedSize || !slices.IsSorted(keys) {
+		t.Errorf("snapshot holds %d keys, sorted == %t, expected %d sorted keys", len(keys), slices.IsSorted(keys), expectedSize)
+	}
+}
+
+func testConcurrentTree_ReadersAndWriters(t *testing.T) {
+	ctree := NewConcurrentAvlTree[int, string]()
+	for key := 0; key < insertsPerWriter; key++ {
+		ctree.Insert(NewAvlNode(-key-1, "permanent"))
+	}
+
+	var wg sync.WaitGroup
+	for writer := 0; writer < numWriters; writer++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for i := 0; i < insertsPerWriter; i++ {
+				node := NewAvlNode(writer*insertsPerWriter+i, "temporary")
+				ctree.Insert(node)
+				if i%2 == 0 {
+					ctree.Remove(node)
+				} else {
+					ctree.UpdateNode(node, NewAvlNode(node.Key, "updated"))
+				}
+			}
+		}(writer)
+	}
+	for reader := 0; reader < numReaders; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < insertsPerWriter; i++ {
+				if min := ctree.Min(); min == nil || min.Key != -insertsPerWriter {
+					t.Errorf("Min() == %v, expected key %d", min, -insertsPerWriter)
+					return
+				}
+				if !ctree.Has(NewAvlNode(-1, "")) {
+					t.Errorf("Has(-1) == false, expected true")
+					return
+				}
+				if count := ctree.CountRange(-insertsPerWriter, -1); count != insertsPerWriter {
+					t.Errorf("CountRange() == %d, expected %d", count, insertsPerWriter)
+					return
+				}
+				ctree.Max()
+				ctree.Select(i)
+				ctree.Rank(NewAvlNode(i, ""))
+				ctree.Floor(NewAvlNode(i, ""))
+				ctree.Ceiling(NewAvlNode(i, ""))
+				ctree.Predecessor(NewAvlNode(i, ""))
+				ctree.Successor(NewAvlNode(i, ""))
+			}
+		}()
+	}
+	wg.Wait()
+
+	expectedSize := insertsPerWriter + numWriters*insertsPerWriter/2
+	if size := ctree.Size(); size != expectedSize {
+		t.Errorf("Size() == %d, expected %d", size, expectedSize)
+	}
+}
+
+func testConcurrentTree_SnapshotUnaffectedByWriters(t *testing.T) {
+	ctree := NewConcurrentAvlTree[int, string]()
+	for key := 0; key < insertsPerWriter; key++ {
+		ctree.Insert(NewAvlNode(key, ""))
+	}
+	snapshot := ctree.Snapshot()
+	expectedKeys := collectKeys(snapshot.All())
+
+	var wg sync.WaitGroup
+	for writer := 0; writer < numWriters; writer++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for i := 0; i < insertsPerWriter; i++ {
+				ctree.Insert(NewAvlNode(insertsPerWriter+writer*insertsPerWriter+i, ""))
+				ctree.RemoveMax()
+				ctree.Remove(NewAvlNode(i, ""))
+			}
+		}(writer)
+	}
+	for reader := 0; reader < numReaders; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keys := collectKeys(snapshot.All())
+			if !slices.Equal(keys, expectedKeys) {
+				t.Errorf("snapshot changed while writers ran, holds %d keys, expected %d", len(keys), len(expectedKeys))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if size := Size(snapshot); size != insertsPerWriter {
+		t.Errorf("Size(snapshot) == %d, expected %d", size, insertsPerWriter)
+	}
+}
+
+func testConcurrentTree_Comparator(t *testing.T) {
+	ctree := NewConcurrentAvlTreeFunc[PriorityKey, struct{}](ComparePriorityKeys)
+	ctree.Insert(createAvlNode("low", 1))
+	ctree.Insert(createAvlNode("high", 9))
+	if max := ctree.Max(); max == nil || max.Key.Data != "high" {
+		t.Errorf("Max() == %v, expected high", max)
+	}
+	ctree.RemoveMax()
+	if max := ctree.Max(); max == nil || max.Key.Data != "low" {
+		t.Errorf("Max() == %v, expected low", max)
+	}
+}
+
+func testConcurrentTree_Observer(t *testing.T) {
+	var inserts, removes int
+	ctree := NewConcurrentAvlTree[int, string]()
+	ctree.SetObserver(&TreeObserver[int, string]{
+		OnInsert: func(node *AvlNode[int, string]) { inserts++ },
+		OnRemove: func(node *AvlNode[int, string]) { removes++ },
+	})
+	var wg sync.WaitGroup
+	for writer := 0; writer < numWriters; writer++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for i := 0; i < insertsPerWriter; i++ {
+				ctree.Insert(NewAvlNode(writer*insertsPerWriter+i, "value"))
+			}
+			ctree.RemoveMax()
+		}(writer)
+	}
+	wg.Wait()
+
+	if inserts != numWriters*insertsPerWriter || removes != numWriters {
+		t.Errorf("observed %d inserts and %d removes, expected %d and %d",
+			inserts, removes, numWriters*insertsPerWriter, numWriters)
+	}
+	snapshot := ctree.Snapshot()
+	Insert(&snapshot, NewAvlNode(-1, "value"))
+	if inserts != numWriters*insertsPerWriter {
+		t.Errorf("inserting into a snapshot notified the tree's observer")
+	}
+}
+
+func TestConcurrentAvlTree(t *testing.T) {
+	testConcurrentTree_ParallelInserts(t)
+	testConcurrentTree_ReadersAndWriters(t)
+	testConcurrentTree_SnapshotUnaffectedByWriters(t)
+	testConcurrentTree_Comparator(t)
+	testConcurrentTree_Observer(t)
+}
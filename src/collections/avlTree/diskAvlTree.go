@@ -0,0 +1,729 @@
+package avlTree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"iter"
+	"maps"
+	"os"
+	"slices"
+)
+
+const (
+	diskPageSize      = 128
+	diskFormatVersion = 1
+	//Offset of a key's data within a node page, after its flag, height,
+	//children, priority and data length
+	diskNodeDataOffset = 20
+	//Longest PriorityKey.Data which fits in a node page, leaving room for its checksum
+	DiskMaxDataLen = diskPageSize - diskNodeDataOffset - 4
+	//Checkpoint once this many pages have changed since the last one
+	diskMaxDirtyPages = 4096
+	//Size of a log record's length and checksum fields
+	diskRecordHeaderSize = 8
+	//Size of one page image in a log record, following its page id
+	diskRecordPageSize = 4 + diskPageSize
+)
+
+//Kinds of page, stored in each page's first byte
+const (
+	diskPageFree = 0
+	diskPageNode = 1
+)
+
+var diskMagic = []byte{'A', 'V', 'L', 'D'}
+
+//AVL tree of PriorityKeys kept in a file of fixed-size pages, one node per
+//page, with page 0 holding a header.  Only the pages on the path of each
+//update are read, so the tree need not fit in memory.
+//
+//Each mutation is first appended to a write-ahead log at <path>.wal as one
+//record holding the new images of every page it changed.  Changed pages are
+//kept in memory and only written to the file at a checkpoint, which Sync and
+//Close perform, as does any mutation leaving too many changed pages.  A
+//checkpoint syncs the log, writes the pages, syncs the file and then empties
+//the log.  Open replays every complete record left in the log, so after a
+//crash the tree holds every mutation up to the last one fully logged, and
+//never part of a mutation.  Mutations since the last Sync survive a crash of
+//the process, but need Sync to survive a crash of the machine.
+//
+//As with AvlTree, equal keys may be inserted more than once.  A DiskAvlTree
+//is not safe for concurrent use, and a file must be opened by only one
+//DiskAvlTree at a time.  After an I/O error, every call returns that error;
+//reopen the file to recover from the log.
+type DiskAvlTree struct {
+	file    *os.File
+	wal     *os.File
+	walSize int64
+	header  diskHeader
+	//Images of pages changed since the last checkpoint, by page id
+	dirty map[uint32][]byte
+	//Images of pages changed by the mutation in progress
+	pending map[uint32][]byte
+	err     error
+}
+
+//Contents of page 0
+type diskHeader struct {
+	root      uint32
+	freeHead  uint32
+	pageCount uint32
+	size      uint64
+}
+
+//Contents of a node page.  Page ids start at 1, so 0 stands for a nil child.
+type diskNode struct {
+	id     uint32
+	left   uint32
+	right  uint32
+	height int
+	key    PriorityKey
+}
+
+//Opens the tree stored at path, creating it if it does not exist, and
+//recovers any mutations left in its write-ahead log
+func Open(path string) (*DiskAvlTree, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("avlTree: opening %s: %v", path, err)
+	}
+	wal, err := os.OpenFile(path+".wal", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("avlTree: opening %s.wal: %v", path, err)
+	}
+	dtree := &DiskAvlTree{file: file, wal: wal, dirty: map[uint32][]byte{}}
+	if err := dtree.recover(); err != nil {
+		file.Close()
+		wal.Close()
+		return nil, err
+	}
+	if err := dtree.loadHeader(); err != nil {
+		file.Close()
+		wal.Close()
+		return nil, err
+	}
+	return dtree, nil
+}
+
+//Checkpoints the tree and closes its files
+func (dtree *DiskAvlTree) Close() error {
+	if dtree.file == nil {
+		return fmt.Errorf("avlTree: tree already closed")
+	}
+	err := dtree.Sync()
+	if closeErr := dtree.wal.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("avlTree: closing log: %v", closeErr)
+	}
+	if closeErr := dtree.file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("avlTree: closing file: %v", closeErr)
+	}
+	dtree.file, dtree.wal = nil, nil
+	if dtree.err == nil {
+		dtree.err = fmt.Errorf("avlTree: tree is closed")
+	}
+	return err
+}
+
+//Makes every mutation so far durable by checkpointing the tree
+func (dtree *DiskAvlTree) Sync() error {
+	if dtree.err != nil {
+		return dtree.err
+	}
+	return dtree.checkpoint()
+}
+
+//Returns the first I/O error the tree has hit, including any which ended an iteration early
+func (dtree *DiskAvlTree) Err() error {
+	return dtree.err
+}
+
+//Returns the number of keys in the tree
+func (dtree *DiskAvlTree) Len() int {
+	return int(dtree.header.size)
+}
+
+//Inserts key, which fails if key.Data is longer than DiskMaxDataLen bytes
+func (dtree *DiskAvlTree) Insert(key PriorityKey) error {
+	if len(key.Data) > DiskMaxDataLen {
+		return fmt.Errorf("avlTree: data %q is longer than %d bytes", key.Data, DiskMaxDataLen)
+	}
+	return dtree.mutate(func() error {
+		return dtree.insertKey(key)
+	})
+}
+
+//Removes one node with key, if there is one
+func (dtree *DiskAvlTree) Remove(key PriorityKey) error {
+	return dtree.mutate(func() error {
+		_, err := dtree.removeKey(key)
+		return err
+	})
+}
+
+//Removes one node with the greatest key, if the tree is not empty
+func (dtree *DiskAvlTree) RemoveMax() error {
+	return dtree.mutate(func() error {
+		if dtree.header.root == 0 {
+			return nil
+		}
+		root, _, err := dtree.removeMax(dtree.header.root)
+		dtree.header.root = root
+		dtree.header.size--
+		return err
+	})
+}
+
+//Replaces key with newKey as a single mutation.  Returns false and leaves
+//the tree unchanged if the tree does not contain key.
+func (dtree *DiskAvlTree) UpdateNode(key PriorityKey, newKey PriorityKey) (bool, error) {
+	if len(newKey.Data) > DiskMaxDataLen {
+		return false, fmt.Errorf("avlTree: data %q is longer than %d bytes", newKey.Data, DiskMaxDataLen)
+	}
+	updated := false
+	err := dtree.mutate(func() error {
+		removed, err := dtree.removeKey(key)
+		if err != nil || !removed {
+			return err
+		}
+		updated = true
+		return dtree.insertKey(newKey)
+	})
+	return updated && err == nil, err
+}
+
+//Returns true iff the tree contains key
+func (dtree *DiskAvlTree) Has(key PriorityKey) (bool, error) {
+	if dtree.err != nil {
+		return false, dtree.err
+	}
+	for id := dtree.header.root; id != 0; {
+		node, err := dtree.readNode(id)
+		if err != nil {
+			return false, err
+		}
+		rootToKeyCompare := node.key.Compare(key)
+		if rootToKeyCompare == 0 {
+			return true, nil
+		}
+		if rootToKeyCompare > 0 {
+			id = node.left
+		} else {
+			id = node.right
+		}
+	}
+	return false, nil
+}
+
+//Returns the greatest key, or nil if the tree is empty
+func (dtree *DiskAvlTree) Max() (*PriorityKey, error) {
+	return dtree.extreme(func(node diskNode) uint32 { return node.right })
+}
+
+//Returns the least key, or nil if the tree is empty
+func (dtree *DiskAvlTree) Min() (*PriorityKey, error) {
+	return dtree.extreme(func(node diskNode) uint32 { return node.left })
+}
+
+//Returns an iterator over the keys in ascending order.  The iteration ends
+//early on an I/O error, which Err then returns.
+func (dtree *DiskAvlTree) All() iter.Seq[PriorityKey] {
+	return func(yield func(PriorityKey) bool) {
+		if dtree.err == nil {
+			dtree.walkAscending(dtree.header.root, yield)
+		}
+	}
+}
+
+//Runs op as one logged mutation.  If op fails, its changes are discarded.
+func (dtree *DiskAvlTree) mutate(op func() error) error {
+	if dtree.err != nil {
+		return dtree.err
+	}
+	savedHeader := dtree.header
+	dtree.pending = map[uint32][]byte{}
+	defer func() {
+		dtree.pending = nil
+	}()
+	err := op()
+	if err == nil {
+		//Reads which cannot return an error still record one in dtree.err
+		err = dtree.err
+	}
+	if err != nil {
+		dtree.header = savedHeader
+		return err
+	}
+	if len(dtree.pending) == 0 {
+		return nil
+	}
+	dtree.pending[0] = encodeDiskHeader(dtree.header)
+	if err := dtree.appendLog(dtree.pending); err != nil {
+		//The log may now end in a partial record, after which nothing could be recovered
+		dtree.header = savedHeader
+		dtree.err = err
+		return err
+	}
+	maps.Copy(dtree.dirty, dtree.pending)
+	if len(dtree.dirty) >= diskMaxDirtyPages {
+		return dtree.checkpoint()
+	}
+	return nil
+}
+
+func (dtree *DiskAvlTree) insertKey(key PriorityKey) error {
+	root, err := dtree.insert(dtree.header.root, key)
+	if err != nil {
+		return err
+	}
+	dtree.header.root = root
+	dtree.header.size++
+	return nil
+}
+
+//Removes one node with key, returns false if there is none
+func (dtree *DiskAvlTree) removeKey(key PriorityKey) (bool, error) {
+	root, removed, err := dtree.remove(dtree.header.root, key)
+	if err != nil || !removed {
+		return false, err
+	}
+	dtree.header.root = root
+	dtree.header.size--
+	return true, nil
+}
+
+//Inserts key below the subtree at id, returns the id of the rebalanced subtree
+func (dtree *DiskAvlTree) insert(id uint32, key PriorityKey) (uint32, error) {
+	if id == 0 {
+		leafId, err := dtree.allocPage()
+		if err != nil {
+			return 0, err
+		}
+		dtree.writeNode(diskNode{id: leafId, key: key})
+		return leafId, nil
+	}
+	node, err := dtree.readNode(id)
+	if err != nil {
+		return 0, err
+	}
+	if node.key.Compare(key) >= 0 {
+		node.left, err = dtree.insert(node.left, key)
+	} else {
+		node.right, err = dtree.insert(node.right, key)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return dtree.rebalance(node)
+}
+
+//Removes one node with key from the subtree at id.  Returns the id of the
+//rebalanced subtree and whether a node was removed.
+func (dtree *DiskAvlTree) remove(id uint32, key PriorityKey) (uint32, bool, error) {
+	if id == 0 {
+		return 0, false, nil
+	}
+	node, err := dtree.readNode(id)
+	if err != nil {
+		return 0, false, err
+	}
+	removed := true
+	rootToKeyCompare := node.key.Compare(key)
+	if rootToKeyCompare > 0 {
+		node.left, removed, err = dtree.remove(node.left, key)
+	} else if rootToKeyCompare < 0 {
+		node.right, removed, err = dtree.remove(node.right, key)
+	} else if node.left == 0 {
+		dtree.freePage(id)
+		return node.right, true, nil
+	} else if node.right == 0 {
+		dtree.freePage(id)
+		return node.left, true, nil
+	} else {
+		node.left, node.key, err = dtree.removeMax(node.left)
+	}
+	if err != nil || !removed {
+		return id, false, err
+	}
+	root, err := dtree.rebalance(node)
+	return root, true, err
+}
+
+//Removes the max node from the non-empty subtree at id.  Returns the id of
+//the rebalanced subtree and the removed key.
+func (dtree *DiskAvlTree) removeMax(id uint32) (uint32, PriorityKey, error) {
+	node, err := dtree.readNode(id)
+	if err != nil {
+		return 0, PriorityKey{}, err
+	}
+	if node.right == 0 {
+		dtree.freePage(id)
+		return node.left, node.key, nil
+	}
+	var maxKey PriorityKey
+	node.right, maxKey, err = dtree.removeMax(node.right)
+	if err != nil {
+		return 0, PriorityKey{}, err
+	}
+	root, err := dtree.rebalance(node)
+	return root, maxKey, err
+}
+
+//Writes node with its height updated, rotating as in balanceRoot if it is
+//out of balance.  Returns the id of the subtree's new root.
+func (dtree *DiskAvlTree) rebalance(node diskNode) (uint32, error) {
+	left, leftHeight, err := dtree.readChild(node.left)
+	if err != nil {
+		return 0, err
+	}
+	right, rightHeight, err := dtree.readChild(node.right)
+	if err != nil {
+		return 0, err
+	}
+	if leftHeight-rightHeight > 1 {
+		_, leftLeftHeight, err := dtree.readChild(left.left)
+		if err != nil {
+			return 0, err
+		}
+		leftRight, leftRightHeight, err := dtree.readChild(left.right)
+		if err != nil {
+			return 0, err
+		}
+		if leftLeftHeight < leftRightHeight {
+			left = dtree.rotateRight(left, leftRight)
+		}
+		return dtree.rotateLeft(node, left).id, nil
+	}
+	if rightHeight-leftHeight > 1 {
+		rightLeft, rightLeftHeight, err := dtree.readChild(right.left)
+		if err != nil {
+			return 0, err
+		}
+		_, rightRightHeight, err := dtree.readChild(right.right)
+		if err != nil {
+			return 0, err
+		}
+		if rightRightHeight < rightLeftHeight {
+			right = dtree.rotateLeft(right, rightLeft)
+		}
+		return dtree.rotateRight(node, right).id, nil
+	}
+	node.height = max(leftHeight, rightHeight) + 1
+	dtree.writeNode(node)
+	return node.id, nil
+}
+
+//Moves node's left child left up to node's place, as in rotateLeftToRoot.
+//Returns the new subtree root.
+func (dtree *DiskAvlTree) rotateLeft(node diskNode, left diskNode) diskNode {
+	node.left = left.right
+	node.height = dtree.heightFromChildren(node)
+	left.right = node.id
+	left.height = max(dtree.childHeight(left.left), node.height) + 1
+	dtree.writeNode(node)
+	dtree.writeNode(left)
+	return left
+}
+
+//Moves node's right child right up to node's place, as in rotateRightToRoot.
+//Returns the new subtree root.
+func (dtree *DiskAvlTree) rotateRight(node diskNode, right diskNode) diskNode {
+	node.right = right.left
+	node.height = dtree.heightFromChildren(node)
+	right.left = node.id
+	right.height = max(node.height, dtree.childHeight(right.right)) + 1
+	dtree.writeNode(node)
+	dtree.writeNode(right)
+	return right
+}
+
+func (dtree *DiskAvlTree) heightFromChildren(node diskNode) int {
+	return max(dtree.childHeight(node.left), dtree.childHeight(node.right)) + 1
+}
+
+//Returns the height of the subtree at id.  A failed read is left for mutate
+//to find in dtree.err.
+func (dtree *DiskAvlTree) childHeight(id uint32) int {
+	_, height, _ := dtree.readChild(id)
+	return height
+}
+
+//Returns the node at id and its height, or a height of -1 if id is 0
+func (dtree *DiskAvlTree) readChild(id uint32) (diskNode, int, error) {
+	if id == 0 {
+		return diskNode{}, -1, nil
+	}
+	node, err := dtree.readNode(id)
+	return node, node.height, err
+}
+
+func (dtree *DiskAvlTree) extreme(child func(diskNode) uint32) (*PriorityKey, error) {
+	if dtree.err != nil {
+		return nil, dtree.err
+	}
+	if dtree.header.root == 0 {
+		return nil, nil
+	}
+	node, err := dtree.readNode(dtree.header.root)
+	for err == nil && child(node) != 0 {
+		node, err = dtree.readNode(child(node))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &node.key, nil
+}
+
+//In-order traversal, returns false iff yield requested a stop or a page could not be read
+func (dtree *DiskAvlTree) walkAscending(id uint32, yield func(PriorityKey) bool) bool {
+	if id == 0 {
+		return true
+	}
+	node, err := dtree.readNode(id)
+	if err != nil {
+		return false
+	}
+	return dtree.walkAscending(node.left, yield) && yield(node.key) && dtree.walkAscending(node.right, yield)
+}
+
+//Returns a free page id, reusing the head of the free list if there is one
+func (dtree *DiskAvlTree) allocPage() (uint32, error) {
+	id := dtree.header.freeHead
+	if id == 0 {
+		id = dtree.header.pageCount
+		dtree.header.pageCount++
+		return id, nil
+	}
+	page, err := dtree.readPage(id)
+	if err != nil {
+		return 0, err
+	}
+	if page[0] != diskPageFree {
+		return 0, dtree.fail(fmt.Errorf("avlTree: page %d on the free list is in use", id))
+	}
+	dtree.header.freeHead = binary.LittleEndian.Uint32(page[2:])
+	return id, nil
+}
+
+//Pushes id onto the free list
+func (dtree *DiskAvlTree) freePage(id uint32) {
+	page := make([]byte, diskPageSize)
+	page[0] = diskPageFree
+	binary.LittleEndian.PutUint32(page[2:], dtree.header.freeHead)
+	sealDiskPage(page)
+	dtree.pending[id] = page
+	dtree.header.freeHead = id
+}
+
+func (dtree *DiskAvlTree) readNode(id uint32) (diskNode, error) {
+	page, err := dtree.readPage(id)
+	if err != nil {
+		return diskNode{}, err
+	}
+	if page[0] != diskPageNode {
+		return diskNode{}, dtree.fail(fmt.Errorf("avlTree: page %d is not a node", id))
+	}
+	dataLen := int(binary.LittleEndian.Uint16(page[18:]))
+	if dataLen > DiskMaxDataLen {
+		return diskNode{}, dtree.fail(fmt.Errorf("avlTree: page %d has data length %d", id, dataLen))
+	}
+	return diskNode{
+		id:     id,
+		height: int(page[1]) - 1,
+		left:   binary.LittleEndian.Uint32(page[2:]),
+		right:  binary.LittleEndian.Uint32(page[6:]),
+		key: PriorityKey{
+			Priority: int(int64(binary.LittleEndian.Uint64(page[10:]))),
+			Data:     string(page[diskNodeDataOffset : diskNodeDataOffset+dataLen]),
+		},
+	}, nil
+}
+
+func (dtree *DiskAvlTree) writeNode(node diskNode) {
+	page := make([]byte, diskPageSize)
+	page[0] = diskPageNode
+	page[1] = byte(node.height + 1)
+	binary.LittleEndian.PutUint32(page[2:], node.left)
+	binary.LittleEndian.PutUint32(page[6:], node.right)
+	binary.LittleEndian.PutUint64(page[10:], uint64(int64(node.key.Priority)))
+	binary.LittleEndian.PutUint16(page[18:], uint16(len(node.key.Data)))
+	copy(page[diskNodeDataOffset:], node.key.Data)
+	sealDiskPage(page)
+	dtree.pending[node.id] = page
+}
+
+//Returns the latest image of page id: from the mutation in progress, else
+//from the changes since the last checkpoint, else from the file
+func (dtree *DiskAvlTree) readPage(id uint32) ([]byte, error) {
+	if page, ok := dtree.pending[id]; ok {
+		return page, nil
+	}
+	if page, ok := dtree.dirty[id]; ok {
+		return page, nil
+	}
+	if id == 0 || id >= dtree.header.pageCount {
+		return nil, dtree.fail(fmt.Errorf("avlTree: page %d is out of range", id))
+	}
+	page := make([]byte, diskPageSize)
+	if _, err := dtree.file.ReadAt(page, int64(id)*diskPageSize); err != nil {
+		return nil, dtree.fail(fmt.Errorf("avlTree: reading page %d: %v", id, err))
+	}
+	if !checkDiskPage(page) {
+		return nil, dtree.fail(fmt.Errorf("avlTree: page %d is corrupt", id))
+	}
+	return page, nil
+}
+
+//Appends one record holding pages, in page id order, to the log
+func (dtree *DiskAvlTree) appendLog(pages map[uint32][]byte) error {
+	record := make([]byte, diskRecordHeaderSize, diskRecordHeaderSize+len(pages)*diskRecordPageSize)
+	for _, id := range slices.Sorted(maps.Keys(pages)) {
+		record = binary.LittleEndian.AppendUint32(record, id)
+		record = append(record, pages[id]...)
+	}
+	payload := record[diskRecordHeaderSize:]
+	binary.LittleEndian.PutUint32(record[0:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:], crc32.ChecksumIEEE(payload))
+	if _, err := dtree.wal.WriteAt(record, dtree.walSize); err != nil {
+		return fmt.Errorf("avlTree: appending to log: %v", err)
+	}
+	dtree.walSize += int64(len(record))
+	return nil
+}
+
+//Writes every page changed since the last checkpoint to the file, then empties the log
+func (dtree *DiskAvlTree) checkpoint() error {
+	if err := dtree.wal.Sync(); err != nil {
+		return dtree.fail(fmt.Errorf("avlTree: syncing log: %v", err))
+	}
+	for id, page := range dtree.dirty {
+		if _, err := dtree.file.WriteAt(page, int64(id)*diskPageSize); err != nil {
+			return dtree.fail(fmt.Errorf("avlTree: writing page %d: %v", id, err))
+		}
+	}
+	if err := dtree.file.Sync(); err != nil {
+		return dtree.fail(fmt.Errorf("avlTree: syncing file: %v", err))
+	}
+	if err := dtree.truncateLog(); err != nil {
+		return dtree.fail(err)
+	}
+	clear(dtree.dirty)
+	return nil
+}
+
+//Writes the pages of each complete record in the log to the file, stopping at
+//the first record cut short or corrupted by a crash, then empties the log.
+//Rewriting a page with the same image is harmless, so it does not matter
+//whether a record was already written by a checkpoint interrupted by the crash.
+func (dtree *DiskAvlTree) recover() error {
+	info, err := dtree.wal.Stat()
+	if err != nil {
+		return fmt.Errorf("avlTree: reading log: %v", err)
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+	log := make([]byte, info.Size())
+	if _, err := dtree.wal.ReadAt(log, 0); err != nil {
+		return fmt.Errorf("avlTree: reading log: %v", err)
+	}
+	for len(log) >= diskRecordHeaderSize {
+		payloadLen := int(binary.LittleEndian.Uint32(log[0:]))
+		if payloadLen == 0 || payloadLen%diskRecordPageSize != 0 || diskRecordHeaderSize+payloadLen > len(log) {
+			break
+		}
+		payload := log[diskRecordHeaderSize : diskRecordHeaderSize+payloadLen]
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(log[4:]) {
+			break
+		}
+		for ; len(payload) > 0; payload = payload[diskRecordPageSize:] {
+			id := binary.LittleEndian.Uint32(payload)
+			if _, err := dtree.file.WriteAt(payload[4:diskRecordPageSize], int64(id)*diskPageSize); err != nil {
+				return fmt.Errorf("avlTree: replaying page %d: %v", id, err)
+			}
+		}
+		log = log[diskRecordHeaderSize+payloadLen:]
+	}
+	if err := dtree.file.Sync(); err != nil {
+		return fmt.Errorf("avlTree: syncing file: %v", err)
+	}
+	return dtree.truncateLog()
+}
+
+//Empties the log, syncing so that a crash cannot bring back stale records behind new ones
+func (dtree *DiskAvlTree) truncateLog() error {
+	if err := dtree.wal.Truncate(0); err != nil {
+		return fmt.Errorf("avlTree: truncating log: %v", err)
+	}
+	if err := dtree.wal.Sync(); err != nil {
+		return fmt.Errorf("avlTree: syncing log: %v", err)
+	}
+	dtree.walSize = 0
+	return nil
+}
+
+//Reads the header from page 0, writing a new one if the file is empty
+func (dtree *DiskAvlTree) loadHeader() error {
+	page := make([]byte, diskPageSize)
+	n, err := dtree.file.ReadAt(page, 0)
+	if n == 0 {
+		dtree.header = diskHeader{pageCount: 1}
+		if _, err := dtree.file.WriteAt(encodeDiskHeader(dtree.header), 0); err != nil {
+			return fmt.Errorf("avlTree: writing header: %v", err)
+		}
+		if err := dtree.file.Sync(); err != nil {
+			return fmt.Errorf("avlTree: syncing file: %v", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("avlTree: reading header: %v", err)
+	}
+	if !slices.Equal(page[:4], diskMagic) || !checkDiskPage(page) {
+		return fmt.Errorf("avlTree: %s is not a tree file, or its header is corrupt", dtree.file.Name())
+	}
+	if version := binary.LittleEndian.Uint16(page[4:]); version != diskFormatVersion {
+		return fmt.Errorf("avlTree: unsupported file version %d", version)
+	}
+	if pageSize := binary.LittleEndian.Uint16(page[6:]); pageSize != diskPageSize {
+		return fmt.Errorf("avlTree: unsupported page size %d", pageSize)
+	}
+	dtree.header = diskHeader{
+		root:      binary.LittleEndian.Uint32(page[8:]),
+		freeHead:  binary.LittleEndian.Uint32(page[12:]),
+		pageCount: binary.LittleEndian.Uint32(page[16:]),
+		size:      binary.LittleEndian.Uint64(page[20:]),
+	}
+	return nil
+}
+
+func encodeDiskHeader(header diskHeader) []byte {
+	page := make([]byte, diskPageSize)
+	copy(page, diskMagic)
+	binary.LittleEndian.PutUint16(page[4:], diskFormatVersion)
+	binary.LittleEndian.PutUint16(page[6:], diskPageSize)
+	binary.LittleEndian.PutUint32(page[8:], header.root)
+	binary.LittleEndian.PutUint32(page[12:], header.freeHead)
+	binary.LittleEndian.PutUint32(page[16:], header.pageCount)
+	binary.LittleEndian.PutUint64(page[20:], header.size)
+	sealDiskPage(page)
+	return page
+}
+
+//Stores the checksum of page's contents in its last 4 bytes
+func sealDiskPage(page []byte) {
+	binary.LittleEndian.PutUint32(page[diskPageSize-4:], crc32.ChecksumIEEE(page[:diskPageSize-4]))
+}
+
+func checkDiskPage(page []byte) bool {
+	return binary.LittleEndian.Uint32(page[diskPageSize-4:]) == crc32.ChecksumIEEE(page[:diskPageSize-4])
+}
+
+//Records err as the tree's sticky error and returns it
+func (dtree *DiskAvlTree) fail(err error) error {
+	if dtree.err == nil {
+		dtree.err = err
+	}
+	return err
+}
@@ -0,0 +1,468 @@
+package avlTree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type diskOpKind int
+
+const (
+	diskOpInsert diskOpKind = iota
+	diskOpRemove
+	diskOpRemoveMax
+	diskOpUpdate
+)
+
+type diskOp struct {
+	kind   diskOpKind
+	key    PriorityKey
+	newKey PriorityKey
+}
+
+func openDiskTree(t *testing.T, path string) *DiskAvlTree {
+	dtree, err := Open(path)
+	if err != nil {
+		debug.PrintStack()
+		t.Fatalf("Open(%s) == %v", path, err)
+	}
+	return dtree
+}
+
+//Closes dtree's files without a checkpoint, as if the process had been killed
+func crashDiskTree(dtree *DiskAvlTree) {
+	dtree.wal.Close()
+	dtree.file.Close()
+}
+
+func createDiskKey(rng *rand.Rand) PriorityKey {
+	priority := rng.Intn(100)
+	return PriorityKey{fmt.Sprintf("key%d", priority%7), priority}
+}
+
+//Returns n random operations, mostly inserts so that the tree grows
+func createDiskOps(rng *rand.Rand, n int) []diskOp {
+	ops := make([]diskOp, n)
+	for i := range ops {
+		switch roll := rng.Intn(10); {
+		case roll < 6:
+			ops[i] = diskOp{kind: diskOpInsert, key: createDiskKey(rng)}
+		case roll < 8:
+			ops[i] = diskOp{kind: diskOpRemove, key: createDiskKey(rng)}
+		case roll < 9:
+			ops[i] = diskOp{kind: diskOpRemoveMax}
+		default:
+			ops[i] = diskOp{kind: diskOpUpdate, key: createDiskKey(rng), newKey: createDiskKey(rng)}
+		}
+	}
+	return ops
+}
+
+func applyDiskOp(t *testing.T, dtree *DiskAvlTree, op diskOp) {
+	var err error
+	switch op.kind {
+	case diskOpInsert:
+		err = dtree.Insert(op.key)
+	case diskOpRemove:
+		err = dtree.Remove(op.key)
+	case diskOpRemoveMax:
+		err = dtree.RemoveMax()
+	case diskOpUpdate:
+		_, err = dtree.UpdateNode(op.key, op.newKey)
+	}
+	if err != nil {
+		debug.PrintStack()
+		t.Fatalf("applying %+v == %v", op, err)
+	}
+}
+
+//Returns keys, a sorted slice, after applying op to it
+func applyDiskOpToModel(keys []PriorityKey, op diskOp) []PriorityKey {
+	keys = slices.Clone(keys)
+	remove := func(key PriorityKey) bool {
+		index, found := slices.BinarySearchFunc(keys, key, ComparePriorityKeys)
+		if found {
+			keys = slices.Delete(keys, index, index+1)
+		}
+		return found
+	}
+	insert := func(key PriorityKey) {
+		index, _ := slices.BinarySearchFunc(keys, key, ComparePriorityKeys)
+		keys = slices.Insert(keys, index, key)
+	}
+	switch op.kind {
+	case diskOpInsert:
+		insert(op.key)
+	case diskOpRemove:
+		remove(op.key)
+	case diskOpRemoveMax:
+		if len(keys) > 0 {
+			keys = keys[:len(keys)-1]
+		}
+	case diskOpUpdate:
+		if remove(op.key) {
+			insert(op.newKey)
+		}
+	}
+	return keys
+}
+
+//Checks ordering, cached heights and balance of every node, and that each
+//page is either reachable from the root or on the free list
+func validateDiskTree(dtree *DiskAvlTree) error {
+	var lastKey *PriorityKey
+	nodeCount := 0
+	var walk func(id uint32) (int, error)
+	walk = func(id uint32) (int, error) {
+		if id == 0 {
+			return -1, nil
+		}
+		node, err := dtree.readNode(id)
+		if err != nil {
+			return 0, err
+		}
+		leftHeight, err := walk(node.left)
+		if err != nil {
+			return 0, err
+		}
+		if lastKey != nil && lastKey.Compare(node.key) > 0 {
+			return 0, fmt.Errorf("node %v on page %d is ordered after greater node %v", node.key, id, *lastKey)
+		}
+		lastKey = &node.key
+		nodeCount++
+		rightHeight, err := walk(node.right)
+		if err != nil {
+			return 0, err
+		}
+		if node.height != max(leftHeight, rightHeight)+1 {
+			return 0, fmt.Errorf("page %d has cached height %d, expected %d", id, node.height, max(leftHeight, rightHeight)+1)
+		}
+		if balanceFactor := leftHeight - rightHeight; balanceFactor < -1 || balanceFactor > 1 {
+			return 0, fmt.Errorf("page %d has balance factor %d", id, balanceFactor)
+		}
+		return node.height, nil
+	}
+	if _, err := walk(dtree.header.root); err != nil {
+		return err
+	}
+	if nodeCount != dtree.Len() {
+		return fmt.Errorf("tree has %d nodes, expected %d", nodeCount, dtree.Len())
+	}
+	freeCount := 0
+	for id := dtree.header.freeHead; id != 0; freeCount++ {
+		page, err := dtree.readPage(id)
+		if err != nil {
+			return err
+		}
+		if page[0] != diskPageFree {
+			return fmt.Errorf("page %d on the free list is in use", id)
+		}
+		id = binary.LittleEndian.Uint32(page[2:])
+	}
+	if pages := int(dtree.header.pageCount) - 1; nodeCount+freeCount != pages {
+		return fmt.Errorf("%d nodes and %d free pages, expected %d pages", nodeCount, freeCount, pages)
+	}
+	return nil
+}
+
+func verifyDiskKeys(t *testing.T, dtree *DiskAvlTree, expectedKeys []PriorityKey) {
+	keys := slices.Collect(dtree.All())
+	if err := dtree.Err(); err != nil {
+		debug.PrintStack()
+		t.Fatalf("All() stopped with %v", err)
+	}
+	if !slices.Equal(keys, expectedKeys) {
+		debug.PrintStack()
+		t.Errorf("All() == %v, expected %v", keys, expectedKeys)
+	}
+	if dtree.Len() != len(expectedKeys) {
+		debug.PrintStack()
+		t.Errorf("Len() == %d, expected %d", dtree.Len(), len(expectedKeys))
+	}
+	if err := validateDiskTree(dtree); err != nil {
+		debug.PrintStack()
+		t.Errorf("validateDiskTree(dtree) == %v", err)
+	}
+}
+
+func verifyDiskErr(t *testing.T, err error, expectedErrMsg string) {
+	if err == nil || !strings.Contains(err.Error(), expectedErrMsg) {
+		debug.PrintStack()
+		t.Errorf("err == %v, expected error containing %q", err, expectedErrMsg)
+	}
+}
+
+func testDiskTree_Empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	dtree := openDiskTree(t, path)
+	verifyDiskKeys(t, dtree, nil)
+	if max, err := dtree.Max(); max != nil || err != nil {
+		t.Errorf("Max() == %v, %v, expected nil, nil", max, err)
+	}
+	if err := dtree.RemoveMax(); err != nil {
+		t.Errorf("RemoveMax() == %v, expected nil", err)
+	}
+	if err := dtree.Close(); err != nil {
+		t.Fatalf("Close() == %v", err)
+	}
+	dtree = openDiskTree(t, path)
+	verifyDiskKeys(t, dtree, nil)
+	dtree.Close()
+}
+
+func testDiskTree_ReopenAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	dtree := openDiskTree(t, path)
+	for _, priority := range []int{5, 3, 8, 1, 4, 7, 9, 3} {
+		applyDiskOp(t, dtree, diskOp{kind: diskOpInsert, key: PriorityKey{"a", priority}})
+	}
+	if err := dtree.Close(); err != nil {
+		t.Fatalf("Close() == %v", err)
+	}
+	dtree = openDiskTree(t, path)
+	defer dtree.Close()
+	expectedKeys := []PriorityKey{{"a", 1}, {"a", 3}, {"a", 3}, {"a", 4}, {"a", 5}, {"a", 7}, {"a", 8}, {"a", 9}}
+	verifyDiskKeys(t, dtree, expectedKeys)
+	if min, _ := dtree.Min(); min == nil || *min != expectedKeys[0] {
+		t.Errorf("Min() == %v, expected %v", min, expectedKeys[0])
+	}
+	if max, _ := dtree.Max(); max == nil || *max != expectedKeys[len(expectedKeys)-1] {
+		t.Errorf("Max() == %v, expected %v", max, expectedKeys[len(expectedKeys)-1])
+	}
+	if has, _ := dtree.Has(PriorityKey{"a", 7}); !has {
+		t.Errorf("Has({a 7}) == false, expected true")
+	}
+	if has, _ := dtree.Has(PriorityKey{"b", 7}); has {
+		t.Errorf("Has({b 7}) == true, expected false")
+	}
+}
+
+func testDiskTree_UpdateNode(t *testing.T) {
+	dtree := openDiskTree(t, filepath.Join(t.TempDir(), "index"))
+	defer dtree.Close()
+	applyDiskOp(t, dtree, diskOp{kind: diskOpInsert, key: PriorityKey{"a", 1}})
+	if updated, err := dtree.UpdateNode(PriorityKey{"a", 1}, PriorityKey{"a", 2}); !updated || err != nil {
+		t.Errorf("UpdateNode({a 1}, {a 2}) == %v, %v, expected true, nil", updated, err)
+	}
+	if updated, err := dtree.UpdateNode(PriorityKey{"a", 1}, PriorityKey{"a", 3}); updated || err != nil {
+		t.Errorf("UpdateNode({a 1}, {a 3}) == %v, %v, expected false, nil", updated, err)
+	}
+	verifyDiskKeys(t, dtree, []PriorityKey{{"a", 2}})
+}
+
+func testDiskTree_RandomAgainstModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	rng := rand.New(rand.NewSource(1))
+	dtree := openDiskTree(t, path)
+	var keys []PriorityKey
+	for i, op := range createDiskOps(rng, 3000) {
+		applyDiskOp(t, dtree, op)
+		keys = applyDiskOpToModel(keys, op)
+		if i%500 == 0 {
+			if err := dtree.Sync(); err != nil {
+				t.Fatalf("Sync() == %v", err)
+			}
+			verifyDiskKeys(t, dtree, keys)
+		}
+	}
+	verifyDiskKeys(t, dtree, keys)
+	if err := dtree.Close(); err != nil {
+		t.Fatalf("Close() == %v", err)
+	}
+	dtree = openDiskTree(t, path)
+	defer dtree.Close()
+	verifyDiskKeys(t, dtree, keys)
+}
+
+func testDiskTree_RecoverUnsyncedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	rng := rand.New(rand.NewSource(2))
+	dtree := openDiskTree(t, path)
+	var keys []PriorityKey
+	for i, op := range createDiskOps(rng, 400) {
+		applyDiskOp(t, dtree, op)
+		keys = applyDiskOpToModel(keys, op)
+		if i == 200 {
+			dtree.Sync()
+		}
+	}
+	crashDiskTree(dtree)
+
+	dtree = openDiskTree(t, path)
+	defer dtree.Close()
+	verifyDiskKeys(t, dtree, keys)
+	if info, _ := os.Stat(path + ".wal"); info.Size() != 0 {
+		t.Errorf("log has %d bytes after recovery, expected 0", info.Size())
+	}
+}
+
+//Runs ops from a fresh tree at path, syncing after the first synced ops, then
+//crashes.  Returns the model after each op and the log size after each op.
+func runDiskOpsAndCrash(t *testing.T, path string, ops []diskOp, synced int) ([][]PriorityKey, []int64) {
+	dtree := openDiskTree(t, path)
+	models := [][]PriorityKey{nil}
+	logSizes := []int64{0}
+	for i, op := range ops {
+		applyDiskOp(t, dtree, op)
+		models = append(models, applyDiskOpToModel(models[i], op))
+		if i+1 == synced {
+			dtree.Sync()
+		}
+		logSizes = append(logSizes, dtree.walSize)
+	}
+	crashDiskTree(dtree)
+	return models, logSizes
+}
+
+//Returns the number of ops whose log records all end at or before offset
+func countLoggedOps(logSizes []int64, synced int, offset int64) int {
+	count := synced
+	for count+1 < len(logSizes) && logSizes[count+1] <= offset {
+		count++
+	}
+	return count
+}
+
+func testDiskTree_TruncatedLog(t *testing.T) {
+	const opCount, synced = 300, 100
+	rng := rand.New(rand.NewSource(3))
+	ops := createDiskOps(rng, opCount)
+	for trial := 0; trial < 40; trial++ {
+		path := filepath.Join(t.TempDir(), "index")
+		models, logSizes := runDiskOpsAndCrash(t, path, ops, synced)
+		cut := rng.Int63n(logSizes[opCount] + 1)
+		if err := os.Truncate(path+".wal", cut); err != nil {
+			t.Fatalf("Truncate(%d) == %v", cut, err)
+		}
+
+		dtree := openDiskTree(t, path)
+		verifyDiskKeys(t, dtree, models[countLoggedOps(logSizes, synced, cut)])
+		//The recovered tree must stay usable
+		applyDiskOp(t, dtree, diskOp{kind: diskOpInsert, key: PriorityKey{"after", 50}})
+		if err := dtree.Close(); err != nil {
+			t.Fatalf("Close() == %v", err)
+		}
+	}
+}
+
+func testDiskTree_CorruptLogRecord(t *testing.T) {
+	const opCount, synced = 200, 0
+	rng := rand.New(rand.NewSource(4))
+	ops := createDiskOps(rng, opCount)
+	for trial := 0; trial < 10; trial++ {
+		path := filepath.Join(t.TempDir(), "index")
+		models, logSizes := runDiskOpsAndCrash(t, path, ops, synced)
+		log, _ := os.ReadFile(path + ".wal")
+		offset := rng.Int63n(int64(len(log)))
+		log[offset] ^= 0xff
+		os.WriteFile(path+".wal", log, 0644)
+
+		//Recovery stops before the record holding offset
+		dtree := openDiskTree(t, path)
+		verifyDiskKeys(t, dtree, models[countLoggedOps(logSizes, synced, offset)])
+		dtree.Close()
+	}
+}
+
+func testDiskTree_CrashDuringCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	rng := rand.New(rand.NewSource(5))
+	dtree := openDiskTree(t, path)
+	var keys []PriorityKey
+	for _, op := range createDiskOps(rng, 500) {
+		applyDiskOp(t, dtree, op)
+		keys = applyDiskOpToModel(keys, op)
+	}
+	//Write only some of the changed pages, as a checkpoint would before a crash
+	written := 0
+	for id, page := range dtree.dirty {
+		if written%2 == 0 {
+			dtree.file.WriteAt(page, int64(id)*diskPageSize)
+		}
+		written++
+	}
+	crashDiskTree(dtree)
+
+	dtree = openDiskTree(t, path)
+	defer dtree.Close()
+	verifyDiskKeys(t, dtree, keys)
+}
+
+func testDiskTree_AutomaticCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	dtree := openDiskTree(t, path)
+	var keys []PriorityKey
+	for i := 0; i < diskMaxDirtyPages+100; i++ {
+		key := PriorityKey{"k", i}
+		applyDiskOp(t, dtree, diskOp{kind: diskOpInsert, key: key})
+		keys = append(keys, key)
+	}
+	if len(dtree.dirty) >= diskMaxDirtyPages {
+		t.Errorf("%d dirty pages, expected fewer than %d", len(dtree.dirty), diskMaxDirtyPages)
+	}
+	crashDiskTree(dtree)
+
+	dtree = openDiskTree(t, path)
+	defer dtree.Close()
+	verifyDiskKeys(t, dtree, keys)
+}
+
+func testDiskTree_FreePagesReused(t *testing.T) {
+	dtree := openDiskTree(t, filepath.Join(t.TempDir(), "index"))
+	defer dtree.Close()
+	for i := 0; i < 100; i++ {
+		applyDiskOp(t, dtree, diskOp{kind: diskOpInsert, key: PriorityKey{"k", i}})
+	}
+	pageCount := dtree.header.pageCount
+	for i := 0; i < 100; i++ {
+		applyDiskOp(t, dtree, diskOp{kind: diskOpRemoveMax})
+	}
+	for i := 0; i < 100; i++ {
+		applyDiskOp(t, dtree, diskOp{kind: diskOpInsert, key: PriorityKey{"k", -i}})
+	}
+	if dtree.header.pageCount != pageCount {
+		t.Errorf("pageCount == %d after reinserting, expected %d", dtree.header.pageCount, pageCount)
+	}
+	if err := validateDiskTree(dtree); err != nil {
+		t.Errorf("validateDiskTree(dtree) == %v", err)
+	}
+}
+
+func testDiskTree_Errors(t *testing.T) {
+	dir := t.TempDir()
+	dtree := openDiskTree(t, filepath.Join(dir, "index"))
+	verifyDiskErr(t, dtree.Insert(PriorityKey{strings.Repeat("x", DiskMaxDataLen+1), 1}), "longer than")
+	if err := dtree.Insert(PriorityKey{strings.Repeat("x", DiskMaxDataLen), 1}); err != nil {
+		t.Errorf("Insert(longest key) == %v, expected nil", err)
+	}
+	dtree.Close()
+	verifyDiskErr(t, dtree.Insert(PriorityKey{"a", 1}), "closed")
+	verifyDiskErr(t, dtree.Sync(), "closed")
+	verifyDiskErr(t, dtree.Close(), "already closed")
+
+	notTree := filepath.Join(dir, "notTree")
+	os.WriteFile(notTree, []byte(strings.Repeat("not a tree", 20)), 0644)
+	_, err := Open(notTree)
+	verifyDiskErr(t, err, "not a tree file")
+}
+
+func TestDiskAvlTree(t *testing.T) {
+	testDiskTree_Empty(t)
+	testDiskTree_ReopenAfterClose(t)
+	testDiskTree_UpdateNode(t)
+	testDiskTree_RandomAgainstModel(t)
+	testDiskTree_RecoverUnsyncedLog(t)
+	testDiskTree_TruncatedLog(t)
+	testDiskTree_CorruptLogRecord(t)
+	testDiskTree_CrashDuringCheckpoint(t)
+	testDiskTree_AutomaticCheckpoint(t)
+	testDiskTree_FreePagesReused(t)
+	testDiskTree_Errors(t)
+}
@@ -0,0 +1,103 @@
+package avlTree
+
+import (
+	"cmp"
+	"iter"
+)
+
+//Closed interval [Lo, Hi]
+type Interval[T cmp.Ordered] struct {
+	Lo T
+	Hi T
+}
+
+//Returns true iff interval and [lo, hi] share at least one point
+func (interval Interval[T]) Overlaps(lo T, hi T) bool {
+	return interval.Lo <= hi && lo <= interval.Hi
+}
+
+//Orders intervals by Lo, then by Hi
+func CompareIntervals[T cmp.Ordered](first Interval[T], second Interval[T]) int {
+	if loCompare := cmp.Compare(first.Lo, second.Lo); loCompare != 0 {
+		return loCompare
+	}
+	return cmp.Compare(first.Hi, second.Hi)
+}
+
+//AVL tree of intervals ordered by CompareIntervals, where each subtree's
+//summary is the node with the greatest Hi endpoint in that subtree.  The
+//summary is kept up to date by the same rotations and height updates that
+//rebalance the tree, so overlap queries can skip any subtree ending before
+//the query starts.
+type IntervalTree[T cmp.Ordered, V any] struct {
+	tree *AvlTree[Interval[T], V]
+}
+
+//Creates an empty interval tree
+func NewIntervalTree[T cmp.Ordered, V any]() *IntervalTree[T, V] {
+	tree := NewAvlTreeFunc[Interval[T], V](CompareIntervals[T])
+	tree.augment = updateMaxEndpoint[T, V]
+	return &IntervalTree[T, V]{tree}
+}
+
+//Inserts [lo, hi] with value and returns its node, or nil if lo > hi
+func (itree *IntervalTree[T, V]) Insert(lo T, hi T, value V) *AvlNode[Interval[T], V] {
+	if lo > hi {
+		return nil
+	}
+	node := NewAvlNode(Interval[T]{lo, hi}, value)
+	Insert(&itree.tree, node)
+	return node
+}
+
+//Removes a node with the same interval as node
+func (itree *IntervalTree[T, V]) Remove(node *AvlNode[Interval[T], V]) {
+	Remove(&itree.tree, node)
+}
+
+//Returns the number of intervals in the tree
+func (itree *IntervalTree[T, V]) Len() int {
+	return Size(itree.tree)
+}
+
+//Returns an iterator over the nodes whose intervals overlap [lo, hi], in ascending interval order
+func (itree *IntervalTree[T, V]) Overlapping(lo T, hi T) iter.Seq[*AvlNode[Interval[T], V]] {
+	return func(yield func(*AvlNode[Interval[T], V]) bool) {
+		if lo <= hi {
+			walkOverlapping(itree.tree, lo, hi, yield)
+		}
+	}
+}
+
+//Returns an iterator over the nodes whose intervals contain point, in ascending interval order
+func (itree *IntervalTree[T, V]) Stabbing(point T) iter.Seq[*AvlNode[Interval[T], V]] {
+	return itree.Overlapping(point, point)
+}
+
+//Sets tree's summary to the node with the greatest Hi among tree's root and its children's summaries
+func updateMaxEndpoint[T cmp.Ordered, V any](tree *AvlTree[Interval[T], V]) {
+	tree.summary = tree.root
+	for _, child := range []*AvlTree[Interval[T], V]{tree.left, tree.right} {
+		if !child.isEmpty() && child.summary.Key.Hi > tree.summary.Key.Hi {
+			tree.summary = child.summary
+		}
+	}
+}
+
+//In-order traversal pruned by subtree max endpoints, returns false iff yield requested a stop
+func walkOverlapping[T cmp.Ordered, V any](tree *AvlTree[Interval[T], V], lo T, hi T, yield func(*AvlNode[Interval[T], V]) bool) bool {
+	if tree.isEmpty() || tree.summary.Key.Hi < lo {
+		return true
+	}
+	if !walkOverlapping(tree.left, lo, hi, yield) {
+		return false
+	}
+	if tree.root.Key.Lo > hi {
+		//Every interval in the right subtree starts at or after this one
+		return true
+	}
+	if tree.root.Key.Overlaps(lo, hi) && !yield(tree.root) {
+		return false
+	}
+	return walkOverlapping(tree.right, lo, hi, yield)
+}
@@ -0,0 +1,141 @@
+package avlTree
+
+import (
+	"iter"
+	"math/rand"
+	"runtime/debug"
+	"slices"
+	"testing"
+)
+
+func collectIntervals(seq iter.Seq[*AvlNode[Interval[int], string]]) []Interval[int] {
+	intervals := []Interval[int]{}
+	for node := range seq {
+		intervals = append(intervals, node.Key)
+	}
+	return intervals
+}
+
+func verifyIntervals(t *testing.T, intervals []Interval[int], expected []Interval[int]) {
+	if !slices.Equal(intervals, expected) {
+		t.Errorf("intervals == %v, expected %v", intervals, expected)
+		debug.PrintStack()
+	}
+}
+
+//Verifies that every subtree's summary holds the greatest Hi endpoint in that subtree, returns that endpoint
+func verifyMaxEndpoints(t *testing.T, tree *AvlTree[Interval[int], string]) int {
+	if tree.isEmpty() {
+		return -1 << 31
+	}
+	maxHi := max(tree.root.Key.Hi, verifyMaxEndpoints(t, tree.left), verifyMaxEndpoints(t, tree.right))
+	if tree.summary == nil || tree.summary.Key.Hi != maxHi {
+		t.Errorf("summary == %v at node %v, expected max endpoint %d", tree.summary, tree.root, maxHi)
+		debug.PrintStack()
+	}
+	return maxHi
+}
+
+//Returns the intervals overlapping [lo, hi] by scanning every interval
+func bruteForceOverlapping(intervals []Interval[int], lo int, hi int) []Interval[int] {
+	overlapping := []Interval[int]{}
+	for _, interval := range intervals {
+		if interval.Overlaps(lo, hi) {
+			overlapping = append(overlapping, interval)
+		}
+	}
+	slices.SortFunc(overlapping, CompareIntervals[int])
+	return overlapping
+}
+
+func testIntervalTree_Empty(t *testing.T) {
+	itree := NewIntervalTree[int, string]()
+	verifyIntervals(t, collectIntervals(itree.Overlapping(0, 100)), []Interval[int]{})
+	verifyIntervals(t, collectIntervals(itree.Stabbing(5)), []Interval[int]{})
+	if itree.Len() != 0 {
+		t.Errorf("Len() == %d, expected 0", itree.Len())
+	}
+}
+
+func testIntervalTree_InvalidInterval(t *testing.T) {
+	itree := NewIntervalTree[int, string]()
+	if node := itree.Insert(5, 1, "backwards"); node != nil {
+		t.Errorf("Insert(5, 1) == %v, expected nil", node)
+	}
+	itree.Insert(1, 5, "forwards")
+	verifyIntervals(t, collectIntervals(itree.Overlapping(4, 2)), []Interval[int]{})
+}
+
+func testIntervalTree_Reservations(t *testing.T) {
+	itree := NewIntervalTree[int, string]()
+	itree.Insert(900, 1000, "standup")
+	itree.Insert(1000, 1200, "design review")
+	itree.Insert(1300, 1400, "lunch")
+	itree.Insert(800, 1700, "on call")
+	itree.Insert(1500, 1530, "1:1")
+
+	verifyIntervals(t, collectIntervals(itree.Overlapping(1130, 1330)),
+		[]Interval[int]{{800, 1700}, {1000, 1200}, {1300, 1400}})
+	verifyIntervals(t, collectIntervals(itree.Stabbing(1000)),
+		[]Interval[int]{{800, 1700}, {900, 1000}, {1000, 1200}})
+	verifyIntervals(t, collectIntervals(itree.Stabbing(1750)), []Interval[int]{})
+	verifyMaxEndpoints(t, itree.tree)
+}
+
+func testIntervalTree_StopsEarly(t *testing.T) {
+	itree := NewIntervalTree[int, string]()
+	for lo := 0; lo < 10; lo++ {
+		itree.Insert(lo, lo+10, "")
+	}
+	count := 0
+	for range itree.Stabbing(9) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 {
+		t.Errorf("iterated %d intervals, expected to stop at 3", count)
+	}
+}
+
+func testIntervalTree_RandomAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	itree := NewIntervalTree[int, string]()
+	intervals := []Interval[int]{}
+	nodes := []*AvlNode[Interval[int], string]{}
+	for i := 0; i < 500; i++ {
+		lo := rng.Intn(1000)
+		hi := lo + rng.Intn(50)
+		nodes = append(nodes, itree.Insert(lo, hi, ""))
+		intervals = append(intervals, Interval[int]{lo, hi})
+	}
+	for i := 0; i < 200; i++ {
+		index := rng.Intn(len(nodes))
+		itree.Remove(nodes[index])
+		nodes = slices.Delete(nodes, index, index+1)
+		intervals = slices.Delete(intervals, index, index+1)
+	}
+	if err := Validate(itree.tree); err != nil {
+		t.Errorf("Validate(itree.tree) == %v", err)
+	}
+	verifyMaxEndpoints(t, itree.tree)
+	if itree.Len() != len(intervals) {
+		t.Errorf("Len() == %d, expected %d", itree.Len(), len(intervals))
+	}
+
+	for i := 0; i < 200; i++ {
+		lo := rng.Intn(1100) - 50
+		hi := lo + rng.Intn(30)
+		verifyIntervals(t, collectIntervals(itree.Overlapping(lo, hi)), bruteForceOverlapping(intervals, lo, hi))
+		verifyIntervals(t, collectIntervals(itree.Stabbing(lo)), bruteForceOverlapping(intervals, lo, lo))
+	}
+}
+
+func TestIntervalTree(t *testing.T) {
+	testIntervalTree_Empty(t)
+	testIntervalTree_InvalidInterval(t)
+	testIntervalTree_Reservations(t)
+	testIntervalTree_StopsEarly(t)
+	testIntervalTree_RandomAgainstBruteForce(t)
+}
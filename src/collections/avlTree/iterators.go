@@ -0,0 +1,64 @@
+package avlTree
+
+import (
+	"iter"
+)
+
+//Returns an iterator over the nodes of tree in ascending key order
+func (tree *AvlTree[K, V]) All() iter.Seq[*AvlNode[K, V]] {
+	return func(yield func(*AvlNode[K, V]) bool) {
+		walkAscending(tree, yield)
+	}
+}
+
+//Returns an iterator over the nodes of tree in descending key order
+func (tree *AvlTree[K, V]) Backward() iter.Seq[*AvlNode[K, V]] {
+	return func(yield func(*AvlNode[K, V]) bool) {
+		walkDescending(tree, yield)
+	}
+}
+
+//Returns an iterator over the nodes of tree with lo <= key <= hi, in ascending key order
+func (tree *AvlTree[K, V]) Range(lo K, hi K) iter.Seq[*AvlNode[K, V]] {
+	return func(yield func(*AvlNode[K, V]) bool) {
+		if tree.isEmpty() || tree.comparator()(lo, hi) > 0 {
+			return
+		}
+		walkRange(tree, lo, hi, yield)
+	}
+}
+
+//In-order traversal, returns false iff yield requested a stop
+func walkAscending[K, V any](tree *AvlTree[K, V], yield func(*AvlNode[K, V]) bool) bool {
+	if tree.isEmpty() {
+		return true
+	}
+	return walkAscending(tree.left, yield) && yield(tree.root) && walkAscending(tree.right, yield)
+}
+
+//Reverse in-order traversal, returns false iff yield requested a stop
+func walkDescending[K, V any](tree *AvlTree[K, V], yield func(*AvlNode[K, V]) bool) bool {
+	if tree.isEmpty() {
+		return true
+	}
+	return walkDescending(tree.right, yield) && yield(tree.root) && walkDescending(tree.left, yield)
+}
+
+//In-order traversal skipping subtrees outside [lo, hi], returns false iff yield requested a stop
+func walkRange[K, V any](tree *AvlTree[K, V], lo K, hi K, yield func(*AvlNode[K, V]) bool) bool {
+	if tree.isEmpty() {
+		return true
+	}
+	rootAboveLo := tree.comparator()(tree.root.Key, lo) >= 0
+	rootBelowHi := tree.comparator()(tree.root.Key, hi) <= 0
+	if rootAboveLo && !walkRange(tree.left, lo, hi, yield) {
+		return false
+	}
+	if rootAboveLo && rootBelowHi && !yield(tree.root) {
+		return false
+	}
+	if rootBelowHi {
+		return walkRange(tree.right, lo, hi, yield)
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package avlTree
+
+import (
+	"iter"
+	"runtime/debug"
+	"slices"
+	"testing"
+)
+
+func collectKeys(seq iter.Seq[*AvlNode[int, string]]) []int {
+	keys := []int{}
+	for node := range seq {
+		keys = append(keys, node.Key)
+	}
+	return keys
+}
+
+func verifyIteratedKeys(t *testing.T, seq iter.Seq[*AvlNode[int, string]], expected []int) {
+	keys := collectKeys(seq)
+	if !slices.Equal(keys, expected) {
+		t.Errorf("iterated keys == %v, expected %v", keys, expected)
+		debug.PrintStack()
+	}
+}
+
+func createIntTree(keys ...int) *AvlTree[int, string] {
+	tree := NewAvlTree[int, string]()
+	for _, key := range keys {
+		Insert(&tree, NewAvlNode(key, ""))
+	}
+	return tree
+}
+
+func testTreeAll_EmptyTree(t *testing.T) {
+	verifyIteratedKeys(t, NewAvlTree[int, string]().All(), []int{})
+
+	var nilTree *AvlTree[int, string]
+	verifyIteratedKeys(t, nilTree.All(), []int{})
+}
+
+func testTreeAll_AscendingOrder(t *testing.T) {
+	tree := createIntTree(5, 3, 9, 1, 4, 7, 12, 0)
+	verifyIteratedKeys(t, tree.All(), []int{0, 1, 3, 4, 5, 7, 9, 12})
+}
+
+func testTreeAll_StopsEarly(t *testing.T) {
+	tree := createIntTree(5, 3, 9, 1, 4, 7, 12, 0)
+	keys := []int{}
+	for node := range tree.All() {
+		if node.Key > 4 {
+			break
+		}
+		keys = append(keys, node.Key)
+	}
+	if !slices.Equal(keys, []int{0, 1, 3, 4}) {
+		t.Errorf("iterated keys == %v, expected %v", keys, []int{0, 1, 3, 4})
+	}
+}
+
+func testTreeAll_AfterRebalancing(t *testing.T) {
+	tree := createIntTree()
+	for key := 0; key < 64; key++ {
+		Insert(&tree, NewAvlNode(key, ""))
+	}
+	for key := 0; key < 64; key += 3 {
+		Remove(&tree, NewAvlNode(key, ""))
+	}
+	RemoveMax(&tree)
+	RemoveMax(&tree)
+
+	expected := []int{}
+	for key := 0; key < 61; key++ {
+		if key%3 != 0 {
+			expected = append(expected, key)
+		}
+	}
+	verifyIteratedKeys(t, tree.All(), expected)
+}
+
+func TestTreeAll(t *testing.T) {
+	testTreeAll_EmptyTree(t)
+	testTreeAll_AscendingOrder(t)
+	testTreeAll_StopsEarly(t)
+	testTreeAll_AfterRebalancing(t)
+}
+
+func testTreeBackward_EmptyTree(t *testing.T) {
+	verifyIteratedKeys(t, NewAvlTree[int, string]().Backward(), []int{})
+}
+
+func testTreeBackward_DescendingOrder(t *testing.T) {
+	tree := createIntTree(5, 3, 9, 1, 4, 7, 12, 0)
+	verifyIteratedKeys(t, tree.Backward(), []int{12, 9, 7, 5, 4, 3, 1, 0})
+}
+
+func testTreeBackward_StopsEarly(t *testing.T) {
+	tree := createIntTree(5, 3, 9, 1, 4, 7, 12, 0)
+	keys := []int{}
+	for node := range tree.Backward() {
+		keys = append(keys, node.Key)
+		if len(keys) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(keys, []int{12, 9}) {
+		t.Errorf("iterated keys == %v, expected %v", keys, []int{12, 9})
+	}
+}
+
+func TestTreeBackward(t *testing.T) {
+	testTreeBackward_EmptyTree(t)
+	testTreeBackward_DescendingOrder(t)
+	testTreeBackward_StopsEarly(t)
+}
+
+func testTreeRange_EmptyTree(t *testing.T) {
+	verifyIteratedKeys(t, NewAvlTree[int, string]().Range(0, 10), []int{})
+}
+
+func testTreeRange_InclusiveBounds(t *testing.T) {
+	tree := createIntTree(5, 3, 9, 1, 4, 7, 12, 0)
+	verifyIteratedKeys(t, tree.Range(3, 9), []int{3, 4, 5, 7, 9})
+}
+
+func testTreeRange_BoundsNotInTree(t *testing.T) {
+	tree := createIntTree(5, 3, 9, 1, 4, 7, 12, 0)
+	verifyIteratedKeys(t, tree.Range(2, 8), []int{3, 4, 5, 7})
+	verifyIteratedKeys(t, tree.Range(-5, 0), []int{0})
+	verifyIteratedKeys(t, tree.Range(13, 20), []int{})
+}
+
+func testTreeRange_InvertedBounds(t *testing.T) {
+	tree := createIntTree(5, 3, 9)
+	verifyIteratedKeys(t, tree.Range(9, 3), []int{})
+}
+
+func testTreeRange_PriorityKeys(t *testing.T) {
+	tree := newPriorityTree()
+	Insert(&tree, createAvlNode("b", 2))
+	Insert(&tree, createAvlNode("a", 2))
+	Insert(&tree, createAvlNode("z", 1))
+	Insert(&tree, createAvlNode("c", 3))
+
+	keys := []PriorityKey{}
+	for node := range tree.Range(PriorityKey{"", 2}, PriorityKey{"b", 2}) {
+		keys = append(keys, node.Key)
+	}
+	expected := []PriorityKey{{"a", 2}, {"b", 2}}
+	if !slices.Equal(keys, expected) {
+		t.Errorf("iterated keys == %v, expected %v", keys, expected)
+	}
+}
+
+func TestTreeRange(t *testing.T) {
+	testTreeRange_EmptyTree(t)
+	testTreeRange_InclusiveBounds(t)
+	testTreeRange_BoundsNotInTree(t)
+	testTreeRange_InvertedBounds(t)
+	testTreeRange_PriorityKeys(t)
+}
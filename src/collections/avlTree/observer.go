@@ -0,0 +1,57 @@
+package avlTree
+
+//Callbacks fired as an AvlTree changes, so that side indexes, caches and
+//metrics can follow the tree.  Any of the callbacks may be nil.  They run
+//synchronously once the tree is consistent again, except OnRotate, which
+//runs during rebalancing, and must not modify the tree.
+type TreeObserver[K, V any] struct {
+	//Called by Insert with the inserted node
+	OnInsert func(node *AvlNode[K, V])
+	//Called by Remove and RemoveMax with the node taken out of the tree,
+	//which for Remove may be a different node with the same key
+	OnRemove func(node *AvlNode[K, V])
+	//Called by UpdateNode with the node taken out of the tree and newNode,
+	//instead of OnRemove and OnInsert
+	OnUpdate func(node *AvlNode[K, V], newNode *AvlNode[K, V])
+	//Called after each single rotation with the node that moved down and the
+	//subtree now in its place, rooted at the node that moved up.  A double
+	//rotation calls OnRotate twice.
+	OnRotate func(node *AvlNode[K, V], subtree *AvlTree[K, V])
+}
+
+//Sets the observer notified of changes to tree, or stops notifications if
+//observer is nil.  Takes O(n), as every subtree keeps the observer.  Trees
+//built by FromSorted start without an observer, while Split, Join and the set
+//operations keep the observer of the tree they consumed, see Join.
+func SetObserver[K, V any](tree *AvlTree[K, V], observer *TreeObserver[K, V]) {
+	if tree == nil {
+		return
+	}
+	tree.observer = observer
+	SetObserver(tree.left, observer)
+	SetObserver(tree.right, observer)
+}
+
+func (observer *TreeObserver[K, V]) inserted(node *AvlNode[K, V]) {
+	if observer != nil && observer.OnInsert != nil {
+		observer.OnInsert(node)
+	}
+}
+
+func (observer *TreeObserver[K, V]) removed(node *AvlNode[K, V]) {
+	if observer != nil && observer.OnRemove != nil {
+		observer.OnRemove(node)
+	}
+}
+
+func (observer *TreeObserver[K, V]) updated(node *AvlNode[K, V], newNode *AvlNode[K, V]) {
+	if observer != nil && observer.OnUpdate != nil {
+		observer.OnUpdate(node, newNode)
+	}
+}
+
+func (observer *TreeObserver[K, V]) rotated(node *AvlNode[K, V], subtree *AvlTree[K, V]) {
+	if observer != nil && observer.OnRotate != nil {
+		observer.OnRotate(node, subtree)
+	}
+}
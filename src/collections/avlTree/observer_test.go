@@ -0,0 +1,214 @@
+package avlTree
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"slices"
+	"testing"
+)
+
+//Returns an observer recording each callback as a string in *events
+func createRecordingObserver(events *[]string) *TreeObserver[int, string] {
+	return &TreeObserver[int, string]{
+		OnInsert: func(node *AvlNode[int, string]) {
+			*events = append(*events, fmt.Sprintf("insert %d", node.Key))
+		},
+		OnRemove: func(node *AvlNode[int, string]) {
+			*events = append(*events, fmt.Sprintf("remove %d", node.Key))
+		},
+		OnUpdate: func(node *AvlNode[int, string], newNode *AvlNode[int, string]) {
+			*events = append(*events, fmt.Sprintf("update %d %d", node.Key, newNode.Key))
+		},
+		OnRotate: func(node *AvlNode[int, string], subtree *AvlTree[int, string]) {
+			*events = append(*events, fmt.Sprintf("rotate %d under %d", node.Key, subtree.root.Key))
+		},
+	}
+}
+
+func verifyEvents(t *testing.T, events []string, expected ...string) {
+	if !slices.Equal(events, expected) {
+		t.Errorf("events == %q, expected %q", events, expected)
+		debug.PrintStack()
+	}
+}
+
+func testObserver_InsertAndRotate(t *testing.T) {
+	events := []string{}
+	tree := NewAvlTree[int, string]()
+	SetObserver(tree, createRecordingObserver(&events))
+	for _, key := range []int{1, 2, 3, 5, 4} {
+		Insert(&tree, NewAvlNode(key, ""))
+	}
+	verifyEvents(t, events,
+		"insert 1", "insert 2",
+		"rotate 1 under 2", "insert 3",
+		"insert 5",
+		"rotate 5 under 4", "rotate 3 under 4", "insert 4")
+}
+
+func testObserver_RemoveReportsTreeNode(t *testing.T) {
+	var removedNode *AvlNode[int, string]
+	tree := createIntTree(1, 2, 3)
+	SetObserver(tree, &TreeObserver[int, string]{
+		OnRemove: func(node *AvlNode[int, string]) { removedNode = node },
+	})
+	inTree := Min(tree)
+	Remove(&tree, NewAvlNode(1, ""))
+	if removedNode != inTree {
+		t.Errorf("OnRemove got %p, expected the node from the tree %p", removedNode, inTree)
+	}
+
+	removedNode = nil
+	Remove(&tree, NewAvlNode(7, ""))
+	if removedNode != nil {
+		t.Errorf("OnRemove got %v when removing a missing key", removedNode)
+	}
+}
+
+func testObserver_RemoveMax(t *testing.T) {
+	events := []string{}
+	tree := createIntTree(1, 2, 3, 4)
+	SetObserver(tree, createRecordingObserver(&events))
+	RemoveMax(&tree)
+	RemoveMax(&tree)
+	verifyEvents(t, events, "remove 4", "remove 3")
+}
+
+func testObserver_UpdateNode(t *testing.T) {
+	events := []string{}
+	tree := createIntTree(1, 2, 3)
+	SetObserver(tree, createRecordingObserver(&events))
+	UpdateNode(&tree, NewAvlNode(1, ""), NewAvlNode(9, ""))
+	UpdateNode(&tree, NewAvlNode(1, ""), NewAvlNode(8, ""))
+	verifyEvents(t, events, "rotate 2 under 3", "update 1 9")
+}
+
+//Subtrees created before SetObserver, then promoted to the top of the tree by
+//Remove, must still notify
+func testObserver_SetOnExistingTree(t *testing.T) {
+	events := []string{}
+	tree := createIntTree(2, 1, 3, 4)
+	SetObserver(tree, createRecordingObserver(&events))
+	Remove(&tree, NewAvlNode(1, ""))
+	Remove(&tree, NewAvlNode(2, ""))
+	Insert(&tree, NewAvlNode(5, ""))
+	verifyEvents(t, events, "rotate 2 under 3", "remove 1", "remove 2", "rotate 3 under 4", "insert 5")
+
+	events = []string{}
+	SetObserver(tree, nil)
+	Insert(&tree, NewAvlNode(6, ""))
+	Remove(&tree, NewAvlNode(3, ""))
+	verifyEvents(t, events)
+}
+
+//Keeps a side index from value to node in sync with the tree through callbacks alone
+func testObserver_SideIndex(t *testing.T) {
+	index := map[string]*AvlNode[int, string]{}
+	tree := NewAvlTree[int, string]()
+	SetObserver(tree, &TreeObserver[int, string]{
+		OnInsert: func(node *AvlNode[int, string]) { index[node.Value] = node },
+		OnRemove: func(node *AvlNode[int, string]) { delete(index, node.Value) },
+		OnUpdate: func(node *AvlNode[int, string], newNode *AvlNode[int, string]) {
+			delete(index, node.Value)
+			index[newNode.Value] = newNode
+		},
+	})
+	rng := rand.New(rand.NewSource(17))
+	for i := 0; i < 1000; i++ {
+		key := rng.Intn(200)
+		switch rng.Intn(4) {
+		case 0, 1:
+			if !Has(tree, NewAvlNode(key, "")) {
+				Insert(&tree, NewAvlNode(key, fmt.Sprint(key)))
+			}
+		case 2:
+			Remove(&tree, NewAvlNode(key, ""))
+		case 3:
+			newKey := 200 + i
+			UpdateNode(&tree, NewAvlNode(key, ""), NewAvlNode(newKey, fmt.Sprint(newKey)))
+		}
+	}
+	if len(index) != Size(tree) {
+		t.Errorf("len(index) == %d, expected %d", len(index), Size(tree))
+	}
+	for node := range tree.All() {
+		if index[node.Value] != node {
+			t.Errorf("index[%q] == %v, expected %v", node.Value, index[node.Value], node)
+		}
+	}
+}
+
+//Verifies every subtree of tree holds observer, so no part of it notifies another
+func verifyObservedBy(t *testing.T, tree *AvlTree[int, string], observer *TreeObserver[int, string]) {
+	subtrees := map[*AvlTree[int, string]]bool{}
+	collectSubtrees(tree, subtrees)
+	for subtree := range subtrees {
+		if subtree.observer != observer {
+			t.Errorf("subtree at %v has observer %p, expected %p", subtree.root, subtree.observer, observer)
+			debug.PrintStack()
+			return
+		}
+	}
+}
+
+func createKeyRange(lo int, hi int) []int {
+	keys := []int{}
+	for key := lo; key < hi; key++ {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func testObserver_KeptBySplit(t *testing.T) {
+	events := []string{}
+	observer := createRecordingObserver(&events)
+	tree := createIntTree(createKeyRange(0, 100)...)
+	SetObserver(tree, observer)
+	less, rest := Split(tree, 50)
+	verifyObservedBy(t, less, observer)
+	verifyObservedBy(t, rest, observer)
+
+	events = events[:0]
+	Insert(&less, NewAvlNode(-1, ""))
+	Insert(&rest, NewAvlNode(100, ""))
+	if !slices.Contains(events, "insert -1") || !slices.Contains(events, "insert 100") {
+		t.Errorf("events == %q, expected inserts into both halves", events)
+	}
+}
+
+func testObserver_JoinKeepsLeftObserver(t *testing.T) {
+	leftEvents, rightEvents := []string{}, []string{}
+	leftObserver, rightObserver := createRecordingObserver(&leftEvents), createRecordingObserver(&rightEvents)
+	left, right := createIntTree(createKeyRange(0, 10)...), createIntTree(createKeyRange(11, 100)...)
+	SetObserver(left, leftObserver)
+	SetObserver(right, rightObserver)
+	joined := Join(left, NewAvlNode(10, ""), right)
+	verifyObservedBy(t, joined, leftObserver)
+
+	//An unobserved left tree stops notifications from all of right
+	left, right = createIntTree(createKeyRange(0, 10)...), createIntTree(createKeyRange(10, 100)...)
+	SetObserver(right, rightObserver)
+	verifyObservedBy(t, Join(left, nil, right), nil)
+}
+
+func testObserver_SetOperationsKeepFirstObserver(t *testing.T) {
+	firstEvents, secondEvents := []string{}, []string{}
+	firstObserver, secondObserver := createRecordingObserver(&firstEvents), createRecordingObserver(&secondEvents)
+	first, second := createIntTree(createKeyRange(0, 60)...), createIntTree(createKeyRange(40, 100)...)
+	SetObserver(first, firstObserver)
+	SetObserver(second, secondObserver)
+	verifyObservedBy(t, Union(first, second), firstObserver)
+}
+
+func TestTreeObserver(t *testing.T) {
+	testObserver_InsertAndRotate(t)
+	testObserver_RemoveReportsTreeNode(t)
+	testObserver_RemoveMax(t)
+	testObserver_UpdateNode(t)
+	testObserver_SetOnExistingTree(t)
+	testObserver_SideIndex(t)
+	testObserver_KeptBySplit(t)
+	testObserver_JoinKeepsLeftObserver(t)
+	testObserver_SetOperationsKeepFirstObserver(t)
+}
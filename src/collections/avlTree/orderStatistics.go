@@ -0,0 +1,49 @@
+package avlTree
+
+//Returns the number of nodes in tree
+func Size[K, V any](tree *AvlTree[K, V]) int {
+	return tree.getSize()
+}
+
+//Returns the node at index k in ascending key order, or nil if k is not in [0, Size(tree))
+func Select[K, V any](tree *AvlTree[K, V], k int) *AvlNode[K, V] {
+	if tree.isEmpty() || k < 0 || k >= tree.getSize() {
+		return nil
+	}
+	leftSize := tree.left.getSize()
+	if k < leftSize {
+		return Select(tree.left, k)
+	}
+	if k == leftSize {
+		return tree.root
+	}
+	return Select(tree.right, k-leftSize-1)
+}
+
+//Returns the number of nodes in tree with keys less than node's key
+func Rank[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V]) int {
+	if tree.isEmpty() || node == nil {
+		return 0
+	}
+	return countBelow(tree, node.Key, false)
+}
+
+//Returns the number of nodes in tree with lo <= key <= hi
+func CountRange[K, V any](tree *AvlTree[K, V], lo K, hi K) int {
+	if tree.isEmpty() || tree.comparator()(lo, hi) > 0 {
+		return 0
+	}
+	return countBelow(tree, hi, true) - countBelow(tree, lo, false)
+}
+
+//Counts nodes with key < bound, or key <= bound if inclusive
+func countBelow[K, V any](tree *AvlTree[K, V], bound K, inclusive bool) int {
+	if tree.isEmpty() {
+		return 0
+	}
+	rootToBoundCompare := tree.comparator()(tree.root.Key, bound)
+	if rootToBoundCompare < 0 || (inclusive && rootToBoundCompare == 0) {
+		return tree.left.getSize() + 1 + countBelow(tree.right, bound, inclusive)
+	}
+	return countBelow(tree.left, bound, inclusive)
+}
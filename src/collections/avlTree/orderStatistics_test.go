@@ -0,0 +1,198 @@
+package avlTree
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+//Verifies that every cached subtree size matches the number of nodes below it
+func verifySubtreeSizes[K, V any](t *testing.T, tree *AvlTree[K, V]) int {
+	if tree.isEmpty() {
+		return 0
+	}
+	count := verifySubtreeSizes(t, tree.left) + verifySubtreeSizes(t, tree.right) + 1
+	if tree.size != count {
+		t.Errorf("tree.size == %d at node %v, expected %d", tree.size, tree.root, count)
+		debug.PrintStack()
+	}
+	return count
+}
+
+func verifySizeVal(t *testing.T, tree *AvlTree[int, string], expected int) {
+	size := Size(tree)
+	if size != expected {
+		t.Errorf("Size(tree) == %d, expected %d", size, expected)
+		debug.PrintStack()
+	}
+	verifySubtreeSizes(t, tree)
+}
+
+func testTreeSize_EmptyTree(t *testing.T) {
+	verifySizeVal(t, nil, 0)
+	verifySizeVal(t, NewAvlTree[int, string](), 0)
+}
+
+func testTreeSize_AfterInsertAndRemove(t *testing.T) {
+	tree := createIntTree()
+	for key := 0; key < 100; key++ {
+		Insert(&tree, NewAvlNode(key, ""))
+	}
+	verifySizeVal(t, tree, 100)
+
+	for key := 0; key < 100; key += 2 {
+		Remove(&tree, NewAvlNode(key, ""))
+	}
+	verifySizeVal(t, tree, 50)
+
+	for i := 0; i < 10; i++ {
+		RemoveMax(&tree)
+	}
+	verifySizeVal(t, tree, 40)
+
+	Remove(&tree, NewAvlNode(1000, ""))
+	verifySizeVal(t, tree, 40)
+}
+
+func testTreeSize_RemoveLastNode(t *testing.T) {
+	tree := createIntTree(1)
+	RemoveMax(&tree)
+	verifySizeVal(t, tree, 0)
+}
+
+func TestTreeSize(t *testing.T) {
+	testTreeSize_EmptyTree(t)
+	testTreeSize_AfterInsertAndRemove(t)
+	testTreeSize_RemoveLastNode(t)
+}
+
+func testTreeSelect_EmptyTree(t *testing.T) {
+	if node := Select(NewAvlTree[int, string](), 0); node != nil {
+		t.Errorf("Select(empty, 0) == %v, expected nil", node)
+	}
+}
+
+func testTreeSelect_EveryIndex(t *testing.T) {
+	tree := createIntTree(50, 20, 80, 10, 30, 70, 90, 60, 5)
+	expected := []int{5, 10, 20, 30, 50, 60, 70, 80, 90}
+	for k, key := range expected {
+		node := Select(tree, k)
+		if node == nil || node.Key != key {
+			t.Errorf("Select(tree, %d) == %v, expected key %d", k, node, key)
+		}
+	}
+}
+
+func testTreeSelect_OutOfRange(t *testing.T) {
+	tree := createIntTree(1, 2, 3)
+	for _, k := range []int{-1, 3, 100} {
+		if node := Select(tree, k); node != nil {
+			t.Errorf("Select(tree, %d) == %v, expected nil", k, node)
+		}
+	}
+}
+
+func testTreeSelect_KthHighest(t *testing.T) {
+	tree := newPriorityTree()
+	Insert(&tree, createAvlNode("carol", 30))
+	Insert(&tree, createAvlNode("alice", 90))
+	Insert(&tree, createAvlNode("dave", 10))
+	Insert(&tree, createAvlNode("bob", 60))
+
+	secondHighest := Select(tree, Size(tree)-2)
+	if secondHighest == nil || secondHighest.Key.Data != "bob" {
+		t.Errorf("Select(tree, Size(tree)-2) == %v, expected bob", secondHighest)
+	}
+}
+
+func TestTreeSelect(t *testing.T) {
+	testTreeSelect_EmptyTree(t)
+	testTreeSelect_EveryIndex(t)
+	testTreeSelect_OutOfRange(t)
+	testTreeSelect_KthHighest(t)
+}
+
+func verifyRankVal(t *testing.T, tree *AvlTree[int, string], key int, expected int) {
+	rank := Rank(tree, NewAvlNode(key, ""))
+	if rank != expected {
+		t.Errorf("Rank(tree, %d) == %d, expected %d", key, rank, expected)
+		debug.PrintStack()
+	}
+}
+
+func testTreeRank_EmptyTree(t *testing.T) {
+	verifyRankVal(t, NewAvlTree[int, string](), 5, 0)
+	tree := createIntTree(1, 2)
+	if rank := Rank(tree, nil); rank != 0 {
+		t.Errorf("Rank(tree, nil) == %d, expected 0", rank)
+	}
+}
+
+func testTreeRank_KeysInTree(t *testing.T) {
+	tree := createIntTree(50, 20, 80, 10, 30, 70, 90, 60, 5)
+	for k, key := range []int{5, 10, 20, 30, 50, 60, 70, 80, 90} {
+		verifyRankVal(t, tree, key, k)
+	}
+}
+
+func testTreeRank_KeysNotInTree(t *testing.T) {
+	tree := createIntTree(50, 20, 80, 10, 30)
+	verifyRankVal(t, tree, 0, 0)
+	verifyRankVal(t, tree, 25, 2)
+	verifyRankVal(t, tree, 100, 5)
+}
+
+func testTreeRank_Duplicates(t *testing.T) {
+	tree := createIntTree(3, 3, 3, 1, 5)
+	verifyRankVal(t, tree, 3, 1)
+	verifyRankVal(t, tree, 4, 4)
+}
+
+func TestTreeRank(t *testing.T) {
+	testTreeRank_EmptyTree(t)
+	testTreeRank_KeysInTree(t)
+	testTreeRank_KeysNotInTree(t)
+	testTreeRank_Duplicates(t)
+}
+
+func verifyCountRangeVal(t *testing.T, tree *AvlTree[int, string], lo int, hi int, expected int) {
+	count := CountRange(tree, lo, hi)
+	if count != expected {
+		t.Errorf("CountRange(tree, %d, %d) == %d, expected %d", lo, hi, count, expected)
+		debug.PrintStack()
+	}
+}
+
+func testTreeCountRange_EmptyTree(t *testing.T) {
+	verifyCountRangeVal(t, NewAvlTree[int, string](), 0, 10, 0)
+}
+
+func testTreeCountRange_Bounds(t *testing.T) {
+	tree := createIntTree(50, 20, 80, 10, 30, 70, 90, 60, 5)
+	verifyCountRangeVal(t, tree, 20, 70, 5)
+	verifyCountRangeVal(t, tree, 21, 69, 3)
+	verifyCountRangeVal(t, tree, 0, 100, 9)
+	verifyCountRangeVal(t, tree, 91, 100, 0)
+	verifyCountRangeVal(t, tree, 50, 50, 1)
+	verifyCountRangeVal(t, tree, 70, 20, 0)
+}
+
+func testTreeCountRange_MatchesRangeIterator(t *testing.T) {
+	tree := createIntTree()
+	for key := 0; key < 200; key += 3 {
+		Insert(&tree, NewAvlNode(key, ""))
+	}
+	for key := 0; key < 200; key += 7 {
+		Remove(&tree, NewAvlNode(key, ""))
+	}
+	for lo := -5; lo < 205; lo += 11 {
+		for hi := lo; hi < 205; hi += 13 {
+			verifyCountRangeVal(t, tree, lo, hi, len(collectKeys(tree.Range(lo, hi))))
+		}
+	}
+}
+
+func TestTreeCountRange(t *testing.T) {
+	testTreeCountRange_EmptyTree(t)
+	testTreeCountRange_Bounds(t)
+	testTreeCountRange_MatchesRangeIterator(t)
+}
@@ -0,0 +1,84 @@
+package avlTree
+
+import (
+	"cmp"
+	"iter"
+)
+
+//Sorted collection of nodes with the operations of AvlTree, implemented by
+//AvlSet, RedBlackTree and Treap so that callers can switch balancing schemes
+//without other changes.  As with AvlTree, nodes with equal keys may be
+//inserted more than once, and Remove removes one node with node's key.
+type OrderedSet[K, V any] interface {
+	//Inserts node, keeping any nodes with an equal key
+	Insert(node *AvlNode[K, V])
+	//Removes one node with the same key as node, if there is one
+	Remove(node *AvlNode[K, V])
+	//Removes one node with the greatest key, if the set is not empty
+	RemoveMax()
+	//Returns true iff the set contains a node with the same key as node
+	Has(node *AvlNode[K, V]) bool
+	//Returns a node with the least key, or nil if the set is empty
+	Min() *AvlNode[K, V]
+	//Returns a node with the greatest key, or nil if the set is empty
+	Max() *AvlNode[K, V]
+	//Replaces node with newNode, returns false if the set does not contain node
+	UpdateNode(node *AvlNode[K, V], newNode *AvlNode[K, V]) bool
+	//Returns the number of nodes in the set
+	Len() int
+	//Returns an iterator over the nodes in ascending key order
+	All() iter.Seq[*AvlNode[K, V]]
+}
+
+//OrderedSet backed by an AvlTree
+type AvlSet[K, V any] struct {
+	tree *AvlTree[K, V]
+}
+
+var _ OrderedSet[int, struct{}] = (*AvlSet[int, struct{}])(nil)
+
+//Creates an empty AVL set ordered by the natural ordering of K
+func NewAvlSet[K cmp.Ordered, V any]() *AvlSet[K, V] {
+	return NewAvlSetFunc[K, V](cmp.Compare[K])
+}
+
+//Creates an empty AVL set ordered by compare
+func NewAvlSetFunc[K, V any](compare func(a, b K) int) *AvlSet[K, V] {
+	return &AvlSet[K, V]{NewAvlTreeFunc[K, V](compare)}
+}
+
+func (set *AvlSet[K, V]) Insert(node *AvlNode[K, V]) {
+	Insert(&set.tree, node)
+}
+
+func (set *AvlSet[K, V]) Remove(node *AvlNode[K, V]) {
+	Remove(&set.tree, node)
+}
+
+func (set *AvlSet[K, V]) RemoveMax() {
+	RemoveMax(&set.tree)
+}
+
+func (set *AvlSet[K, V]) Has(node *AvlNode[K, V]) bool {
+	return Has(set.tree, node)
+}
+
+func (set *AvlSet[K, V]) Min() *AvlNode[K, V] {
+	return Min(set.tree)
+}
+
+func (set *AvlSet[K, V]) Max() *AvlNode[K, V] {
+	return Max(set.tree)
+}
+
+func (set *AvlSet[K, V]) UpdateNode(node *AvlNode[K, V], newNode *AvlNode[K, V]) bool {
+	return UpdateNode(&set.tree, node, newNode)
+}
+
+func (set *AvlSet[K, V]) Len() int {
+	return Size(set.tree)
+}
+
+func (set *AvlSet[K, V]) All() iter.Seq[*AvlNode[K, V]] {
+	return set.tree.All()
+}
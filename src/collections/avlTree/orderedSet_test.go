@@ -0,0 +1,166 @@
+package avlTree
+
+import (
+	"math/rand"
+	"runtime/debug"
+	"slices"
+	"testing"
+)
+
+//OrderedSet implementation under the conformance suite, with a check of its own structural invariants
+type orderedSetImpl struct {
+	name     string
+	create   func() OrderedSet[int, string]
+	validate func(set OrderedSet[int, string]) error
+}
+
+var orderedSetImpls = []orderedSetImpl{
+	{"AvlSet",
+		func() OrderedSet[int, string] { return NewAvlSet[int, string]() },
+		func(set OrderedSet[int, string]) error { return Validate(set.(*AvlSet[int, string]).tree) }},
+	{"RedBlackTree",
+		func() OrderedSet[int, string] { return NewRedBlackTree[int, string]() },
+		func(set OrderedSet[int, string]) error { return validateRedBlackTree(set.(*RedBlackTree[int, string])) }},
+	{"Treap",
+		func() OrderedSet[int, string] { return NewTreap[int, string]() },
+		func(set OrderedSet[int, string]) error { return validateTreap(set.(*Treap[int, string])) }},
+}
+
+func createOrderedSet(impl orderedSetImpl, keys ...int) OrderedSet[int, string] {
+	set := impl.create()
+	for _, key := range keys {
+		set.Insert(NewAvlNode(key, ""))
+	}
+	return set
+}
+
+func verifyOrderedSetKeys(t *testing.T, impl orderedSetImpl, set OrderedSet[int, string], expected []int) {
+	if err := impl.validate(set); err != nil {
+		t.Errorf("%s: validate(set) == %v", impl.name, err)
+		debug.PrintStack()
+	}
+	if keys := collectKeys(set.All()); !slices.Equal(keys, expected) {
+		t.Errorf("%s: set holds %v, expected %v", impl.name, keys, expected)
+		debug.PrintStack()
+	}
+	if set.Len() != len(expected) {
+		t.Errorf("%s: Len() == %d, expected %d", impl.name, set.Len(), len(expected))
+		debug.PrintStack()
+	}
+}
+
+func verifyOrderedSetKey(t *testing.T, impl orderedSetImpl, name string, node *AvlNode[int, string], expected int) {
+	if node == nil || node.Key != expected {
+		t.Errorf("%s: %s == %v, expected key %d", impl.name, name, node, expected)
+		debug.PrintStack()
+	}
+}
+
+func testOrderedSet_Empty(t *testing.T, impl orderedSetImpl) {
+	set := impl.create()
+	set.Insert(nil)
+	set.Remove(NewAvlNode(1, ""))
+	set.Remove(nil)
+	set.RemoveMax()
+	if set.Min() != nil || set.Max() != nil {
+		t.Errorf("%s: Min() == %v, Max() == %v, expected nil", impl.name, set.Min(), set.Max())
+	}
+	if set.Has(NewAvlNode(1, "")) || set.Has(nil) {
+		t.Errorf("%s: empty set Has(1) == true", impl.name)
+	}
+	if set.UpdateNode(NewAvlNode(1, ""), NewAvlNode(2, "")) {
+		t.Errorf("%s: empty set UpdateNode(1, 2) == true", impl.name)
+	}
+	verifyOrderedSetKeys(t, impl, set, []int{})
+}
+
+func testOrderedSet_MinMaxHas(t *testing.T, impl orderedSetImpl) {
+	set := createOrderedSet(impl, 5, 1, 9, 3, 7)
+	verifyOrderedSetKey(t, impl, "Min()", set.Min(), 1)
+	verifyOrderedSetKey(t, impl, "Max()", set.Max(), 9)
+	if !set.Has(NewAvlNode(3, "")) || set.Has(NewAvlNode(4, "")) {
+		t.Errorf("%s: Has(3) == %t, Has(4) == %t, expected true, false",
+			impl.name, set.Has(NewAvlNode(3, "")), set.Has(NewAvlNode(4, "")))
+	}
+	verifyOrderedSetKeys(t, impl, set, []int{1, 3, 5, 7, 9})
+}
+
+func testOrderedSet_DuplicateKeys(t *testing.T, impl orderedSetImpl) {
+	set := createOrderedSet(impl, 2, 1, 2, 3, 2)
+	verifyOrderedSetKeys(t, impl, set, []int{1, 2, 2, 2, 3})
+	set.Remove(NewAvlNode(2, ""))
+	verifyOrderedSetKeys(t, impl, set, []int{1, 2, 2, 3})
+	set.RemoveMax()
+	set.RemoveMax()
+	verifyOrderedSetKeys(t, impl, set, []int{1, 2})
+}
+
+func testOrderedSet_RemoveMaxDescending(t *testing.T, impl orderedSetImpl) {
+	set := createOrderedSet(impl, rand.New(rand.NewSource(3)).Perm(100)...)
+	for expected := 99; expected >= 0; expected-- {
+		verifyOrderedSetKey(t, impl, "Max()", set.Max(), expected)
+		set.RemoveMax()
+	}
+	verifyOrderedSetKeys(t, impl, set, []int{})
+}
+
+func testOrderedSet_UpdateNode(t *testing.T, impl orderedSetImpl) {
+	set := createOrderedSet(impl, 1, 3, 5)
+	newNode := NewAvlNode(10, "updated")
+	if !set.UpdateNode(NewAvlNode(3, ""), newNode) {
+		t.Errorf("%s: UpdateNode(3, 10) == false, expected true", impl.name)
+	}
+	if set.Max() != newNode {
+		t.Errorf("%s: Max() == %v, expected the updated node %v", impl.name, set.Max(), newNode)
+	}
+	if set.UpdateNode(NewAvlNode(3, ""), NewAvlNode(4, "")) || set.UpdateNode(NewAvlNode(1, ""), nil) {
+		t.Errorf("%s: UpdateNode of missing node or to nil == true, expected false", impl.name)
+	}
+	verifyOrderedSetKeys(t, impl, set, []int{1, 5, 10})
+}
+
+//Applies random operations to set and to a sorted slice, comparing them after every operation
+func testOrderedSet_RandomAgainstModel(t *testing.T, impl orderedSetImpl) {
+	rng := rand.New(rand.NewSource(15))
+	set := impl.create()
+	model := sortedSliceModel{}
+	for i := 0; i < 2000; i++ {
+		key := rng.Intn(64)
+		switch rng.Intn(4) {
+		case 0, 1:
+			set.Insert(NewAvlNode(key, ""))
+			model.insert(key)
+		case 2:
+			set.Remove(NewAvlNode(key, ""))
+			model.remove(key)
+		case 3:
+			if rng.Intn(2) == 0 {
+				set.RemoveMax()
+				model.removeMax()
+			} else {
+				newKey := rng.Intn(64)
+				set.UpdateNode(NewAvlNode(key, ""), NewAvlNode(newKey, ""))
+				if model.remove(key) {
+					model.insert(newKey)
+				}
+			}
+		}
+		if err := impl.validate(set); err != nil {
+			t.Fatalf("%s: op %d: validate(set) == %v", impl.name, i, err)
+		}
+		if keys := collectKeys(set.All()); !slices.Equal(keys, model) {
+			t.Fatalf("%s: op %d: set holds %v, expected %v", impl.name, i, keys, model)
+		}
+	}
+}
+
+func TestOrderedSetConformance(t *testing.T) {
+	for _, impl := range orderedSetImpls {
+		testOrderedSet_Empty(t, impl)
+		testOrderedSet_MinMaxHas(t, impl)
+		testOrderedSet_DuplicateKeys(t, impl)
+		testOrderedSet_RemoveMaxDescending(t, impl)
+		testOrderedSet_UpdateNode(t, impl)
+		testOrderedSet_RandomAgainstModel(t, impl)
+	}
+}
@@ -0,0 +1,188 @@
+package avlTree
+
+import (
+	"cmp"
+	"iter"
+)
+
+//Immutable, versioned AVL tree.  Insert, Remove and RemoveMax leave the
+//receiver unchanged and return a new version which copies only the
+//root-to-leaf path that changed, sharing every untouched subtree with the
+//receiver.  All versions remain valid and may be read concurrently.
+type PersistentAvlTree[K, V any] struct {
+	tree    *AvlTree[K, V]
+	compare func(K, K) int
+}
+
+//Creates an empty persistent AVL tree ordered by the natural ordering of K
+func NewPersistentAvlTree[K cmp.Ordered, V any]() *PersistentAvlTree[K, V] {
+	return NewPersistentAvlTreeFunc[K, V](cmp.Compare[K])
+}
+
+//Creates an empty persistent AVL tree ordered by compare
+func NewPersistentAvlTreeFunc[K, V any](compare func(a, b K) int) *PersistentAvlTree[K, V] {
+	return &PersistentAvlTree[K, V]{nil, compare}
+}
+
+//Returns a new version of the tree with node inserted
+func (ptree *PersistentAvlTree[K, V]) Insert(node *AvlNode[K, V]) *PersistentAvlTree[K, V] {
+	if node == nil {
+		return ptree
+	}
+	//A zero-value tree is ordered by K's default ordering, stored in the new
+	//version so that the receiver is never written
+	compare := ptree.compare
+	if compare == nil {
+		compare = defaultCompare[K]()
+	}
+	return &PersistentAvlTree[K, V]{persistentInsert(ptree.tree, node, compare), compare}
+}
+
+//Returns a new version of the tree with node removed, or the receiver if node is not in the tree
+func (ptree *PersistentAvlTree[K, V]) Remove(node *AvlNode[K, V]) *PersistentAvlTree[K, V] {
+	if node == nil {
+		return ptree
+	}
+	tree := persistentRemove(ptree.tree, node)
+	if tree == ptree.tree {
+		return ptree
+	}
+	return &PersistentAvlTree[K, V]{tree, ptree.compare}
+}
+
+//Returns a new version of the tree with its max node removed
+func (ptree *PersistentAvlTree[K, V]) RemoveMax() *PersistentAvlTree[K, V] {
+	if ptree.tree.isEmpty() {
+		return ptree
+	}
+	return &PersistentAvlTree[K, V]{persistentRemoveMax(ptree.tree), ptree.compare}
+}
+
+//Returns max element in this version of the tree
+func (ptree *PersistentAvlTree[K, V]) Max() *AvlNode[K, V] {
+	return Max(ptree.tree)
+}
+
+//Returns min element in this version of the tree
+func (ptree *PersistentAvlTree[K, V]) Min() *AvlNode[K, V] {
+	return Min(ptree.tree)
+}
+
+//Returns true iff this version of the tree contains a node with the same key as node
+func (ptree *PersistentAvlTree[K, V]) Has(node *AvlNode[K, V]) bool {
+	return Has(ptree.tree, node)
+}
+
+//Returns the number of nodes in this version of the tree
+func (ptree *PersistentAvlTree[K, V]) Size() int {
+	return Size(ptree.tree)
+}
+
+//Returns an iterator over the nodes of this version in ascending key order
+func (ptree *PersistentAvlTree[K, V]) All() iter.Seq[*AvlNode[K, V]] {
+	return ptree.tree.All()
+}
+
+//Returns an iterator over the nodes of this version in descending key order
+func (ptree *PersistentAvlTree[K, V]) Backward() iter.Seq[*AvlNode[K, V]] {
+	return ptree.tree.Backward()
+}
+
+//Returns an iterator over the nodes of this version with lo <= key <= hi, in ascending key order
+func (ptree *PersistentAvlTree[K, V]) Range(lo K, hi K) iter.Seq[*AvlNode[K, V]] {
+	return ptree.tree.Range(lo, hi)
+}
+
+//Returns a new subtree with node inserted, copying only the path to node's position
+func persistentInsert[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V], compare func(K, K) int) *AvlTree[K, V] {
+	if tree.isEmpty() {
+		leaf := NewAvlTreeFunc[K, V](compare)
+		leaf.root = node
+		leaf.height = 0
+		leaf.size = 1
+		return leaf
+	}
+	copied := copySubtreeRoot(tree)
+	if copied.compareNodes(copied.root, node) >= 0 {
+		copied.left = persistentInsert(tree.left, node, compare)
+	} else {
+		copied.right = persistentInsert(tree.right, node, compare)
+	}
+	copied.updateHeight()
+	return persistentBalance(copied)
+}
+
+//Returns a new subtree with node removed, or tree itself if node is not in tree
+func persistentRemove[K, V any](tree *AvlTree[K, V], node *AvlNode[K, V]) *AvlTree[K, V] {
+	if tree.isEmpty() {
+		return tree
+	}
+	var copied *AvlTree[K, V]
+	rootToNodeComparison := tree.compareNodes(tree.root, node)
+	if rootToNodeComparison == 0 {
+		if tree.left.isEmpty() {
+			return tree.right
+		}
+		if tree.right.isEmpty() {
+			return tree.left
+		}
+		copied = copySubtreeRoot(tree)
+		copied.root = Max(tree.left)
+		copied.left = persistentRemoveMax(tree.left)
+	} else if rootToNodeComparison > 0 {
+		left := persistentRemove(tree.left, node)
+		if left == tree.left {
+			return tree
+		}
+		copied = copySubtreeRoot(tree)
+		copied.left = left
+	} else {
+		right := persistentRemove(tree.right, node)
+		if right == tree.right {
+			return tree
+		}
+		copied = copySubtreeRoot(tree)
+		copied.right = right
+	}
+	copied.updateHeight()
+	return persistentBalance(copied)
+}
+
+//Returns a new subtree with its max node removed, requires that tree is non-empty
+func persistentRemoveMax[K, V any](tree *AvlTree[K, V]) *AvlTree[K, V] {
+	if tree.right.isEmpty() {
+		return tree.left
+	}
+	copied := copySubtreeRoot(tree)
+	copied.right = persistentRemoveMax(tree.right)
+	copied.updateHeight()
+	return persistentBalance(copied)
+}
+
+//Rebalances a freshly copied subtree root.  Children are copied before the
+//rotations modify them, so subtrees shared with older versions are untouched.
+func persistentBalance[K, V any](tree *AvlTree[K, V]) *AvlTree[K, V] {
+	currBalance := tree.left.getHeight() - tree.right.getHeight()
+	if currBalance > 1 {
+		tree.left = copySubtreeRoot(tree.left)
+		if tree.left.left.getHeight() < tree.left.right.getHeight() {
+			tree.left.right = copySubtreeRoot(tree.left.right)
+			rotateRightToRoot(&tree.left)
+		}
+		rotateLeftToRoot(&tree)
+	} else if currBalance < -1 {
+		tree.right = copySubtreeRoot(tree.right)
+		if tree.right.right.getHeight() < tree.right.left.getHeight() {
+			tree.right.left = copySubtreeRoot(tree.right.left)
+			rotateLeftToRoot(&tree.right)
+		}
+		rotateRightToRoot(&tree)
+	}
+	return tree
+}
+
+//Returns a shallow copy of tree's root, sharing both children
+func copySubtreeRoot[K, V any](tree *AvlTree[K, V]) *AvlTree[K, V] {
+	copied := *tree
+	return &copied
+}
@@ -0,0 +1,221 @@
+package avlTree
+
+import (
+	"runtime/debug"
+	"slices"
+	"sync"
+	"testing"
+)
+
+//Verifies ordering, cached heights and sizes, and |balance| <= 1 at every node, returns tree's height
+func verifyAvlInvariants[K, V any](t *testing.T, tree *AvlTree[K, V]) int {
+	if tree.isEmpty() {
+		return -1
+	}
+	leftHeight := verifyAvlInvariants(t, tree.left)
+	rightHeight := verifyAvlInvariants(t, tree.right)
+	if !tree.left.isEmpty() && tree.compareNodes(tree.left.root, tree.root) > 0 {
+		t.Errorf("left child %v sorts above root %v", tree.left.root, tree.root)
+		debug.PrintStack()
+	}
+	if !tree.right.isEmpty() && tree.compareNodes(tree.right.root, tree.root) < 0 {
+		t.Errorf("right child %v sorts below root %v", tree.right.root, tree.root)
+		debug.PrintStack()
+	}
+	if leftHeight-rightHeight > 1 || rightHeight-leftHeight > 1 {
+		t.Errorf("node %v has balance %d, expected |balance| <= 1", tree.root, leftHeight-rightHeight)
+		debug.PrintStack()
+	}
+	height := maxInt(leftHeight, rightHeight) + 1
+	if tree.height != height {
+		t.Errorf("tree.height == %d at node %v, expected %d", tree.height, tree.root, height)
+		debug.PrintStack()
+	}
+	if size := tree.left.getSize() + tree.right.getSize() + 1; tree.size != size {
+		t.Errorf("tree.size == %d at node %v, expected %d", tree.size, tree.root, size)
+		debug.PrintStack()
+	}
+	return height
+}
+
+func verifyPersistentKeys(t *testing.T, ptree *PersistentAvlTree[int, string], expected []int) {
+	keys := collectKeys(ptree.All())
+	if !slices.Equal(keys, expected) {
+		t.Errorf("version holds keys %v, expected %v", keys, expected)
+		debug.PrintStack()
+	}
+	verifyAvlInvariants(t, ptree.tree)
+}
+
+//Collects every subtree reachable from tree
+func collectSubtrees[K, V any](tree *AvlTree[K, V], subtrees map[*AvlTree[K, V]]bool) {
+	if tree.isEmpty() {
+		return
+	}
+	subtrees[tree] = true
+	collectSubtrees(tree.left, subtrees)
+	collectSubtrees(tree.right, subtrees)
+}
+
+//Returns the number of subtrees in newer that are not shared with older
+func countUnsharedSubtrees[K, V any](older *AvlTree[K, V], newer *AvlTree[K, V]) int {
+	olderSubtrees := map[*AvlTree[K, V]]bool{}
+	newerSubtrees := map[*AvlTree[K, V]]bool{}
+	collectSubtrees(older, olderSubtrees)
+	collectSubtrees(newer, newerSubtrees)
+	unshared := 0
+	for subtree := range newerSubtrees {
+		if !olderSubtrees[subtree] {
+			unshared++
+		}
+	}
+	return unshared
+}
+
+func testPersistentTree_EmptyTree(t *testing.T) {
+	ptree := NewPersistentAvlTree[int, string]()
+	verifyPersistentKeys(t, ptree, []int{})
+	if ptree.Max() != nil || ptree.Min() != nil || ptree.Size() != 0 {
+		t.Errorf("empty tree has Max %v, Min %v, Size %d", ptree.Max(), ptree.Min(), ptree.Size())
+	}
+	if removed := ptree.Remove(NewAvlNode(1, "")); removed != ptree {
+		t.Errorf("Remove from empty tree returned a new version")
+	}
+	if removed := ptree.RemoveMax(); removed != ptree {
+		t.Errorf("RemoveMax from empty tree returned a new version")
+	}
+	verifyIteratedKeys(t, ptree.Range(0, 10), []int{})
+}
+
+func testPersistentTree_OldVersionsUnchanged(t *testing.T) {
+	versions := []*PersistentAvlTree[int, string]{NewPersistentAvlTree[int, string]()}
+	for key := 0; key < 50; key++ {
+		versions = append(versions, versions[len(versions)-1].Insert(NewAvlNode(key, "")))
+	}
+	for key := 0; key < 50; key += 2 {
+		versions = append(versions, versions[len(versions)-1].Remove(NewAvlNode(key, "")))
+	}
+	for i := 0; i < 5; i++ {
+		versions = append(versions, versions[len(versions)-1].RemoveMax())
+	}
+
+	for i := 0; i <= 50; i++ {
+		expected := []int{}
+		for key := 0; key < i; key++ {
+			expected = append(expected, key)
+		}
+		verifyPersistentKeys(t, versions[i], expected)
+	}
+	expected := []int{}
+	for key := 1; key < 40; key += 2 {
+		expected = append(expected, key)
+	}
+	verifyPersistentKeys(t, versions[len(versions)-1], expected)
+}
+
+func testPersistentTree_SharesUntouchedSubtrees(t *testing.T) {
+	ptree := NewPersistentAvlTree[int, string]()
+	for key := 0; key < 1000; key++ {
+		ptree = ptree.Insert(NewAvlNode(key*2, ""))
+	}
+	maxUnshared := 2 * (ptree.tree.height + 2)
+
+	inserted := ptree.Insert(NewAvlNode(501, ""))
+	if unshared := countUnsharedSubtrees(ptree.tree, inserted.tree); unshared > maxUnshared {
+		t.Errorf("Insert copied %d subtrees, expected at most %d", unshared, maxUnshared)
+	}
+	removed := ptree.Remove(NewAvlNode(500, ""))
+	if unshared := countUnsharedSubtrees(ptree.tree, removed.tree); unshared > maxUnshared {
+		t.Errorf("Remove copied %d subtrees, expected at most %d", unshared, maxUnshared)
+	}
+	removedMax := ptree.RemoveMax()
+	if unshared := countUnsharedSubtrees(ptree.tree, removedMax.tree); unshared > maxUnshared {
+		t.Errorf("RemoveMax copied %d subtrees, expected at most %d", unshared, maxUnshared)
+	}
+	if notFound := ptree.Remove(NewAvlNode(501, "")); notFound != ptree {
+		t.Errorf("Remove of missing node returned a new version")
+	}
+	verifyAvlInvariants(t, ptree.tree)
+	verifyAvlInvariants(t, inserted.tree)
+	verifyAvlInvariants(t, removed.tree)
+	verifyAvlInvariants(t, removedMax.tree)
+}
+
+//Mirrors testTreeInsert_LongTailShouldBalance
+func testPersistentTree_LongTailShouldBalance(t *testing.T) {
+	root := createAvlNode("root", -1)
+	left := createAvlNode("left", -5)
+	minNode := createAvlNode("min", -7)
+	ptree := NewPersistentAvlTreeFunc[PriorityKey, struct{}](ComparePriorityKeys).Insert(root).Insert(left)
+	balanced := ptree.Insert(minNode)
+
+	verifyNodePointersEqual(t, balanced.tree.root, left)
+	verifyNodePointersEqual(t, balanced.tree.left.root, minNode)
+	verifyNodePointersEqual(t, balanced.tree.right.root, root)
+	verifyGetHeightVal(t, balanced.tree, 1)
+	verifyAvlInvariants(t, balanced.tree)
+
+	verifyNodePointersEqual(t, ptree.tree.root, root)
+	verifyNodePointersEqual(t, ptree.tree.left.root, left)
+	verifyGetHeightVal(t, ptree.tree, 1)
+}
+
+//Mirrors testTreeBalance_BalancesIn1DblLftRtt
+func testPersistentTree_DoubleRotationShouldBalance(t *testing.T) {
+	root := createAvlNode("root", 10)
+	left := createAvlNode("left", 2)
+	leftRight := createAvlNode("leftRight", 5)
+	ptree := NewPersistentAvlTreeFunc[PriorityKey, struct{}](ComparePriorityKeys).Insert(root).Insert(left)
+	balanced := ptree.Insert(leftRight)
+
+	verifyNodePointersEqual(t, balanced.tree.root, leftRight)
+	verifyNodePointersEqual(t, balanced.tree.left.root, left)
+	verifyNodePointersEqual(t, balanced.tree.right.root, root)
+	verifyAvlInvariants(t, balanced.tree)
+
+	verifyNodePointersEqual(t, ptree.tree.root, root)
+	verifyNodePointersEqual(t, ptree.tree.left.root, left)
+	verifyTreeLAndR(t, ptree.tree.left, nil, nil)
+}
+
+func testPersistentTree_RemoveRequiringRebalance(t *testing.T) {
+	ptree := NewPersistentAvlTree[int, string]()
+	for _, key := range []int{50, 20, 80, 10, 30, 90, 25} {
+		ptree = ptree.Insert(NewAvlNode(key, ""))
+	}
+	removed := ptree.Remove(NewAvlNode(90, "")).Remove(NewAvlNode(80, ""))
+	verifyPersistentKeys(t, removed, []int{10, 20, 25, 30, 50})
+	verifyPersistentKeys(t, ptree, []int{10, 20, 25, 30, 50, 80, 90})
+}
+
+//Versions of a zero-value tree carry the default comparator, so reading them
+//concurrently writes nothing and the zero value itself is never modified
+func testPersistentTree_ZeroValueTree(t *testing.T) {
+	var zero PersistentAvlTree[int, string]
+	ptree := zero.Insert(NewAvlNode(20, "")).Insert(NewAvlNode(10, "")).Insert(NewAvlNode(30, ""))
+	if zero.compare != nil || zero.tree != nil {
+		t.Errorf("zero value modified by Insert")
+	}
+	var readers sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			if !ptree.Has(NewAvlNode(10, "")) || ptree.Has(NewAvlNode(15, "")) {
+				t.Errorf("Has == wrong membership for keys 10 and 15")
+			}
+		}()
+	}
+	readers.Wait()
+	verifyPersistentKeys(t, ptree, []int{10, 20, 30})
+}
+
+func TestPersistentAvlTree(t *testing.T) {
+	testPersistentTree_EmptyTree(t)
+	testPersistentTree_ZeroValueTree(t)
+	testPersistentTree_OldVersionsUnchanged(t)
+	testPersistentTree_SharesUntouchedSubtrees(t)
+	testPersistentTree_LongTailShouldBalance(t)
+	testPersistentTree_DoubleRotationShouldBalance(t)
+	testPersistentTree_RemoveRequiringRebalance(t)
+}
@@ -0,0 +1,138 @@
+package avlTree
+
+import (
+	"cmp"
+)
+
+//Max-priority queue of (data, priority) items backed by an AvlTree.
+//Items are ordered by priority, then by data, as in PriorityKey.Compare.
+//Items with equal priority and data are kept as separate entries, and both Pop
+//and PopMin return the earliest pushed of them first.
+type PriorityQueue struct {
+	tree    *AvlTree[queueKey, *QueueItem]
+	nextSeq uint64
+}
+
+//Handle to an item pushed onto a PriorityQueue, used to update its priority or remove it
+type QueueItem struct {
+	node  *AvlNode[queueKey, *QueueItem]
+	queue *PriorityQueue
+}
+
+//PriorityKey extended with a push sequence number to tell apart otherwise equal items
+type queueKey struct {
+	PriorityKey
+	seq uint64
+}
+
+//Orders by PriorityKey, then with earlier pushes above later ones so that ties pop in FIFO order
+func compareQueueKeys(first queueKey, second queueKey) int {
+	if keyCompare := first.Compare(second.PriorityKey); keyCompare != 0 {
+		return keyCompare
+	}
+	return cmp.Compare(second.seq, first.seq)
+}
+
+//Creates an empty priority queue
+func NewPriorityQueue() *PriorityQueue {
+	return &PriorityQueue{tree: NewAvlTreeFunc[queueKey, *QueueItem](compareQueueKeys)}
+}
+
+//Returns the data the item was pushed with
+func (item *QueueItem) Data() string {
+	return item.node.Key.Data
+}
+
+//Returns the item's current priority
+func (item *QueueItem) Priority() int {
+	return item.node.Key.Priority
+}
+
+//Returns the number of items in the queue
+func (pq *PriorityQueue) Len() int {
+	return Size(pq.tree)
+}
+
+//Adds an item to the queue and returns its handle
+func (pq *PriorityQueue) Push(data string, priority int) *QueueItem {
+	item := &QueueItem{queue: pq}
+	item.node = pq.newNode(item, PriorityKey{data, priority})
+	Insert(&pq.tree, item.node)
+	return item
+}
+
+//Returns the item with the highest priority without removing it, or nil if the queue is empty
+func (pq *PriorityQueue) Peek() *QueueItem {
+	return itemOf(Max(pq.tree))
+}
+
+//Returns the item with the lowest priority without removing it, or nil if the queue is empty
+func (pq *PriorityQueue) PeekMin() *QueueItem {
+	min := Min(pq.tree)
+	if min == nil {
+		return nil
+	}
+	//Later pushes sort below earlier ones with the same key, so the earliest push
+	//of the lowest key is the last node before sequence number 0, which sorts
+	//above every push of it
+	probe := NewAvlNode[queueKey, *QueueItem](queueKey{min.Key.PriorityKey, 0}, nil)
+	return itemOf(Predecessor(pq.tree, probe))
+}
+
+//Removes and returns the item with the highest priority, or nil if the queue is empty
+func (pq *PriorityQueue) Pop() *QueueItem {
+	item := pq.Peek()
+	if item != nil {
+		RemoveMax(&pq.tree)
+		item.queue = nil
+	}
+	return item
+}
+
+//Removes and returns the item with the lowest priority, or nil if the queue is empty
+func (pq *PriorityQueue) PopMin() *QueueItem {
+	item := pq.PeekMin()
+	if item != nil {
+		pq.Remove(item)
+	}
+	return item
+}
+
+//Changes the priority of item, placing it after queued items with the same priority and data.
+//Returns false if item is not in the queue.
+func (pq *PriorityQueue) UpdatePriority(item *QueueItem, priority int) bool {
+	if !pq.contains(item) {
+		return false
+	}
+	newNode := pq.newNode(item, PriorityKey{item.Data(), priority})
+	UpdateNode(&pq.tree, item.node, newNode)
+	item.node = newNode
+	return true
+}
+
+//Removes item from the queue, returns false if item is not in the queue
+func (pq *PriorityQueue) Remove(item *QueueItem) bool {
+	if !pq.contains(item) {
+		return false
+	}
+	Remove(&pq.tree, item.node)
+	item.queue = nil
+	return true
+}
+
+func (pq *PriorityQueue) contains(item *QueueItem) bool {
+	return item != nil && item.queue == pq
+}
+
+//Creates a tree node for item with the next sequence number
+func (pq *PriorityQueue) newNode(item *QueueItem, key PriorityKey) *AvlNode[queueKey, *QueueItem] {
+	pq.nextSeq++
+	return NewAvlNode(queueKey{key, pq.nextSeq}, item)
+}
+
+func itemOf(node *AvlNode[queueKey, *QueueItem]) *QueueItem {
+	if node == nil {
+		return nil
+	}
+	return node.Value
+}
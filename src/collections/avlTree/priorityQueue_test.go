@@ -0,0 +1,166 @@
+package avlTree
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func verifyQueueItem(t *testing.T, item *QueueItem, expectedData string, expectedPriority int) {
+	if item == nil {
+		t.Errorf("item == nil, expected {%s %d}", expectedData, expectedPriority)
+		debug.PrintStack()
+	} else if item.Data() != expectedData || item.Priority() != expectedPriority {
+		t.Errorf("item == {%s %d}, expected {%s %d}", item.Data(), item.Priority(), expectedData, expectedPriority)
+		debug.PrintStack()
+	}
+}
+
+func verifyQueueLen(t *testing.T, pq *PriorityQueue, expected int) {
+	if pq.Len() != expected {
+		t.Errorf("Len() == %d, expected %d", pq.Len(), expected)
+		debug.PrintStack()
+	}
+}
+
+func testPriorityQueue_Empty(t *testing.T) {
+	pq := NewPriorityQueue()
+	verifyQueueLen(t, pq, 0)
+	if item := pq.Peek(); item != nil {
+		t.Errorf("Peek() == %v, expected nil", item)
+	}
+	if item := pq.Pop(); item != nil {
+		t.Errorf("Pop() == %v, expected nil", item)
+	}
+	if item := pq.PopMin(); item != nil {
+		t.Errorf("PopMin() == %v, expected nil", item)
+	}
+	if pq.Remove(nil) || pq.UpdatePriority(nil, 5) {
+		t.Errorf("Remove(nil) or UpdatePriority(nil, 5) == true, expected false")
+	}
+}
+
+func testPriorityQueue_PopsInPriorityOrder(t *testing.T) {
+	pq := NewPriorityQueue()
+	pq.Push("low", 1)
+	pq.Push("high", 9)
+	pq.Push("mid", 5)
+	pq.Push("alsoMid", 5)
+	verifyQueueLen(t, pq, 4)
+
+	verifyQueueItem(t, pq.Peek(), "high", 9)
+	verifyQueueItem(t, pq.PeekMin(), "low", 1)
+	verifyQueueItem(t, pq.Pop(), "high", 9)
+	verifyQueueItem(t, pq.Pop(), "mid", 5)
+	verifyQueueItem(t, pq.PopMin(), "low", 1)
+	verifyQueueItem(t, pq.Pop(), "alsoMid", 5)
+	verifyQueueLen(t, pq, 0)
+}
+
+func testPriorityQueue_Duplicates(t *testing.T) {
+	pq := NewPriorityQueue()
+	first := pq.Push("same", 3)
+	second := pq.Push("same", 3)
+	third := pq.Push("same", 3)
+	verifyQueueLen(t, pq, 3)
+
+	if !pq.Remove(second) {
+		t.Errorf("Remove(second) == false, expected true")
+	}
+	verifyQueueLen(t, pq, 2)
+	if popped := pq.Pop(); popped != first {
+		t.Errorf("Pop() == %p, expected first item %p", popped, first)
+	}
+	if popped := pq.Pop(); popped != third {
+		t.Errorf("Pop() == %p, expected third item %p", popped, third)
+	}
+}
+
+func testPriorityQueue_PopMinDuplicates(t *testing.T) {
+	pq := NewPriorityQueue()
+	first := pq.Push("same", 3)
+	second := pq.Push("same", 3)
+	pq.Push("higher", 4)
+	third := pq.Push("same", 3)
+
+	for _, expected := range []*QueueItem{first, second, third} {
+		if peeked := pq.PeekMin(); peeked != expected {
+			t.Errorf("PeekMin() == %p, expected %p", peeked, expected)
+		}
+		if popped := pq.PopMin(); popped != expected {
+			t.Errorf("PopMin() == %p, expected %p", popped, expected)
+		}
+	}
+	verifyQueueItem(t, pq.PopMin(), "higher", 4)
+	verifyQueueLen(t, pq, 0)
+}
+
+func testPriorityQueue_UpdatePriority(t *testing.T) {
+	pq := NewPriorityQueue()
+	a := pq.Push("a", 1)
+	b := pq.Push("b", 2)
+	c := pq.Push("c", 3)
+
+	if !pq.UpdatePriority(a, 10) {
+		t.Errorf("UpdatePriority(a, 10) == false, expected true")
+	}
+	verifyQueueItem(t, a, "a", 10)
+	if !pq.UpdatePriority(c, 0) {
+		t.Errorf("UpdatePriority(c, 0) == false, expected true")
+	}
+	verifyQueueLen(t, pq, 3)
+	if popped := pq.Pop(); popped != a {
+		t.Errorf("Pop() == %p, expected a %p", popped, a)
+	}
+	if popped := pq.Pop(); popped != b {
+		t.Errorf("Pop() == %p, expected b %p", popped, b)
+	}
+	if popped := pq.Pop(); popped != c {
+		t.Errorf("Pop() == %p, expected c %p", popped, c)
+	}
+}
+
+func testPriorityQueue_UpdatePriorityOfDuplicate(t *testing.T) {
+	pq := NewPriorityQueue()
+	first := pq.Push("same", 3)
+	second := pq.Push("same", 3)
+	pq.UpdatePriority(second, 4)
+	pq.UpdatePriority(second, 3)
+
+	if popped := pq.Pop(); popped != first {
+		t.Errorf("Pop() == %p, expected first item %p", popped, first)
+	}
+	if popped := pq.Pop(); popped != second {
+		t.Errorf("Pop() == %p, expected second item %p", popped, second)
+	}
+}
+
+func testPriorityQueue_StaleHandles(t *testing.T) {
+	pq := NewPriorityQueue()
+	other := NewPriorityQueue()
+	popped := pq.Push("popped", 1)
+	removed := pq.Push("removed", 2)
+	foreign := other.Push("foreign", 3)
+	pq.Remove(removed)
+	pq.Pop()
+
+	for _, item := range []*QueueItem{popped, removed, foreign} {
+		if pq.Remove(item) {
+			t.Errorf("Remove(%s) == true, expected false", item.Data())
+		}
+		if pq.UpdatePriority(item, 100) {
+			t.Errorf("UpdatePriority(%s, 100) == true, expected false", item.Data())
+		}
+	}
+	verifyQueueLen(t, pq, 0)
+	verifyQueueLen(t, other, 1)
+}
+
+func TestPriorityQueue(t *testing.T) {
+	testPriorityQueue_Empty(t)
+	testPriorityQueue_PopsInPriorityOrder(t)
+	testPriorityQueue_Duplicates(t)
+	testPriorityQueue_PopMinDuplicates(t)
+	testPriorityQueue_UpdatePriority(t)
+	testPriorityQueue_UpdatePriorityOfDuplicate(t)
+	testPriorityQueue_StaleHandles(t)
+}
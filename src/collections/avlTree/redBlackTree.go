@@ -0,0 +1,291 @@
+package avlTree
+
+import (
+	"cmp"
+	"iter"
+)
+
+//OrderedSet backed by a left-leaning red-black tree (Sedgewick, 2008).
+//Red links always lean left and no path has two red links in a row, so every
+//path from the root has the same number of black links and the height stays
+//below 2 log2(n).  Updates rebalance with fewer rotations than an AVL tree,
+//at the cost of a taller tree to search.
+type RedBlackTree[K, V any] struct {
+	root    *redBlackNode[K, V]
+	size    int
+	compare func(K, K) int
+}
+
+//Node of a RedBlackTree, red iff the link from its parent is red
+type redBlackNode[K, V any] struct {
+	node  *AvlNode[K, V]
+	left  *redBlackNode[K, V]
+	right *redBlackNode[K, V]
+	red   bool
+}
+
+var _ OrderedSet[int, struct{}] = (*RedBlackTree[int, struct{}])(nil)
+
+//Creates an empty red-black tree ordered by the natural ordering of K
+func NewRedBlackTree[K cmp.Ordered, V any]() *RedBlackTree[K, V] {
+	return NewRedBlackTreeFunc[K, V](cmp.Compare[K])
+}
+
+//Creates an empty red-black tree ordered by compare
+func NewRedBlackTreeFunc[K, V any](compare func(a, b K) int) *RedBlackTree[K, V] {
+	return &RedBlackTree[K, V]{compare: compare}
+}
+
+func (rbtree *RedBlackTree[K, V]) Insert(node *AvlNode[K, V]) {
+	if node == nil {
+		return
+	}
+	rbtree.root = rbtree.insert(rbtree.root, node)
+	rbtree.root.red = false
+	rbtree.size++
+}
+
+func (rbtree *RedBlackTree[K, V]) Remove(node *AvlNode[K, V]) {
+	if !rbtree.Has(node) {
+		return
+	}
+	if !isRed(rbtree.root.left) && !isRed(rbtree.root.right) {
+		rbtree.root.red = true
+	}
+	rbtree.root = rbtree.remove(rbtree.root, node.Key)
+	rbtree.blackenRoot()
+	rbtree.size--
+}
+
+func (rbtree *RedBlackTree[K, V]) RemoveMax() {
+	if rbtree.root == nil {
+		return
+	}
+	if !isRed(rbtree.root.left) && !isRed(rbtree.root.right) {
+		rbtree.root.red = true
+	}
+	rbtree.root = removeRedBlackMax(rbtree.root)
+	rbtree.blackenRoot()
+	rbtree.size--
+}
+
+func (rbtree *RedBlackTree[K, V]) Has(node *AvlNode[K, V]) bool {
+	if node == nil {
+		return false
+	}
+	for subtree := rbtree.root; subtree != nil; {
+		rootToNodeCompare := rbtree.compare(subtree.node.Key, node.Key)
+		if rootToNodeCompare == 0 {
+			return true
+		}
+		if rootToNodeCompare > 0 {
+			subtree = subtree.left
+		} else {
+			subtree = subtree.right
+		}
+	}
+	return false
+}
+
+func (rbtree *RedBlackTree[K, V]) Min() *AvlNode[K, V] {
+	if rbtree.root == nil {
+		return nil
+	}
+	subtree := rbtree.root
+	for subtree.left != nil {
+		subtree = subtree.left
+	}
+	return subtree.node
+}
+
+func (rbtree *RedBlackTree[K, V]) Max() *AvlNode[K, V] {
+	if rbtree.root == nil {
+		return nil
+	}
+	subtree := rbtree.root
+	for subtree.right != nil {
+		subtree = subtree.right
+	}
+	return subtree.node
+}
+
+func (rbtree *RedBlackTree[K, V]) UpdateNode(node *AvlNode[K, V], newNode *AvlNode[K, V]) bool {
+	if newNode == nil || !rbtree.Has(node) {
+		return false
+	}
+	rbtree.Remove(node)
+	rbtree.Insert(newNode)
+	return true
+}
+
+func (rbtree *RedBlackTree[K, V]) Len() int {
+	return rbtree.size
+}
+
+func (rbtree *RedBlackTree[K, V]) All() iter.Seq[*AvlNode[K, V]] {
+	return func(yield func(*AvlNode[K, V]) bool) {
+		walkRedBlackAscending(rbtree.root, yield)
+	}
+}
+
+//Inserts node below subtree as a red leaf, returns the rebalanced subtree
+func (rbtree *RedBlackTree[K, V]) insert(subtree *redBlackNode[K, V], node *AvlNode[K, V]) *redBlackNode[K, V] {
+	if subtree == nil {
+		return &redBlackNode[K, V]{node: node, red: true}
+	}
+	if rbtree.compare(subtree.node.Key, node.Key) >= 0 {
+		subtree.left = rbtree.insert(subtree.left, node)
+	} else {
+		subtree.right = rbtree.insert(subtree.right, node)
+	}
+	return fixRedBlackUp(subtree)
+}
+
+//Removes one node with key from subtree, which must contain one.  On the way
+//down, red links are pushed towards the removed node so that it is never a
+//black leaf, then fixRedBlackUp restores the invariants on the way back up.
+func (rbtree *RedBlackTree[K, V]) remove(subtree *redBlackNode[K, V], key K) *redBlackNode[K, V] {
+	if rbtree.compare(key, subtree.node.Key) < 0 {
+		if !isRed(subtree.left) && !isRed(subtree.left.left) {
+			subtree = moveRedLeft(subtree)
+		}
+		subtree.left = rbtree.remove(subtree.left, key)
+		return fixRedBlackUp(subtree)
+	}
+	if isRed(subtree.left) {
+		subtree = rotateRedBlackRight(subtree)
+	}
+	if rbtree.compare(key, subtree.node.Key) == 0 && subtree.right == nil {
+		return nil
+	}
+	found := rbtree.compare(key, subtree.node.Key) == 0
+	if !isRed(subtree.right) && !isRed(subtree.right.left) {
+		moved := moveRedRight(subtree)
+		//A rotation here moves the node just compared down to moved.right.
+		//Its new parent may hold an equal key, so searching on from the new
+		//parent must continue to the right rather than remove the parent.
+		found = found && moved == subtree
+		subtree = moved
+	}
+	if found {
+		successor := subtree.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		subtree.node = successor.node
+		subtree.right = removeRedBlackMin(subtree.right)
+	} else {
+		subtree.right = rbtree.remove(subtree.right, key)
+	}
+	return fixRedBlackUp(subtree)
+}
+
+func (rbtree *RedBlackTree[K, V]) blackenRoot() {
+	if rbtree.root != nil {
+		rbtree.root.red = false
+	}
+}
+
+func removeRedBlackMin[K, V any](subtree *redBlackNode[K, V]) *redBlackNode[K, V] {
+	if subtree.left == nil {
+		return nil
+	}
+	if !isRed(subtree.left) && !isRed(subtree.left.left) {
+		subtree = moveRedLeft(subtree)
+	}
+	subtree.left = removeRedBlackMin(subtree.left)
+	return fixRedBlackUp(subtree)
+}
+
+func removeRedBlackMax[K, V any](subtree *redBlackNode[K, V]) *redBlackNode[K, V] {
+	if isRed(subtree.left) {
+		subtree = rotateRedBlackRight(subtree)
+	}
+	if subtree.right == nil {
+		return nil
+	}
+	if !isRed(subtree.right) && !isRed(subtree.right.left) {
+		subtree = moveRedRight(subtree)
+	}
+	subtree.right = removeRedBlackMax(subtree.right)
+	return fixRedBlackUp(subtree)
+}
+
+//Restores left-leaning red links below subtree's root, returns the new subtree root
+func fixRedBlackUp[K, V any](subtree *redBlackNode[K, V]) *redBlackNode[K, V] {
+	if isRed(subtree.right) && !isRed(subtree.left) {
+		subtree = rotateRedBlackLeft(subtree)
+	}
+	if isRed(subtree.left) && isRed(subtree.left.left) {
+		subtree = rotateRedBlackRight(subtree)
+	}
+	if isRed(subtree.left) && isRed(subtree.right) {
+		flipColors(subtree)
+	}
+	return subtree
+}
+
+//Makes subtree.left or one of its children red, assuming subtree is red and both its children are black
+func moveRedLeft[K, V any](subtree *redBlackNode[K, V]) *redBlackNode[K, V] {
+	flipColors(subtree)
+	if isRed(subtree.right.left) {
+		subtree.right = rotateRedBlackRight(subtree.right)
+		subtree = rotateRedBlackLeft(subtree)
+		flipColors(subtree)
+	}
+	return subtree
+}
+
+//Makes subtree.right or one of its children red, assuming subtree is red and both its children are black
+func moveRedRight[K, V any](subtree *redBlackNode[K, V]) *redBlackNode[K, V] {
+	flipColors(subtree)
+	if isRed(subtree.left.left) {
+		subtree = rotateRedBlackRight(subtree)
+		flipColors(subtree)
+	}
+	return subtree
+}
+
+//Graphical representation of rotateRedBlackLeft(t), where t-R is red:
+//     t                R
+//  L     R     ->   t     RR
+//      RL RR      L  RL
+func rotateRedBlackLeft[K, V any](subtree *redBlackNode[K, V]) *redBlackNode[K, V] {
+	right := subtree.right
+	subtree.right = right.left
+	right.left = subtree
+	right.red = subtree.red
+	subtree.red = true
+	return right
+}
+
+//Graphical representation of rotateRedBlackRight(t), where t-L is red:
+//       t            L
+//    L     R   ->  LL    t
+//  LL LR               LR  R
+func rotateRedBlackRight[K, V any](subtree *redBlackNode[K, V]) *redBlackNode[K, V] {
+	left := subtree.left
+	subtree.left = left.right
+	left.right = subtree
+	left.red = subtree.red
+	subtree.red = true
+	return left
+}
+
+func flipColors[K, V any](subtree *redBlackNode[K, V]) {
+	subtree.red = !subtree.red
+	subtree.left.red = !subtree.left.red
+	subtree.right.red = !subtree.right.red
+}
+
+func isRed[K, V any](subtree *redBlackNode[K, V]) bool {
+	return subtree != nil && subtree.red
+}
+
+//In-order traversal, returns false iff yield requested a stop
+func walkRedBlackAscending[K, V any](subtree *redBlackNode[K, V], yield func(*AvlNode[K, V]) bool) bool {
+	if subtree == nil {
+		return true
+	}
+	return walkRedBlackAscending(subtree.left, yield) && yield(subtree.node) && walkRedBlackAscending(subtree.right, yield)
+}
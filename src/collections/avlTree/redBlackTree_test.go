@@ -0,0 +1,111 @@
+package avlTree
+
+import (
+	"fmt"
+	"testing"
+)
+
+//Checks key order, that red links lean left and never come in pairs, and that
+//every path from the root passes the same number of black links
+func validateRedBlackTree[K, V any](rbtree *RedBlackTree[K, V]) error {
+	if isRed(rbtree.root) {
+		return fmt.Errorf("root %v is red", rbtree.root.node)
+	}
+	_, err := validateRedBlackSubtree(rbtree, rbtree.root, nil, nil)
+	return err
+}
+
+//Returns the number of black links on every path down from subtree
+func validateRedBlackSubtree[K, V any](rbtree *RedBlackTree[K, V], subtree *redBlackNode[K, V], lo *AvlNode[K, V], hi *AvlNode[K, V]) (int, error) {
+	if subtree == nil {
+		return 0, nil
+	}
+	if (lo != nil && rbtree.compare(lo.Key, subtree.node.Key) > 0) || (hi != nil && rbtree.compare(subtree.node.Key, hi.Key) > 0) {
+		return 0, fmt.Errorf("node %v is out of order", subtree.node)
+	}
+	if isRed(subtree.right) {
+		return 0, fmt.Errorf("node %v has a red right link", subtree.node)
+	}
+	if isRed(subtree) && isRed(subtree.left) {
+		return 0, fmt.Errorf("node %v and its left child are both red", subtree.node)
+	}
+	leftBlack, err := validateRedBlackSubtree(rbtree, subtree.left, lo, subtree.node)
+	if err != nil {
+		return 0, err
+	}
+	rightBlack, err := validateRedBlackSubtree(rbtree, subtree.right, subtree.node, hi)
+	if err != nil {
+		return 0, err
+	}
+	if leftBlack != rightBlack {
+		return 0, fmt.Errorf("node %v has %d black links on the left, %d on the right", subtree.node, leftBlack, rightBlack)
+	}
+	if !isRed(subtree) {
+		leftBlack++
+	}
+	return leftBlack, nil
+}
+
+func redBlackHeight[K, V any](subtree *redBlackNode[K, V]) int {
+	if subtree == nil {
+		return -1
+	}
+	return max(redBlackHeight(subtree.left), redBlackHeight(subtree.right)) + 1
+}
+
+func testRedBlackTree_AscendingKeys(t *testing.T) {
+	rbtree := NewRedBlackTree[int, string]()
+	for key := 0; key < 1<<12; key++ {
+		rbtree.Insert(NewAvlNode(key, ""))
+	}
+	if err := validateRedBlackTree(rbtree); err != nil {
+		t.Errorf("validateRedBlackTree(rbtree) == %v", err)
+	}
+	//Red-black trees of n nodes have height <= 2 log2(n + 1)
+	if height := redBlackHeight(rbtree.root); height > 24 {
+		t.Errorf("height == %d, expected <= 24", height)
+	}
+	for key := 0; key < 1<<12; key += 2 {
+		rbtree.Remove(NewAvlNode(key, ""))
+	}
+	if err := validateRedBlackTree(rbtree); err != nil {
+		t.Errorf("validateRedBlackTree(rbtree) after removals == %v", err)
+	}
+}
+
+//Removing a key held by several nodes can rotate an equal node above the one
+//being removed, which must not be mistaken for it
+func testRedBlackTree_RemoveDuplicates(t *testing.T) {
+	rbtree := NewRedBlackTree[int, string]()
+	for i := 0; i < 64; i++ {
+		rbtree.Insert(NewAvlNode(i%8, ""))
+	}
+	for i := 0; i < 64; i++ {
+		rbtree.Remove(NewAvlNode(i%8, ""))
+		if err := validateRedBlackTree(rbtree); err != nil {
+			t.Fatalf("removal %d: validateRedBlackTree(rbtree) == %v", i, err)
+		}
+	}
+	if rbtree.Len() != 0 || rbtree.root != nil {
+		t.Errorf("Len() == %d, root == %v, expected an empty tree", rbtree.Len(), rbtree.root)
+	}
+}
+
+func testRedBlackTree_RejectsBrokenInvariants(t *testing.T) {
+	rbtree := NewRedBlackTree[int, string]()
+	rbtree.root = &redBlackNode[int, string]{node: NewAvlNode(2, "")}
+	rbtree.root.right = &redBlackNode[int, string]{node: NewAvlNode(3, ""), red: true}
+	if validateRedBlackTree(rbtree) == nil {
+		t.Errorf("validateRedBlackTree accepted a red right link")
+	}
+	rbtree.root.right.red = false
+	if validateRedBlackTree(rbtree) == nil {
+		t.Errorf("validateRedBlackTree accepted unequal black heights")
+	}
+}
+
+func TestRedBlackTree(t *testing.T) {
+	testRedBlackTree_AscendingKeys(t)
+	testRedBlackTree_RemoveDuplicates(t)
+	testRedBlackTree_RejectsBrokenInvariants(t)
+}
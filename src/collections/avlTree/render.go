@@ -0,0 +1,81 @@
+package avlTree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//Writes tree to w as a Graphviz digraph, one record per node showing its key,
+//cached height and balance factor (left height - right height).  A missing
+//child is drawn as a point when its sibling exists, so left and right stay
+//apart.  Render with, e.g., `dot -Tpng tree.dot -o tree.png`.
+func WriteDOT[K, V any](w io.Writer, tree *AvlTree[K, V]) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph AvlTree {\n\tnode [shape=box];\n")
+	if !tree.isEmpty() {
+		nextId := 0
+		writeDOTSubtree(bw, tree, &nextId)
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+//Writes tree to w sideways, with the root at the left margin, right subtrees
+//above their parent and left subtrees below it:
+//
+//	/-- 15 [h=1 b=1]
+//	|   \-- 12 [h=0 b=0]
+//	10 [h=2 b=-1]
+//	\-- 5 [h=0 b=0]
+func WriteASCII[K, V any](w io.Writer, tree *AvlTree[K, V]) error {
+	bw := bufio.NewWriter(w)
+	if tree.isEmpty() {
+		bw.WriteString("(empty)\n")
+	} else {
+		writeASCIISubtree(bw, tree, "", "", "")
+	}
+	return bw.Flush()
+}
+
+//Writes the statements for tree's root and its subtrees, returns the root's DOT id
+func writeDOTSubtree[K, V any](bw *bufio.Writer, tree *AvlTree[K, V], nextId *int) string {
+	id := fmt.Sprintf("n%d", *nextId)
+	*nextId++
+	label := escapeDOT(fmt.Sprint(tree.root.Key)) + `\n` + heightLabel(tree)
+	fmt.Fprintf(bw, "\t%s [label=\"%s\"];\n", id, label)
+	if tree.left.isEmpty() && tree.right.isEmpty() {
+		return id
+	}
+	for _, child := range []*AvlTree[K, V]{tree.left, tree.right} {
+		if child.isEmpty() {
+			fmt.Fprintf(bw, "\t%s_nil%d [shape=point];\n\t%s -> %s_nil%d;\n", id, *nextId, id, id, *nextId)
+			*nextId++
+		} else {
+			childId := writeDOTSubtree(bw, child, nextId)
+			fmt.Fprintf(bw, "\t%s -> %s;\n", id, childId)
+		}
+	}
+	return id
+}
+
+//Writes the right subtree, then tree's root after linePrefix, then the left subtree.
+//rightPrefix and leftPrefix start the lines above and below the root's line.
+func writeASCIISubtree[K, V any](bw *bufio.Writer, tree *AvlTree[K, V], rightPrefix string, linePrefix string, leftPrefix string) {
+	if !tree.right.isEmpty() {
+		writeASCIISubtree(bw, tree.right, rightPrefix+"    ", rightPrefix+"/-- ", rightPrefix+"|   ")
+	}
+	fmt.Fprintf(bw, "%s%v [%s]\n", linePrefix, tree.root.Key, heightLabel(tree))
+	if !tree.left.isEmpty() {
+		writeASCIISubtree(bw, tree.left, leftPrefix+"|   ", leftPrefix+"\\-- ", leftPrefix+"    ")
+	}
+}
+
+func heightLabel[K, V any](tree *AvlTree[K, V]) string {
+	return fmt.Sprintf("h=%d b=%d", tree.height, tree.left.getHeight()-tree.right.getHeight())
+}
+
+func escapeDOT(label string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(label)
+}
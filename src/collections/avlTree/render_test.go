@@ -0,0 +1,154 @@
+package avlTree
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+//Logs tree in ASCII form, for use when a check on tree fails.  If AVL_DOT_DIR
+//is set, also writes the tree to AVL_DOT_DIR/<test>-<name>.dot for Graphviz.
+func dumpTree[K, V any](t *testing.T, name string, tree *AvlTree[K, V]) {
+	var text strings.Builder
+	WriteASCII(&text, tree)
+	t.Logf("%s:\n%s", name, text.String())
+	dir := os.Getenv("AVL_DOT_DIR")
+	if dir == "" {
+		return
+	}
+	path := filepath.Join(dir, strings.ReplaceAll(t.Name(), "/", "_")+"-"+name+".dot")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Logf("cannot write %s: %v", path, err)
+		return
+	}
+	defer file.Close()
+	if err := WriteDOT(file, tree); err != nil {
+		t.Logf("cannot write %s: %v", path, err)
+		return
+	}
+	t.Logf("wrote %s", path)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func verifyRendered(t *testing.T, rendered string, expected string) {
+	if rendered != expected {
+		t.Errorf("rendered:\n%s\nexpected:\n%s", rendered, expected)
+		debug.PrintStack()
+	}
+}
+
+//       root
+//    L        R
+//  LL  LR   RL
+func createRenderTree() *priorityTree {
+	left := createAvlTree("L", 2, 1, createAvlTree_Leaf("LL", -1), createAvlTree_Leaf("LR", 3))
+	right := createAvlTree("R", 15, 1, createAvlTree_Leaf("RL", 14), nil)
+	return createAvlTree("root", 10, 2, left, right)
+}
+
+func testWriteASCII_Empty(t *testing.T) {
+	var text strings.Builder
+	WriteASCII(&text, (*priorityTree)(nil))
+	WriteASCII(&text, newPriorityTree())
+	verifyRendered(t, text.String(), "(empty)\n(empty)\n")
+}
+
+func testWriteASCII_Tree(t *testing.T) {
+	var text strings.Builder
+	if err := WriteASCII(&text, createRenderTree()); err != nil {
+		t.Errorf("WriteASCII() == %v, expected nil", err)
+	}
+	verifyRendered(t, text.String(), `/-- R (15) [h=1 b=1]
+|   \-- RL (14) [h=0 b=0]
+root (10) [h=2 b=0]
+|   /-- LR (3) [h=0 b=0]
+\-- L (2) [h=1 b=0]
+    \-- LL (-1) [h=0 b=0]
+`)
+}
+
+func testWriteASCII_WriterError(t *testing.T) {
+	if err := WriteASCII(failingWriter{}, createRenderTree()); err == nil {
+		t.Errorf("WriteASCII(failingWriter) == nil, expected an error")
+	}
+}
+
+func TestWriteASCII(t *testing.T) {
+	testWriteASCII_Empty(t)
+	testWriteASCII_Tree(t)
+	testWriteASCII_WriterError(t)
+}
+
+func testWriteDOT_Empty(t *testing.T) {
+	var dot strings.Builder
+	WriteDOT(&dot, newPriorityTree())
+	verifyRendered(t, dot.String(), "digraph AvlTree {\n\tnode [shape=box];\n}\n")
+}
+
+func testWriteDOT_Tree(t *testing.T) {
+	var dot strings.Builder
+	if err := WriteDOT(&dot, createRenderTree()); err != nil {
+		t.Errorf("WriteDOT() == %v, expected nil", err)
+	}
+	verifyRendered(t, dot.String(), `digraph AvlTree {
+	node [shape=box];
+	n0 [label="root (10)\nh=2 b=0"];
+	n1 [label="L (2)\nh=1 b=0"];
+	n2 [label="LL (-1)\nh=0 b=0"];
+	n1 -> n2;
+	n3 [label="LR (3)\nh=0 b=0"];
+	n1 -> n3;
+	n0 -> n1;
+	n4 [label="R (15)\nh=1 b=1"];
+	n5 [label="RL (14)\nh=0 b=0"];
+	n4 -> n5;
+	n4_nil6 [shape=point];
+	n4 -> n4_nil6;
+	n0 -> n4;
+}
+`)
+}
+
+func testWriteDOT_EscapesLabels(t *testing.T) {
+	var dot strings.Builder
+	WriteDOT(&dot, createAvlTree_Leaf(`say "hi" \o/`, 1))
+	if !strings.Contains(dot.String(), `n0 [label="say \"hi\" \\o/ (1)\nh=0 b=0"];`) {
+		t.Errorf("WriteDOT() == %q, expected escaped quotes and backslashes", dot.String())
+	}
+}
+
+func testWriteDOT_WriterError(t *testing.T) {
+	if err := WriteDOT(failingWriter{}, createRenderTree()); err == nil {
+		t.Errorf("WriteDOT(failingWriter) == nil, expected an error")
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	testWriteDOT_Empty(t)
+	testWriteDOT_Tree(t)
+	testWriteDOT_EscapesLabels(t)
+	testWriteDOT_WriterError(t)
+}
+
+func TestDumpTree(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AVL_DOT_DIR", dir)
+	dumpTree(t, "render", createRenderTree())
+
+	dot, err := os.ReadFile(filepath.Join(dir, "TestDumpTree-render.dot"))
+	if err != nil {
+		t.Fatalf("ReadFile() == %v", err)
+	}
+	if !strings.HasPrefix(string(dot), "digraph AvlTree {") {
+		t.Errorf("dumped file holds %q, expected a DOT digraph", dot)
+	}
+}
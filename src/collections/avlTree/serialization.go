@@ -0,0 +1,85 @@
+package avlTree
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+//Header of the binary encoding, followed by a gob-encoded slice of nodes in ascending key order
+var binaryHeader = []byte{'A', 'V', 'L', 1}
+
+//Encodes the nodes of tree in ascending key order.  K and V must be encodable by encoding/gob.
+func (tree *AvlTree[K, V]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(binaryHeader)
+	if err := gob.NewEncoder(&buf).Encode(collectNodes(tree)); err != nil {
+		return nil, fmt.Errorf("avlTree: encoding nodes: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+//Replaces the contents of tree with nodes decoded from data, in linear time.
+//The decoded nodes are checked to be in ascending order by tree's comparator.
+//A zero-value tree takes K's default ordering, as Insert would, and decoding
+//fails if K has none.
+func (tree *AvlTree[K, V]) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, binaryHeader) {
+		return fmt.Errorf("avlTree: missing or unsupported binary header")
+	}
+	var nodes []*AvlNode[K, V]
+	decoder := gob.NewDecoder(bytes.NewReader(data[len(binaryHeader):]))
+	if err := decoder.Decode(&nodes); err != nil {
+		return fmt.Errorf("avlTree: decoding nodes: %v", err)
+	}
+	return tree.replaceWithSorted(nodes)
+}
+
+//Encodes tree as a JSON array of {"Key": ..., "Value": ...} objects in ascending key order
+func (tree *AvlTree[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(collectNodes(tree))
+}
+
+//Replaces the contents of tree with nodes decoded from a JSON array, in linear time.
+//The decoded nodes are checked to be in ascending order by tree's comparator.
+//A zero-value tree takes K's default ordering, as Insert would, and decoding
+//fails if K has none.
+func (tree *AvlTree[K, V]) UnmarshalJSON(data []byte) error {
+	var nodes []*AvlNode[K, V]
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return fmt.Errorf("avlTree: decoding nodes: %v", err)
+	}
+	return tree.replaceWithSorted(nodes)
+}
+
+//Returns the nodes of tree in ascending key order
+func collectNodes[K, V any](tree *AvlTree[K, V]) []*AvlNode[K, V] {
+	nodes := make([]*AvlNode[K, V], 0, tree.getSize())
+	for node := range tree.All() {
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+//Checks that nodes are non-nil and sorted, then rebuilds tree from them in place
+func (tree *AvlTree[K, V]) replaceWithSorted(nodes []*AvlNode[K, V]) error {
+	tree.initComparator()
+	if tree.compare == nil {
+		return fmt.Errorf("avlTree: cannot decode into a tree without a comparator")
+	}
+	for i, node := range nodes {
+		if node == nil {
+			return fmt.Errorf("avlTree: node %d is null", i)
+		}
+		if i > 0 && tree.comparator()(nodes[i-1].Key, node.Key) > 0 {
+			return fmt.Errorf("avlTree: node %d is out of order, %v sorts before %v", i, node.Key, nodes[i-1].Key)
+		}
+	}
+	rebuilt := buildFromSorted(nodes, tree)
+	if rebuilt == nil {
+		rebuilt = newSubtreeLike(tree)
+	}
+	*tree = *rebuilt
+	return nil
+}
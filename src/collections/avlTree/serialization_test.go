@@ -0,0 +1,175 @@
+package avlTree
+
+import (
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func verifyDecodeErr(t *testing.T, err error, expectedErrMsg string) {
+	if err == nil || !strings.Contains(err.Error(), expectedErrMsg) {
+		t.Errorf("decode err == %v, expected error containing %q", err, expectedErrMsg)
+		debug.PrintStack()
+	}
+}
+
+func verifyDecodedTree(t *testing.T, tree *AvlTree[int, string], expectedKeys []int) {
+	if err := Validate(tree); err != nil {
+		t.Errorf("Validate(decoded) == %v", err)
+	}
+	verifyIteratedKeys(t, tree.All(), expectedKeys)
+}
+
+func testSerialization_BinaryRoundTrip(t *testing.T) {
+	tree := createIntTree()
+	for key := 0; key < 100; key++ {
+		Insert(&tree, NewAvlNode(key, "value"))
+	}
+	RemoveMax(&tree)
+	data, err := tree.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() returned err %v", err)
+	}
+
+	decoded := createIntTree(500, 501)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() returned err %v", err)
+	}
+	verifyDecodedTree(t, decoded, keyRange(0, 99))
+	if node := Select(decoded, 42); node == nil || node.Value != "value" {
+		t.Errorf("Select(decoded, 42) == %v, expected value %q", node, "value")
+	}
+	Insert(&decoded, NewAvlNode(1000, ""))
+	verifyDecodedTree(t, decoded, append(keyRange(0, 99), 1000))
+}
+
+func testSerialization_JSONRoundTrip(t *testing.T) {
+	tree := createIntTree(3, 1, 2)
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("json.Marshal(tree) returned err %v", err)
+	}
+	expectedJSON := `[{"Key":1,"Value":""},{"Key":2,"Value":""},{"Key":3,"Value":""}]`
+	if string(data) != expectedJSON {
+		t.Errorf("json.Marshal(tree) == %s, expected %s", data, expectedJSON)
+	}
+
+	decoded := NewAvlTree[int, string]()
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("json.Unmarshal() returned err %v", err)
+	}
+	verifyDecodedTree(t, decoded, []int{1, 2, 3})
+}
+
+func testSerialization_PriorityKeys(t *testing.T) {
+	tree := NewAvlTreeFunc[PriorityKey, string](ComparePriorityKeys)
+	Insert(&tree, NewAvlNode(PriorityKey{"b", 1}, "second"))
+	Insert(&tree, NewAvlNode(PriorityKey{"a", 1}, "first"))
+	Insert(&tree, NewAvlNode(PriorityKey{"a", 7}, "highest"))
+
+	binaryData, err := tree.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() returned err %v", err)
+	}
+	jsonData, err := tree.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() returned err %v", err)
+	}
+	fromBinary := NewAvlTreeFunc[PriorityKey, string](ComparePriorityKeys)
+	fromJSON := NewAvlTreeFunc[PriorityKey, string](ComparePriorityKeys)
+	if err := fromBinary.UnmarshalBinary(binaryData); err != nil {
+		t.Fatalf("UnmarshalBinary() returned err %v", err)
+	}
+	if err := fromJSON.UnmarshalJSON(jsonData); err != nil {
+		t.Fatalf("UnmarshalJSON() returned err %v", err)
+	}
+	for _, decoded := range []*AvlTree[PriorityKey, string]{fromBinary, fromJSON} {
+		if max := Max(decoded); max == nil || max.Value != "highest" {
+			t.Errorf("Max(decoded) == %v, expected highest", max)
+		}
+		if min := Min(decoded); min == nil || min.Value != "first" {
+			t.Errorf("Min(decoded) == %v, expected first", min)
+		}
+	}
+}
+
+func testSerialization_EmptyTree(t *testing.T) {
+	data, err := NewAvlTree[int, string]().MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() returned err %v", err)
+	}
+	decoded := createIntTree(1, 2, 3)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() returned err %v", err)
+	}
+	verifyDecodedTree(t, decoded, []int{})
+
+	decoded = createIntTree(1, 2, 3)
+	if err := decoded.UnmarshalJSON([]byte("[]")); err != nil {
+		t.Fatalf("UnmarshalJSON([]) returned err %v", err)
+	}
+	verifyDecodedTree(t, decoded, []int{})
+}
+
+func testSerialization_CorruptBinary(t *testing.T) {
+	data, _ := createIntTree(1, 2, 3).MarshalBinary()
+	tree := NewAvlTree[int, string]()
+	verifyDecodeErr(t, tree.UnmarshalBinary(nil), "binary header")
+	verifyDecodeErr(t, tree.UnmarshalBinary([]byte("AVL")), "binary header")
+	verifyDecodeErr(t, tree.UnmarshalBinary(append([]byte{'A', 'V', 'L', 9}, data[4:]...)), "binary header")
+	verifyDecodeErr(t, tree.UnmarshalBinary(data[:len(data)-3]), "decoding nodes")
+	verifyDecodeErr(t, tree.UnmarshalBinary(append(data[:4:4], 0xff, 0xff, 0x01)), "decoding nodes")
+}
+
+func testSerialization_OutOfOrder(t *testing.T) {
+	tree := NewAvlTree[int, string]()
+	verifyDecodeErr(t, tree.UnmarshalJSON([]byte(`[{"Key":1},{"Key":3},{"Key":2}]`)), "node 2 is out of order")
+
+	descending := NewAvlTreeFunc[int, string](func(a, b int) int { return b - a })
+	Insert(&descending, NewAvlNode(1, ""))
+	Insert(&descending, NewAvlNode(2, ""))
+	data, _ := descending.MarshalBinary()
+	verifyDecodeErr(t, tree.UnmarshalBinary(data), "node 1 is out of order")
+}
+
+func testSerialization_InvalidJSON(t *testing.T) {
+	tree := NewAvlTree[int, string]()
+	verifyDecodeErr(t, tree.UnmarshalJSON([]byte(`[{"Key":1},null]`)), "node 1 is null")
+	verifyDecodeErr(t, tree.UnmarshalJSON([]byte(`[{"Key":"one"}]`)), "decoding nodes")
+	verifyDecodeErr(t, tree.UnmarshalJSON([]byte(`{"Key":1}`)), "decoding nodes")
+}
+
+func testSerialization_RejectedInputLeavesTreeUnchanged(t *testing.T) {
+	tree := createIntTree(1, 2, 3)
+	tree.UnmarshalJSON([]byte(`[{"Key":5},{"Key":4}]`))
+	verifyDecodedTree(t, tree, []int{1, 2, 3})
+}
+
+func testSerialization_NoComparator(t *testing.T) {
+	type point struct{ X, Y int }
+	var tree AvlTree[point, string]
+	verifyDecodeErr(t, tree.UnmarshalJSON([]byte(`[{"Key":{"X":1}}]`)), "without a comparator")
+}
+
+//Zero-value trees decode using the key's natural ordering
+func testSerialization_ZeroValueTree(t *testing.T) {
+	var tree AvlTree[int, string]
+	if err := tree.UnmarshalJSON([]byte(`[{"Key":1},{"Key":2}]`)); err != nil {
+		t.Fatalf("UnmarshalJSON == %v", err)
+	}
+	verifyDecodedTree(t, &tree, []int{1, 2})
+}
+
+func TestSerialization(t *testing.T) {
+	testSerialization_BinaryRoundTrip(t)
+	testSerialization_JSONRoundTrip(t)
+	testSerialization_PriorityKeys(t)
+	testSerialization_EmptyTree(t)
+	testSerialization_CorruptBinary(t)
+	testSerialization_OutOfOrder(t)
+	testSerialization_InvalidJSON(t)
+	testSerialization_RejectedInputLeavesTreeUnchanged(t)
+	testSerialization_NoComparator(t)
+	testSerialization_ZeroValueTree(t)
+}
@@ -0,0 +1,187 @@
+package avlTree
+
+import (
+	"iter"
+)
+
+//Set operations treat trees as multisets of keys: a key inserted c times is
+//counted c times.  Union, Intersection, Difference and SymmetricDifference
+//split and join their inputs in O(m log(n/m + 1)), for trees of sizes m <= n,
+//rather than merging them in O(m + n).  Where both trees hold a key, the
+//result takes its nodes from first before second.  The result keeps the
+//comparator, augmentation and observer of first, or of second if first is
+//empty, and sets that observer on every subtree in O(n) if the other tree had
+//a different one.  first and second are consumed and must not be used
+//afterwards.  They may be the same tree, but distinct trees must not share
+//subtrees.
+
+//How a set operation treats keys held by only one tree, and how many nodes
+//with a key it keeps given how many of them each tree holds
+type setOperation struct {
+	keepFirstOnly  bool
+	keepSecondOnly bool
+	counts         func(firstCount int, secondCount int) (fromFirst int, fromSecond int)
+}
+
+var (
+	unionOperation = setOperation{true, true, func(firstCount int, secondCount int) (int, int) {
+		return firstCount, max(secondCount-firstCount, 0)
+	}}
+	intersectionOperation = setOperation{false, false, func(firstCount int, secondCount int) (int, int) {
+		return min(firstCount, secondCount), 0
+	}}
+	differenceOperation = setOperation{true, false, func(firstCount int, secondCount int) (int, int) {
+		return max(firstCount-secondCount, 0), 0
+	}}
+	symmetricDifferenceOperation = setOperation{true, true, func(firstCount int, secondCount int) (int, int) {
+		return max(firstCount-secondCount, 0), max(secondCount-firstCount, 0)
+	}}
+)
+
+//Returns a tree holding each key as many times as the tree holding it most.
+//Consumes first and second, which must not be used afterwards.
+func Union[K, V any](first *AvlTree[K, V], second *AvlTree[K, V]) *AvlTree[K, V] {
+	return combineTrees(first, second, unionOperation)
+}
+
+//Returns a tree holding each key as many times as the tree holding it least.
+//Consumes first and second, which must not be used afterwards.
+func Intersection[K, V any](first *AvlTree[K, V], second *AvlTree[K, V]) *AvlTree[K, V] {
+	return combineTrees(first, second, intersectionOperation)
+}
+
+//Returns a tree holding each key of first as many times as first holds it
+//more than second does.  Consumes first and second, which must not be used
+//afterwards.
+func Difference[K, V any](first *AvlTree[K, V], second *AvlTree[K, V]) *AvlTree[K, V] {
+	return combineTrees(first, second, differenceOperation)
+}
+
+//Returns a tree holding each key as many times as one tree holds it more than
+//the other does.  Consumes first and second, which must not be used afterwards.
+func SymmetricDifference[K, V any](first *AvlTree[K, V], second *AvlTree[K, V]) *AvlTree[K, V] {
+	return combineTrees(first, second, symmetricDifferenceOperation)
+}
+
+//Returns true iff both trees hold the same keys the same number of times, in O(n)
+func Equal[K, V any](first *AvlTree[K, V], second *AvlTree[K, V]) bool {
+	if Size(first) != Size(second) {
+		return false
+	}
+	if Size(first) == 0 {
+		return true
+	}
+	compare := comparatorOf(first, second)
+	nextSecond, stop := iter.Pull(second.All())
+	defer stop()
+	for node := range first.All() {
+		other, _ := nextSecond()
+		if compare(node.Key, other.Key) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//Returns true iff second holds every key of first at least as many times as
+//first does, in O(m log n) for trees of sizes m and n
+func IsSubset[K, V any](first *AvlTree[K, V], second *AvlTree[K, V]) bool {
+	if Size(first) > Size(second) {
+		return false
+	}
+	var previous *AvlNode[K, V]
+	for node := range first.All() {
+		if previous != nil && first.comparator()(previous.Key, node.Key) == 0 {
+			continue
+		}
+		previous = node
+		if CountRange(second, node.Key, node.Key) < CountRange(first, node.Key, node.Key) {
+			return false
+		}
+	}
+	return true
+}
+
+func combineTrees[K, V any](first *AvlTree[K, V], second *AvlTree[K, V], operation setOperation) *AvlTree[K, V] {
+	template := templateOf(first, second)
+	if first == second && first != nil {
+		//Splitting one tree as both inputs would corrupt it, but each key's
+		//count is the same in both, so the result is all of the tree or none of it
+		if fromFirst, _ := operation.counts(1, 1); fromFirst > 0 {
+			return first
+		}
+		return template
+	}
+	//Splitting the pivot tree at its own roots takes O(size) in total, so
+	//take pivots from the smaller tree
+	var combined *AvlTree[K, V]
+	if Size(first) <= Size(second) {
+		combined = combineSubtrees(nonEmptyOrNil(first), nonEmptyOrNil(second), true, operation, template)
+	} else {
+		combined = combineSubtrees(nonEmptyOrNil(second), nonEmptyOrNil(first), false, operation, template)
+	}
+	combined = orNewTree(combined, template)
+	unifyObserver(combined, template, first, second)
+	return combined
+}
+
+//Splits both trees at the key of pivot's root, combines the trees of lesser
+//and greater keys recursively, and joins the results around the nodes kept
+//with the pivot key.  pivotIsFirst tells which of first and second pivot is.
+func combineSubtrees[K, V any](pivot *AvlTree[K, V], other *AvlTree[K, V], pivotIsFirst bool, operation setOperation, template *AvlTree[K, V]) *AvlTree[K, V] {
+	if pivot.isEmpty() || other.isEmpty() {
+		keepPivot, keepOther := operation.keepFirstOnly, operation.keepSecondOnly
+		if !pivotIsFirst {
+			keepPivot, keepOther = keepOther, keepPivot
+		}
+		if !pivot.isEmpty() && keepPivot {
+			return pivot
+		}
+		if !other.isEmpty() && keepOther {
+			return other
+		}
+		return nil
+	}
+	key := pivot.root.Key
+	//Nodes with the pivot key may also sit at the inner edges of pivot's subtrees
+	pivotLess, pivotEqualLeft := splitSubtree(pivot.left, key)
+	pivotEqualRight, pivotGreater := splitSubtreeAfter(pivot.right, key)
+	pivotEqual := append(append(collectNodes(pivotEqualLeft), pivot.root), collectNodes(pivotEqualRight)...)
+	otherLess, otherRest := splitSubtree(other, key)
+	otherEqualTree, otherGreater := splitSubtreeAfter(otherRest, key)
+	otherEqual := collectNodes(otherEqualTree)
+
+	less := combineSubtrees(pivotLess, otherLess, pivotIsFirst, operation, template)
+	greater := combineSubtrees(pivotGreater, otherGreater, pivotIsFirst, operation, template)
+	firstEqual, secondEqual := pivotEqual, otherEqual
+	if !pivotIsFirst {
+		firstEqual, secondEqual = otherEqual, pivotEqual
+	}
+	fromFirst, fromSecond := operation.counts(len(firstEqual), len(secondEqual))
+	for _, node := range append(firstEqual[:fromFirst], secondEqual[:fromSecond]...) {
+		less = joinSubtrees(less, node, nil, template)
+	}
+	return concatSubtrees(less, greater, template)
+}
+
+//Returns trees with keys <= key and keys > key, either of which may be nil
+func splitSubtreeAfter[K, V any](tree *AvlTree[K, V], key K) (*AvlTree[K, V], *AvlTree[K, V]) {
+	if tree.isEmpty() {
+		return nil, nil
+	}
+	if tree.comparator()(tree.root.Key, key) <= 0 {
+		atMost, greater := splitSubtreeAfter(tree.right, key)
+		return joinSubtrees(tree.left, tree.root, atMost, tree), greater
+	}
+	atMost, greater := splitSubtreeAfter(tree.left, key)
+	return atMost, joinSubtrees(greater, tree.root, tree.right, tree)
+}
+
+//Joins two trees, either of which may be nil, where all keys in left are <= all keys in right
+func concatSubtrees[K, V any](left *AvlTree[K, V], right *AvlTree[K, V], template *AvlTree[K, V]) *AvlTree[K, V] {
+	if left.isEmpty() {
+		return nonEmptyOrNil(right)
+	}
+	rest, max := splitMax(left)
+	return joinSubtrees(nonEmptyOrNil(rest), max, nonEmptyOrNil(right), template)
+}
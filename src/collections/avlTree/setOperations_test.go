@@ -0,0 +1,198 @@
+package avlTree
+
+import (
+	"math/rand"
+	"runtime/debug"
+	"slices"
+	"testing"
+)
+
+//Returns the sorted keys of the multiset holding each key counts(firstCount, secondCount) times
+func expectedSetKeys(first []int, second []int, counts func(int, int) (int, int)) []int {
+	firstCounts, secondCounts := map[int]int{}, map[int]int{}
+	for _, key := range first {
+		firstCounts[key]++
+	}
+	for _, key := range second {
+		secondCounts[key]++
+	}
+	keys := []int{}
+	for key := range 64 {
+		fromFirst, fromSecond := counts(firstCounts[key], secondCounts[key])
+		for range fromFirst + fromSecond {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func verifySetResult(t *testing.T, tree *AvlTree[int, string], expected []int) {
+	verifyIteratedKeys(t, tree.All(), expected)
+	verifyAvlInvariants(t, tree)
+	if err := Validate(tree); err != nil {
+		debug.PrintStack()
+		dumpTree(t, "result", tree)
+		t.Errorf("Validate(result) == %v", err)
+	}
+}
+
+func createRandomKeys(rng *rand.Rand, n int) []int {
+	keys := make([]int, n)
+	for i := range keys {
+		keys[i] = rng.Intn(64)
+	}
+	return keys
+}
+
+func testSetOperations_Small(t *testing.T) {
+	verifySetResult(t, Union(createIntTree(1, 3, 5), createIntTree(2, 3, 4)), []int{1, 2, 3, 4, 5})
+	verifySetResult(t, Intersection(createIntTree(1, 3, 5), createIntTree(2, 3, 4, 5)), []int{3, 5})
+	verifySetResult(t, Difference(createIntTree(1, 3, 5), createIntTree(2, 3, 4)), []int{1, 5})
+	verifySetResult(t, SymmetricDifference(createIntTree(1, 3, 5), createIntTree(2, 3, 4)), []int{1, 2, 4, 5})
+}
+
+func testSetOperations_Duplicates(t *testing.T) {
+	verifySetResult(t, Union(createIntTree(1, 1, 2), createIntTree(1, 1, 1, 2)), []int{1, 1, 1, 2})
+	verifySetResult(t, Intersection(createIntTree(1, 1, 2, 2), createIntTree(1, 2, 2, 2)), []int{1, 2, 2})
+	verifySetResult(t, Difference(createIntTree(1, 1, 1, 2), createIntTree(1, 2, 2)), []int{1, 1})
+	verifySetResult(t, SymmetricDifference(createIntTree(1, 1, 1, 2), createIntTree(1, 2, 2)), []int{1, 1, 2})
+}
+
+//Passing one tree as both arguments gives the result for two equal trees
+func testSetOperations_SameTree(t *testing.T) {
+	rng := rand.New(rand.NewSource(20))
+	operations := []struct {
+		combine func(*AvlTree[int, string], *AvlTree[int, string]) *AvlTree[int, string]
+		keepAll bool
+	}{
+		{Union[int, string], true},
+		{Intersection[int, string], true},
+		{Difference[int, string], false},
+		{SymmetricDifference[int, string], false},
+	}
+	for round := 0; round < 50; round++ {
+		keys := createRandomKeys(rng, 1+rng.Intn(80))
+		for _, operation := range operations {
+			tree := NewAvlTree[int, string]()
+			for _, key := range keys {
+				Insert(&tree, NewAvlNode(key, ""))
+			}
+			expected := []int{}
+			if operation.keepAll {
+				expected = collectKeys(tree.All())
+			}
+			verifySetResult(t, operation.combine(tree, tree), expected)
+		}
+	}
+}
+
+func testSetOperations_EmptyAndNil(t *testing.T) {
+	verifySetResult(t, Union(NewAvlTree[int, string](), createIntTree(1, 2)), []int{1, 2})
+	verifySetResult(t, Union(createIntTree(1, 2), nil), []int{1, 2})
+	verifySetResult(t, Intersection(createIntTree(1, 2), NewAvlTree[int, string]()), []int{})
+	verifySetResult(t, Difference(createIntTree(1, 2), nil), []int{1, 2})
+	verifySetResult(t, Difference(NewAvlTree[int, string](), createIntTree(1, 2)), []int{})
+	verifySetResult(t, SymmetricDifference(nil, createIntTree(1, 2)), []int{1, 2})
+	verifySizeVal(t, Union[int, string](nil, nil), 0)
+
+	//Results of emptied trees keep the comparator
+	result := Intersection(createIntTree(1), createIntTree(2))
+	Insert(&result, NewAvlNode(3, ""))
+	Insert(&result, NewAvlNode(0, ""))
+	verifySetResult(t, result, []int{0, 3})
+}
+
+func testSetOperations_RandomAgainstModel(t *testing.T) {
+	operations := []struct {
+		name    string
+		combine func(*AvlTree[int, string], *AvlTree[int, string]) *AvlTree[int, string]
+		counts  func(int, int) (int, int)
+	}{
+		{"Union", Union[int, string], unionOperation.counts},
+		{"Intersection", Intersection[int, string], intersectionOperation.counts},
+		{"Difference", Difference[int, string], differenceOperation.counts},
+		{"SymmetricDifference", SymmetricDifference[int, string], symmetricDifferenceOperation.counts},
+	}
+	rng := rand.New(rand.NewSource(1))
+	sizes := []int{0, 1, 5, 40, 300}
+	for _, operation := range operations {
+		for _, firstSize := range sizes {
+			for _, secondSize := range sizes {
+				first, second := createRandomKeys(rng, firstSize), createRandomKeys(rng, secondSize)
+				expected := expectedSetKeys(first, second, operation.counts)
+				result := operation.combine(createIntTree(first...), createIntTree(second...))
+				if !slices.Equal(collectKeys(result.All()), expected) {
+					t.Errorf("%s of %d and %d keys == %v, expected %v", operation.name, firstSize, secondSize, collectKeys(result.All()), expected)
+				}
+				verifyAvlInvariants(t, result)
+				if err := Validate(result); err != nil {
+					t.Errorf("%s of %d and %d keys: Validate(result) == %v", operation.name, firstSize, secondSize, err)
+				}
+			}
+		}
+	}
+}
+
+//PriorityKeys order by priority, then data, so keys with equal priority are distinct
+func testSetOperations_PriorityKeys(t *testing.T) {
+	firstNodes := []*priorityNode{createAvlNode("a", 1), createAvlNode("b", 1), createAvlNode("a", 5)}
+	secondNodes := []*priorityNode{createAvlNode("b", 1), createAvlNode("c", 1), createAvlNode("a", 3)}
+	union := Union(FromSortedFunc(firstNodes, ComparePriorityKeys), FromSortedFunc(secondNodes, ComparePriorityKeys))
+	expected := []*priorityNode{firstNodes[0], firstNodes[1], secondNodes[1], secondNodes[2], firstNodes[2]}
+	nodes := collectNodes(union)
+	if len(nodes) != len(expected) {
+		t.Fatalf("Union has %d nodes, expected %d", len(nodes), len(expected))
+	}
+	for i := range nodes {
+		//Keys held by both trees keep first's node
+		verifyNodePointersEqual(t, nodes[i], expected[i])
+	}
+
+	intersection := Intersection(FromSortedFunc(firstNodes, ComparePriorityKeys), FromSortedFunc(secondNodes, ComparePriorityKeys))
+	if Size(intersection) != 1 || Min(intersection) != firstNodes[1] {
+		t.Errorf("Intersection == %v, expected only %v", collectNodes(intersection), firstNodes[1])
+	}
+}
+
+func verifyEqual(t *testing.T, first *AvlTree[int, string], second *AvlTree[int, string], expected bool) {
+	if Equal(first, second) != expected {
+		debug.PrintStack()
+		t.Errorf("Equal(%v, %v) == %v, expected %v", collectKeys(first.All()), collectKeys(second.All()), !expected, expected)
+	}
+}
+
+func verifyIsSubset(t *testing.T, first *AvlTree[int, string], second *AvlTree[int, string], expected bool) {
+	if IsSubset(first, second) != expected {
+		debug.PrintStack()
+		t.Errorf("IsSubset(%v, %v) == %v, expected %v", collectKeys(first.All()), collectKeys(second.All()), !expected, expected)
+	}
+}
+
+func testEqual(t *testing.T) {
+	verifyEqual(t, nil, NewAvlTree[int, string](), true)
+	verifyEqual(t, createIntTree(3, 1, 2), createIntTree(1, 2, 3), true)
+	verifyEqual(t, createIntTree(1, 2, 2), createIntTree(1, 2, 2), true)
+	verifyEqual(t, createIntTree(1, 2, 2), createIntTree(1, 1, 2), false)
+	verifyEqual(t, createIntTree(1, 2), createIntTree(1, 2, 3), false)
+}
+
+func testIsSubset(t *testing.T) {
+	verifyIsSubset(t, nil, createIntTree(1), true)
+	verifyIsSubset(t, createIntTree(1), nil, false)
+	verifyIsSubset(t, createIntTree(1, 3), createIntTree(1, 2, 3), true)
+	verifyIsSubset(t, createIntTree(1, 2, 3), createIntTree(1, 2, 3), true)
+	verifyIsSubset(t, createIntTree(1, 4), createIntTree(1, 2, 3), false)
+	verifyIsSubset(t, createIntTree(2, 2), createIntTree(1, 2, 3), false)
+	verifyIsSubset(t, createIntTree(2, 2), createIntTree(2, 1, 2), true)
+}
+
+func TestSetOperations(t *testing.T) {
+	testSetOperations_Small(t)
+	testSetOperations_Duplicates(t)
+	testSetOperations_SameTree(t)
+	testSetOperations_EmptyAndNil(t)
+	testSetOperations_RandomAgainstModel(t)
+	testSetOperations_PriorityKeys(t)
+	testEqual(t)
+	testIsSubset(t)
+}
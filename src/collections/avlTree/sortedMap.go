@@ -0,0 +1,84 @@
+package avlTree
+
+import (
+	"cmp"
+	"iter"
+)
+
+//Map from K to V which keeps its keys in ascending order.  Like Go's built-in
+//map, each key appears at most once, Put overwrites the value of an existing
+//key and Get of a missing key returns V's zero value.
+type SortedMap[K, V any] struct {
+	tree *AvlTree[K, V]
+}
+
+//Creates an empty sorted map ordered by the natural ordering of K
+func NewSortedMap[K cmp.Ordered, V any]() *SortedMap[K, V] {
+	return NewSortedMapFunc[K, V](cmp.Compare[K])
+}
+
+//Creates an empty sorted map ordered by compare
+func NewSortedMapFunc[K, V any](compare func(a, b K) int) *SortedMap[K, V] {
+	return &SortedMap[K, V]{NewAvlTreeFunc[K, V](compare)}
+}
+
+//Returns the value stored for key and true, or V's zero value and false if key is not in the map
+func (smap *SortedMap[K, V]) Get(key K) (V, bool) {
+	subtree := findSubtreeWithNodeAsRoot(smap.tree, &AvlNode[K, V]{Key: key})
+	if subtree == nil {
+		var zero V
+		return zero, false
+	}
+	return subtree.root.Value, true
+}
+
+//Stores value for key, replacing any value already stored for key
+func (smap *SortedMap[K, V]) Put(key K, value V) {
+	node := NewAvlNode(key, value)
+	subtree := findSubtreeWithNodeAsRoot(smap.tree, node)
+	if subtree != nil {
+		subtree.root = node
+	} else {
+		Insert(&smap.tree, node)
+	}
+}
+
+//Removes key from the map, does nothing if key is not in the map
+func (smap *SortedMap[K, V]) Delete(key K) {
+	Remove(&smap.tree, &AvlNode[K, V]{Key: key})
+}
+
+//Returns the number of keys in the map
+func (smap *SortedMap[K, V]) Len() int {
+	return Size(smap.tree)
+}
+
+//Removes every key from the map
+func (smap *SortedMap[K, V]) Clear() {
+	//A zero-value map has no tree until its first Put
+	if smap.tree != nil {
+		smap.tree = NewAvlTreeFunc[K, V](smap.tree.compare)
+	}
+}
+
+//Returns an iterator over the map's keys in ascending order
+func (smap *SortedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for node := range smap.tree.All() {
+			if !yield(node.Key) {
+				return
+			}
+		}
+	}
+}
+
+//Returns an iterator over the map's key-value pairs in ascending key order
+func (smap *SortedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for node := range smap.tree.All() {
+			if !yield(node.Key, node.Value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package avlTree
+
+import (
+	"runtime/debug"
+	"slices"
+	"testing"
+)
+
+func verifyMapGet(t *testing.T, smap *SortedMap[string, int], key string, expectedVal int, expectedOk bool) {
+	val, ok := smap.Get(key)
+	if val != expectedVal || ok != expectedOk {
+		t.Errorf("Get(%q) == (%d, %t), expected (%d, %t)", key, val, ok, expectedVal, expectedOk)
+		debug.PrintStack()
+	}
+}
+
+func verifyMapKeys(t *testing.T, smap *SortedMap[string, int], expected []string) {
+	keys := slices.Collect(smap.Keys())
+	if !slices.Equal(keys, expected) {
+		t.Errorf("Keys() == %v, expected %v", keys, expected)
+		debug.PrintStack()
+	}
+	if smap.Len() != len(expected) {
+		t.Errorf("Len() == %d, expected %d", smap.Len(), len(expected))
+		debug.PrintStack()
+	}
+}
+
+func testSortedMap_Empty(t *testing.T) {
+	smap := NewSortedMap[string, int]()
+	verifyMapGet(t, smap, "missing", 0, false)
+	verifyMapKeys(t, smap, []string{})
+	smap.Delete("missing")
+	verifyMapKeys(t, smap, []string{})
+}
+
+func testSortedMap_PutAndGet(t *testing.T) {
+	smap := NewSortedMap[string, int]()
+	smap.Put("banana", 2)
+	smap.Put("apple", 1)
+	smap.Put("cherry", 3)
+	verifyMapGet(t, smap, "apple", 1, true)
+	verifyMapGet(t, smap, "banana", 2, true)
+	verifyMapGet(t, smap, "cherry", 3, true)
+	verifyMapGet(t, smap, "durian", 0, false)
+	verifyMapKeys(t, smap, []string{"apple", "banana", "cherry"})
+}
+
+func testSortedMap_PutOverwrites(t *testing.T) {
+	smap := NewSortedMap[string, int]()
+	for i := 0; i < 10; i++ {
+		smap.Put("key", i)
+		smap.Put("other", -i)
+	}
+	verifyMapGet(t, smap, "key", 9, true)
+	verifyMapGet(t, smap, "other", -9, true)
+	verifyMapKeys(t, smap, []string{"key", "other"})
+	if err := Validate(smap.tree); err != nil {
+		t.Errorf("Validate(smap.tree) == %v", err)
+	}
+}
+
+func testSortedMap_Delete(t *testing.T) {
+	smap := NewSortedMap[string, int]()
+	smap.Put("a", 1)
+	smap.Put("b", 2)
+	smap.Put("c", 3)
+	smap.Delete("b")
+	smap.Delete("b")
+	smap.Delete("z")
+	verifyMapGet(t, smap, "b", 0, false)
+	verifyMapKeys(t, smap, []string{"a", "c"})
+	smap.Put("b", 20)
+	verifyMapGet(t, smap, "b", 20, true)
+}
+
+func testSortedMap_Clear(t *testing.T) {
+	smap := NewSortedMap[string, int]()
+	smap.Put("a", 1)
+	smap.Put("b", 2)
+	smap.Clear()
+	verifyMapKeys(t, smap, []string{})
+	smap.Put("c", 3)
+	verifyMapKeys(t, smap, []string{"c"})
+}
+
+//A zero-value map is empty, and ordered by K's natural ordering once filled
+func testSortedMap_ZeroValue(t *testing.T) {
+	var smap SortedMap[string, int]
+	smap.Clear()
+	verifyMapKeys(t, &smap, []string{})
+	smap.Put("b", 2)
+	smap.Put("a", 1)
+	verifyMapKeys(t, &smap, []string{"a", "b"})
+	smap.Clear()
+	verifyMapKeys(t, &smap, []string{})
+	smap.Put("c", 3)
+	verifyMapGet(t, &smap, "c", 3, true)
+}
+
+func testSortedMap_All(t *testing.T) {
+	smap := NewSortedMap[string, int]()
+	smap.Put("b", 2)
+	smap.Put("a", 1)
+	smap.Put("c", 3)
+	values := []int{}
+	for key, val := range smap.All() {
+		if key == "c" {
+			break
+		}
+		values = append(values, val)
+	}
+	if !slices.Equal(values, []int{1, 2}) {
+		t.Errorf("values == %v, expected [1 2]", values)
+	}
+}
+
+func testSortedMap_MatchesBuiltinMap(t *testing.T) {
+	smap := NewSortedMapFunc[PriorityKey, int](ComparePriorityKeys)
+	builtin := map[PriorityKey]int{}
+	for i := 0; i < 500; i++ {
+		key := PriorityKey{string(rune('a' + i%7)), i % 13}
+		switch i % 5 {
+		case 0, 1, 2:
+			smap.Put(key, i)
+			builtin[key] = i
+		case 3:
+			smap.Delete(key)
+			delete(builtin, key)
+		case 4:
+			val, ok := smap.Get(key)
+			expectedVal, expectedOk := builtin[key]
+			if val != expectedVal || ok != expectedOk {
+				t.Errorf("Get(%v) == (%d, %t), expected (%d, %t)", key, val, ok, expectedVal, expectedOk)
+			}
+		}
+	}
+	if smap.Len() != len(builtin) {
+		t.Errorf("Len() == %d, expected %d", smap.Len(), len(builtin))
+	}
+	keys := slices.Collect(smap.Keys())
+	if !slices.IsSortedFunc(keys, ComparePriorityKeys) {
+		t.Errorf("Keys() == %v, expected ascending order", keys)
+	}
+	for _, key := range keys {
+		if val, _ := smap.Get(key); val != builtin[key] {
+			t.Errorf("Get(%v) == %d, expected %d", key, val, builtin[key])
+		}
+	}
+}
+
+func TestSortedMap(t *testing.T) {
+	testSortedMap_Empty(t)
+	testSortedMap_PutAndGet(t)
+	testSortedMap_PutOverwrites(t)
+	testSortedMap_Delete(t)
+	testSortedMap_Clear(t)
+	testSortedMap_ZeroValue(t)
+	testSortedMap_All(t)
+	testSortedMap_MatchesBuiltinMap(t)
+}
@@ -0,0 +1,97 @@
+package avlTree
+
+import (
+	"cmp"
+	"iter"
+)
+
+//Sorted collection which may hold equal elements.  Unlike inserting duplicates
+//into an AvlTree, each distinct element is stored once in a single node whose
+//value counts its copies, so duplicates do not add to the tree's height.
+type SortedMultiset[K any] struct {
+	tree *AvlTree[K, int]
+	len  int
+}
+
+//Creates an empty multiset ordered by the natural ordering of K
+func NewSortedMultiset[K cmp.Ordered]() *SortedMultiset[K] {
+	return NewSortedMultisetFunc[K](cmp.Compare[K])
+}
+
+//Creates an empty multiset ordered by compare
+func NewSortedMultisetFunc[K any](compare func(a, b K) int) *SortedMultiset[K] {
+	return &SortedMultiset[K]{NewAvlTreeFunc[K, int](compare), 0}
+}
+
+//Adds one copy of key
+func (mset *SortedMultiset[K]) Add(key K) {
+	mset.len++
+	if subtree := mset.find(key); subtree != nil {
+		subtree.root.Value++
+	} else {
+		Insert(&mset.tree, NewAvlNode(key, 1))
+	}
+}
+
+//Removes one copy of key, returns false if key is not in the multiset
+func (mset *SortedMultiset[K]) RemoveOne(key K) bool {
+	subtree := mset.find(key)
+	if subtree == nil {
+		return false
+	}
+	mset.len--
+	if subtree.root.Value > 1 {
+		subtree.root.Value--
+	} else {
+		Remove(&mset.tree, subtree.root)
+	}
+	return true
+}
+
+//Removes every copy of key, returns the number of copies removed
+func (mset *SortedMultiset[K]) RemoveAll(key K) int {
+	subtree := mset.find(key)
+	if subtree == nil {
+		return 0
+	}
+	count := subtree.root.Value
+	mset.len -= count
+	Remove(&mset.tree, subtree.root)
+	return count
+}
+
+//Returns the number of copies of key in the multiset
+func (mset *SortedMultiset[K]) Count(key K) int {
+	if subtree := mset.find(key); subtree != nil {
+		return subtree.root.Value
+	}
+	return 0
+}
+
+//Returns the number of elements in the multiset, counting every copy
+func (mset *SortedMultiset[K]) Len() int {
+	return mset.len
+}
+
+//Returns the number of distinct elements in the multiset
+func (mset *SortedMultiset[K]) Distinct() int {
+	return Size(mset.tree)
+}
+
+//Returns an iterator over the multiset's elements in ascending order, repeating each once per copy
+func (mset *SortedMultiset[K]) All() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for node := range mset.tree.All() {
+			for i := 0; i < node.Value; i++ {
+				if !yield(node.Key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+//Returns the subtree whose root holds key, or nil if key is not in the multiset
+func (mset *SortedMultiset[K]) find(key K) *AvlTree[K, int] {
+	return findSubtreeWithNodeAsRoot(mset.tree, &AvlNode[K, int]{Key: key})
+}
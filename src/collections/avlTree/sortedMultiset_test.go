@@ -0,0 +1,139 @@
+package avlTree
+
+import (
+	"runtime/debug"
+	"slices"
+	"testing"
+)
+
+func verifyMultiset(t *testing.T, mset *SortedMultiset[int], expected []int) {
+	elements := slices.Collect(mset.All())
+	if !slices.Equal(elements, expected) {
+		t.Errorf("All() == %v, expected %v", elements, expected)
+		debug.PrintStack()
+	}
+	if mset.Len() != len(expected) {
+		t.Errorf("Len() == %d, expected %d", mset.Len(), len(expected))
+		debug.PrintStack()
+	}
+	distinct := len(slices.Compact(slices.Clone(expected)))
+	if mset.Distinct() != distinct {
+		t.Errorf("Distinct() == %d, expected %d", mset.Distinct(), distinct)
+		debug.PrintStack()
+	}
+	if err := Validate(mset.tree); err != nil {
+		t.Errorf("Validate(mset.tree) == %v", err)
+	}
+}
+
+func verifyCount(t *testing.T, mset *SortedMultiset[int], key int, expected int) {
+	if count := mset.Count(key); count != expected {
+		t.Errorf("Count(%d) == %d, expected %d", key, count, expected)
+		debug.PrintStack()
+	}
+}
+
+func testSortedMultiset_Empty(t *testing.T) {
+	mset := NewSortedMultiset[int]()
+	verifyMultiset(t, mset, []int{})
+	verifyCount(t, mset, 1, 0)
+	if mset.RemoveOne(1) {
+		t.Errorf("RemoveOne(1) == true, expected false")
+	}
+	if removed := mset.RemoveAll(1); removed != 0 {
+		t.Errorf("RemoveAll(1) == %d, expected 0", removed)
+	}
+}
+
+func testSortedMultiset_AddRepeats(t *testing.T) {
+	mset := NewSortedMultiset[int]()
+	for _, key := range []int{5, 3, 5, 1, 5, 3} {
+		mset.Add(key)
+	}
+	verifyMultiset(t, mset, []int{1, 3, 3, 5, 5, 5})
+	verifyCount(t, mset, 5, 3)
+	verifyCount(t, mset, 3, 2)
+	verifyCount(t, mset, 4, 0)
+}
+
+func testSortedMultiset_DuplicatesShareNode(t *testing.T) {
+	mset := NewSortedMultiset[int]()
+	for i := 0; i < 1000; i++ {
+		mset.Add(7)
+	}
+	if mset.tree.getHeight() != 0 {
+		t.Errorf("tree height == %d, expected 0", mset.tree.getHeight())
+	}
+	verifyCount(t, mset, 7, 1000)
+}
+
+func testSortedMultiset_RemoveOne(t *testing.T) {
+	mset := NewSortedMultiset[int]()
+	for _, key := range []int{2, 2, 4} {
+		mset.Add(key)
+	}
+	if !mset.RemoveOne(2) {
+		t.Errorf("RemoveOne(2) == false, expected true")
+	}
+	verifyMultiset(t, mset, []int{2, 4})
+	mset.RemoveOne(2)
+	verifyMultiset(t, mset, []int{4})
+	if mset.RemoveOne(2) {
+		t.Errorf("RemoveOne(2) of removed element == true, expected false")
+	}
+}
+
+func testSortedMultiset_RemoveAll(t *testing.T) {
+	mset := NewSortedMultiset[int]()
+	for _, key := range []int{2, 2, 2, 4, 1} {
+		mset.Add(key)
+	}
+	if removed := mset.RemoveAll(2); removed != 3 {
+		t.Errorf("RemoveAll(2) == %d, expected 3", removed)
+	}
+	verifyMultiset(t, mset, []int{1, 4})
+	mset.Add(2)
+	verifyMultiset(t, mset, []int{1, 2, 4})
+}
+
+func testSortedMultiset_StopsEarly(t *testing.T) {
+	mset := NewSortedMultiset[int]()
+	for i := 0; i < 5; i++ {
+		mset.Add(1)
+	}
+	mset.Add(2)
+	count := 0
+	for range mset.All() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 {
+		t.Errorf("iterated %d elements, expected to stop at 3", count)
+	}
+}
+
+func testSortedMultiset_PriorityKeys(t *testing.T) {
+	mset := NewSortedMultisetFunc[PriorityKey](ComparePriorityKeys)
+	mset.Add(PriorityKey{"job", 2})
+	mset.Add(PriorityKey{"job", 2})
+	mset.Add(PriorityKey{"job", 1})
+	if count := mset.Count(PriorityKey{"job", 2}); count != 2 {
+		t.Errorf("Count({job 2}) == %d, expected 2", count)
+	}
+	expected := []PriorityKey{{"job", 1}, {"job", 2}, {"job", 2}}
+	if elements := slices.Collect(mset.All()); !slices.Equal(elements, expected) {
+		t.Errorf("All() == %v, expected %v", elements, expected)
+	}
+}
+
+func TestSortedMultiset(t *testing.T) {
+	testSortedMultiset_Empty(t)
+	testSortedMultiset_AddRepeats(t)
+	testSortedMultiset_DuplicatesShareNode(t)
+	testSortedMultiset_RemoveOne(t)
+	testSortedMultiset_RemoveAll(t)
+	testSortedMultiset_StopsEarly(t)
+	testSortedMultiset_PriorityKeys(t)
+}
@@ -0,0 +1,186 @@
+package avlTree
+
+import (
+	"cmp"
+	"iter"
+	"math/rand"
+)
+
+//OrderedSet backed by a treap: a binary search tree on keys which is also a
+//max-heap on priorities drawn at random when each node is inserted.  The
+//tree has the shape of one built by inserting in random order, so its
+//expected height is O(log n) whatever order keys arrive in, without storing
+//any balance information beyond the priorities.
+type Treap[K, V any] struct {
+	root    *treapNode[K, V]
+	size    int
+	compare func(K, K) int
+}
+
+type treapNode[K, V any] struct {
+	node     *AvlNode[K, V]
+	priority int64
+	left     *treapNode[K, V]
+	right    *treapNode[K, V]
+}
+
+var _ OrderedSet[int, struct{}] = (*Treap[int, struct{}])(nil)
+
+//Creates an empty treap ordered by the natural ordering of K
+func NewTreap[K cmp.Ordered, V any]() *Treap[K, V] {
+	return NewTreapFunc[K, V](cmp.Compare[K])
+}
+
+//Creates an empty treap ordered by compare
+func NewTreapFunc[K, V any](compare func(a, b K) int) *Treap[K, V] {
+	return &Treap[K, V]{compare: compare}
+}
+
+func (treap *Treap[K, V]) Insert(node *AvlNode[K, V]) {
+	if node == nil {
+		return
+	}
+	treap.root = treap.insert(treap.root, &treapNode[K, V]{node: node, priority: rand.Int63()})
+	treap.size++
+}
+
+func (treap *Treap[K, V]) Remove(node *AvlNode[K, V]) {
+	if node == nil {
+		return
+	}
+	slot := &treap.root
+	for *slot != nil {
+		rootToNodeCompare := treap.compare((*slot).node.Key, node.Key)
+		if rootToNodeCompare == 0 {
+			*slot = mergeTreaps((*slot).left, (*slot).right)
+			treap.size--
+			return
+		}
+		if rootToNodeCompare > 0 {
+			slot = &(*slot).left
+		} else {
+			slot = &(*slot).right
+		}
+	}
+}
+
+func (treap *Treap[K, V]) RemoveMax() {
+	if treap.root == nil {
+		return
+	}
+	slot := &treap.root
+	for (*slot).right != nil {
+		slot = &(*slot).right
+	}
+	*slot = (*slot).left
+	treap.size--
+}
+
+func (treap *Treap[K, V]) Has(node *AvlNode[K, V]) bool {
+	if node == nil {
+		return false
+	}
+	for subtree := treap.root; subtree != nil; {
+		rootToNodeCompare := treap.compare(subtree.node.Key, node.Key)
+		if rootToNodeCompare == 0 {
+			return true
+		}
+		if rootToNodeCompare > 0 {
+			subtree = subtree.left
+		} else {
+			subtree = subtree.right
+		}
+	}
+	return false
+}
+
+func (treap *Treap[K, V]) Min() *AvlNode[K, V] {
+	if treap.root == nil {
+		return nil
+	}
+	subtree := treap.root
+	for subtree.left != nil {
+		subtree = subtree.left
+	}
+	return subtree.node
+}
+
+func (treap *Treap[K, V]) Max() *AvlNode[K, V] {
+	if treap.root == nil {
+		return nil
+	}
+	subtree := treap.root
+	for subtree.right != nil {
+		subtree = subtree.right
+	}
+	return subtree.node
+}
+
+func (treap *Treap[K, V]) UpdateNode(node *AvlNode[K, V], newNode *AvlNode[K, V]) bool {
+	if newNode == nil || !treap.Has(node) {
+		return false
+	}
+	treap.Remove(node)
+	treap.Insert(newNode)
+	return true
+}
+
+func (treap *Treap[K, V]) Len() int {
+	return treap.size
+}
+
+func (treap *Treap[K, V]) All() iter.Seq[*AvlNode[K, V]] {
+	return func(yield func(*AvlNode[K, V]) bool) {
+		walkTreapAscending(treap.root, yield)
+	}
+}
+
+//Inserts leaf below subtree by key, then rotates it up while its priority
+//exceeds its parent's.  Returns the new subtree root.
+func (treap *Treap[K, V]) insert(subtree *treapNode[K, V], leaf *treapNode[K, V]) *treapNode[K, V] {
+	if subtree == nil {
+		return leaf
+	}
+	if treap.compare(subtree.node.Key, leaf.node.Key) >= 0 {
+		subtree.left = treap.insert(subtree.left, leaf)
+		if subtree.left.priority > subtree.priority {
+			left := subtree.left
+			subtree.left = left.right
+			left.right = subtree
+			return left
+		}
+	} else {
+		subtree.right = treap.insert(subtree.right, leaf)
+		if subtree.right.priority > subtree.priority {
+			right := subtree.right
+			subtree.right = right.left
+			right.left = subtree
+			return right
+		}
+	}
+	return subtree
+}
+
+//Merges two treaps where every key in left is <= every key in right
+func mergeTreaps[K, V any](left *treapNode[K, V], right *treapNode[K, V]) *treapNode[K, V] {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	if left.priority > right.priority {
+		left.right = mergeTreaps(left.right, right)
+		return left
+	}
+	right.left = mergeTreaps(left, right.left)
+	return right
+}
+
+//In-order traversal, returns false iff yield requested a stop
+func walkTreapAscending[K, V any](subtree *treapNode[K, V], yield func(*AvlNode[K, V]) bool) bool {
+	if subtree == nil {
+		return true
+	}
+	return walkTreapAscending(subtree.left, yield) && yield(subtree.node) && walkTreapAscending(subtree.right, yield)
+}
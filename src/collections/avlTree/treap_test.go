@@ -0,0 +1,75 @@
+package avlTree
+
+import (
+	"fmt"
+	"testing"
+)
+
+//Checks that treap is ordered by key, max-heap ordered by priority, and holds treap.size nodes
+func validateTreap[K, V any](treap *Treap[K, V]) error {
+	count, err := validateTreapSubtree(treap, treap.root, nil, nil)
+	if err == nil && count != treap.size {
+		err = fmt.Errorf("treap holds %d nodes, size is %d", count, treap.size)
+	}
+	return err
+}
+
+//Returns the number of nodes in subtree
+func validateTreapSubtree[K, V any](treap *Treap[K, V], subtree *treapNode[K, V], lo *AvlNode[K, V], hi *AvlNode[K, V]) (int, error) {
+	if subtree == nil {
+		return 0, nil
+	}
+	if (lo != nil && treap.compare(lo.Key, subtree.node.Key) > 0) || (hi != nil && treap.compare(subtree.node.Key, hi.Key) > 0) {
+		return 0, fmt.Errorf("node %v is out of order", subtree.node)
+	}
+	for _, child := range []*treapNode[K, V]{subtree.left, subtree.right} {
+		if child != nil && child.priority > subtree.priority {
+			return 0, fmt.Errorf("node %v has priority above its parent %v", child.node, subtree.node)
+		}
+	}
+	leftCount, err := validateTreapSubtree(treap, subtree.left, lo, subtree.node)
+	if err != nil {
+		return 0, err
+	}
+	rightCount, err := validateTreapSubtree(treap, subtree.right, subtree.node, hi)
+	if err != nil {
+		return 0, err
+	}
+	return leftCount + rightCount + 1, nil
+}
+
+func treapHeight[K, V any](subtree *treapNode[K, V]) int {
+	if subtree == nil {
+		return -1
+	}
+	return max(treapHeight(subtree.left), treapHeight(subtree.right)) + 1
+}
+
+func testTreap_AscendingKeys(t *testing.T) {
+	treap := NewTreap[int, string]()
+	for key := 0; key < 1<<12; key++ {
+		treap.Insert(NewAvlNode(key, ""))
+	}
+	if err := validateTreap(treap); err != nil {
+		t.Errorf("validateTreap(treap) == %v", err)
+	}
+	//Expected height is about 3 log2(n), far below the n - 1 of an unbalanced tree
+	if height := treapHeight(treap.root); height > 80 {
+		t.Errorf("height == %d, expected <= 80", height)
+	}
+}
+
+func testTreap_RejectsBrokenHeap(t *testing.T) {
+	treap := NewTreap[int, string]()
+	treap.root = &treapNode[int, string]{node: NewAvlNode(2, ""), priority: 1}
+	treap.root.left = &treapNode[int, string]{node: NewAvlNode(1, ""), priority: 5}
+	treap.size = 2
+	if validateTreap(treap) == nil {
+		t.Errorf("validateTreap accepted a child with priority above its parent")
+	}
+}
+
+func TestTreap(t *testing.T) {
+	testTreap_AscendingKeys(t)
+	testTreap_RejectsBrokenHeap(t)
+}
@@ -0,0 +1,56 @@
+package avlTree
+
+import (
+	"fmt"
+)
+
+//Returns an error describing the first broken invariant found in tree, or nil if
+//tree is a valid AVL tree: keys are in non-decreasing in-order sequence, every
+//cached height and size matches the subtree below it, and the heights of every
+//node's children differ by at most one
+func Validate[K, V any](tree *AvlTree[K, V]) error {
+	if tree.isEmpty() {
+		return nil
+	}
+	if tree.resolveComparator() == nil {
+		return fmt.Errorf("tree has no comparator")
+	}
+	var prev *AvlNode[K, V]
+	_, _, err := validateSubtree(tree, &prev)
+	return err
+}
+
+//Validates tree in order, tracking the previously visited node in *prev.
+//Returns the actual height and size of tree.
+func validateSubtree[K, V any](tree *AvlTree[K, V], prev **AvlNode[K, V]) (int, int, error) {
+	if tree == nil {
+		return -1, 0, nil
+	}
+	if tree.root == nil {
+		return 0, 0, fmt.Errorf("empty subtree %p found below a non-empty root", tree)
+	}
+	leftHeight, leftSize, err := validateSubtree(tree.left, prev)
+	if err != nil {
+		return 0, 0, err
+	}
+	if *prev != nil && tree.comparator()((*prev).Key, tree.root.Key) > 0 {
+		return 0, 0, fmt.Errorf("node %v is ordered after greater node %v", tree.root, *prev)
+	}
+	*prev = tree.root
+	rightHeight, rightSize, err := validateSubtree(tree.right, prev)
+	if err != nil {
+		return 0, 0, err
+	}
+	height := maxInt(leftHeight, rightHeight) + 1
+	size := leftSize + rightSize + 1
+	if tree.height != height {
+		return 0, 0, fmt.Errorf("node %v has cached height %d, expected %d", tree.root, tree.height, height)
+	}
+	if tree.size != size {
+		return 0, 0, fmt.Errorf("node %v has cached size %d, expected %d", tree.root, tree.size, size)
+	}
+	if leftHeight-rightHeight > 1 || rightHeight-leftHeight > 1 {
+		return 0, 0, fmt.Errorf("node %v has balance factor %d", tree.root, leftHeight-rightHeight)
+	}
+	return height, size, nil
+}
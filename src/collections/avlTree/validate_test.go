@@ -0,0 +1,144 @@
+package avlTree
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func verifyValidateErr(t *testing.T, tree *priorityTree, expectedErrMsg string) {
+	err := Validate(tree)
+	if expectedErrMsg == "" {
+		if err != nil {
+			t.Errorf("Validate(tree) == %v, expected nil", err)
+		}
+	} else if err == nil || !strings.Contains(err.Error(), expectedErrMsg) {
+		t.Errorf("Validate(tree) == %v, expected error containing %q", err, expectedErrMsg)
+	}
+}
+
+func testValidate_EmptyTree(t *testing.T) {
+	verifyValidateErr(t, nil, "")
+	verifyValidateErr(t, newPriorityTree(), "")
+}
+
+func testValidate_ValidTree(t *testing.T) {
+	left := createAvlTree_Leaf("left", 1)
+	right := createAvlTree_Leaf("right", 9)
+	verifyValidateErr(t, createAvlTree("root", 5, 1, left, right), "")
+}
+
+func testValidate_OutOfOrder(t *testing.T) {
+	left := createAvlTree_Leaf("left", 7)
+	right := createAvlTree_Leaf("right", 9)
+	verifyValidateErr(t, createAvlTree("root", 5, 1, left, right), "is ordered after greater node")
+
+	grandchild := createAvlTree_Leaf("grandchild", 6)
+	left = createAvlTree("left", 1, 1, nil, grandchild)
+	right = createAvlTree_Leaf("right", 9)
+	verifyValidateErr(t, createAvlTree("root", 5, 2, left, right), "is ordered after greater node")
+}
+
+func testValidate_WrongHeight(t *testing.T) {
+	left := createAvlTree_Leaf("left", 1)
+	verifyValidateErr(t, createAvlTree("root", 5, 2, left, nil), "cached height 2, expected 1")
+}
+
+func testValidate_WrongSize(t *testing.T) {
+	left := createAvlTree_Leaf("left", 1)
+	tree := createAvlTree("root", 5, 1, left, nil)
+	tree.size = 3
+	verifyValidateErr(t, tree, "cached size 3, expected 2")
+}
+
+func testValidate_Unbalanced(t *testing.T) {
+	leftL := createAvlTree_Leaf("LL", 1)
+	left := createAvlTree("L", 3, 1, leftL, nil)
+	verifyValidateErr(t, createAvlTree("root", 5, 2, left, nil), "balance factor 2")
+}
+
+func testValidate_EmptyChild(t *testing.T) {
+	verifyValidateErr(t, createAvlTree("root", 5, 1, newPriorityTree(), nil), "empty subtree")
+}
+
+func TestValidate(t *testing.T) {
+	testValidate_EmptyTree(t)
+	testValidate_ValidTree(t)
+	testValidate_OutOfOrder(t)
+	testValidate_WrongHeight(t)
+	testValidate_WrongSize(t)
+	testValidate_Unbalanced(t)
+	testValidate_EmptyChild(t)
+}
+
+//Sorted slice with the same multiset semantics as AvlTree, used as a reference model
+type sortedSliceModel []int
+
+func (model *sortedSliceModel) insert(key int) {
+	index, _ := slices.BinarySearch(*model, key)
+	*model = slices.Insert(*model, index, key)
+}
+
+func (model *sortedSliceModel) remove(key int) bool {
+	index, found := slices.BinarySearch(*model, key)
+	if found {
+		*model = slices.Delete(*model, index, index+1)
+	}
+	return found
+}
+
+func (model *sortedSliceModel) removeMax() {
+	if len(*model) > 0 {
+		*model = (*model)[:len(*model)-1]
+	}
+}
+
+//Applies the operations encoded in ops to an AvlTree and to a sorted slice,
+//validating the tree and comparing it with the slice after every operation.
+//Each operation uses two bytes: an opcode and a key.
+func runTreeOperations(t *testing.T, ops []byte) {
+	tree := NewAvlTree[int, string]()
+	model := sortedSliceModel{}
+	for i := 0; i+1 < len(ops); i += 2 {
+		key := int(ops[i+1] % 64)
+		switch ops[i] % 4 {
+		case 0:
+			Insert(&tree, NewAvlNode(key, ""))
+			model.insert(key)
+		case 1:
+			Remove(&tree, NewAvlNode(key, ""))
+			model.remove(key)
+		case 2:
+			RemoveMax(&tree)
+			model.removeMax()
+		case 3:
+			newKey := int(ops[i] / 4 % 64)
+			updated := UpdateNode(&tree, NewAvlNode(key, ""), NewAvlNode(newKey, ""))
+			if model.remove(key) {
+				model.insert(newKey)
+			} else if updated {
+				t.Fatalf("op %d: UpdateNode(%d, %d) == true, expected false", i/2, key, newKey)
+			}
+		}
+		if err := Validate(tree); err != nil {
+			dumpTree(t, "tree", tree)
+			t.Fatalf("op %d: Validate(tree) == %v", i/2, err)
+		}
+		if keys := collectKeys(tree.All()); !slices.Equal(keys, model) {
+			t.Fatalf("op %d: tree holds %v, expected %v", i/2, keys, model)
+		}
+	}
+}
+
+func FuzzTreeOperations(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 1, 0, 2, 0, 3, 0, 4, 0, 5, 2, 0, 1, 3})
+	f.Add([]byte{0, 5, 0, 5, 0, 5, 1, 5, 3, 5, 2, 0})
+	f.Add([]byte{0, 10, 0, 9, 0, 8, 0, 7, 0, 6, 0, 5, 1, 10, 1, 9, 1, 8, 7, 6})
+	ascending := []byte{}
+	for key := byte(0); key < 64; key++ {
+		ascending = append(ascending, 0, key)
+	}
+	f.Add(append(ascending, 1, 31, 1, 32, 1, 0, 2, 0, 2, 0, 203, 40))
+	f.Fuzz(runTreeOperations)
+}
@@ -47,7 +47,7 @@ func RunTestWithExpectedError(t *testing.T, testName string, expectedErrMsg stri
 	cmd.Env = append(os.Environ(), "BE_CRASHER=1")
 	err := cmd.Start()
 	if err != nil {
-		t.Fatalf("Failed to start test in crasher mode, err: ", err)
+		t.Fatalf("Failed to start test in crasher mode, err: %v", err)
 	}
 	WaitAndVerifyErr(t, cmd, timeoutPeriod, expectedErrMsg)
 }